@@ -0,0 +1,14 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfAnalyticsSubscription struct {
+	SubID        string
+	AnaSub       *nef_models.AnalyticsExposureSubsc
+	NwdafSubID   string
+	NotifCorreID string
+	Log          *logrus.Entry `json:"-"`
+}
@@ -0,0 +1,23 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfBdtSubscription struct {
+	SubID       string
+	Bdt         *nef_models.Bdt
+	BdtPolicyID string
+	Log         *logrus.Entry `json:"-"`
+}
+
+// TransferPolicy returns the transfer policy offered by the PCF
+func (s *AfBdtSubscription) TransferPolicy(bdtPolicyID int32) *nef_models.TransferPolicy {
+	for i := range s.Bdt.TransferPolicies {
+		if s.Bdt.TransferPolicies[i].BdtPolicyId == bdtPolicyID {
+			return &s.Bdt.TransferPolicies[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfChargeableParty struct {
+	TransID      string
+	ChgParty     *nef_models.ChargeableParty
+	AppSessID    string
+	NotifCorreID string
+	Log          *logrus.Entry `json:"-"`
+}
+
+func (t *AfChargeableParty) PatchChgPartyData(chgPartyPatch *nef_models.ChargeablePartyPatch) {
+	if chgPartyPatch.ExterAppId != "" {
+		t.ChgParty.ExterAppId = chgPartyPatch.ExterAppId
+	}
+	if chgPartyPatch.FlowInfo != nil {
+		t.ChgParty.FlowInfo = chgPartyPatch.FlowInfo
+	}
+	if chgPartyPatch.EthFlowInfo != nil {
+		t.ChgParty.EthFlowInfo = chgPartyPatch.EthFlowInfo
+	}
+	if chgPartyPatch.SponsoringEnabled != nil {
+		t.ChgParty.SponsoringEnabled = *chgPartyPatch.SponsoringEnabled
+	}
+	if chgPartyPatch.ReferenceId != "" {
+		t.ChgParty.ReferenceId = chgPartyPatch.ReferenceId
+	}
+	if chgPartyPatch.UsageThreshold != nil {
+		t.ChgParty.UsageThreshold = &models.UsageThreshold{
+			Duration:       chgPartyPatch.UsageThreshold.Duration,
+			TotalVolume:    chgPartyPatch.UsageThreshold.TotalVolume,
+			DownlinkVolume: chgPartyPatch.UsageThreshold.DownlinkVolume,
+			UplinkVolume:   chgPartyPatch.UsageThreshold.UplinkVolume,
+		}
+	}
+	if chgPartyPatch.NotificationDestination != "" {
+		t.ChgParty.NotificationDestination = chgPartyPatch.NotificationDestination
+	}
+	if chgPartyPatch.Events != nil {
+		t.ChgParty.Events = chgPartyPatch.Events
+	}
+}
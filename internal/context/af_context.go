@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/openapi/models_nef"
 	"github.com/sirupsen/logrus"
 )
@@ -16,8 +17,18 @@ type AfData struct {
 	NumTransID uint64
 	Subs       map[string]*AfSubscription
 	PfdTrans   map[string]*AfPfdTransaction
-	Mu         sync.RWMutex
-	Log        *logrus.Entry
+	QosSubs    map[string]*AfQosSubscription
+	MonSubs    map[string]*AfMonitoringSubscription
+	DevTrigs   map[string]*AfDeviceTrigger
+	ChgParties map[string]*AfChargeableParty
+	BdtSubs    map[string]*AfBdtSubscription
+	PpSubs     map[string]*AfPpSubscription
+	LanSubs    map[string]*AfLanSubscription
+	NiddCfgs   map[string]*AfNiddConfiguration
+	AnaSubs    map[string]*AfAnalyticsSubscription
+	SpSubs     map[string]*AfSpSubscription
+	Mu         sync.RWMutex  `json:"-"`
+	Log        *logrus.Entry `json:"-"`
 }
 
 func (a *AfData) NewSub(numCorreID uint64, tiSub *models_nef.TrafficInfluSub) *AfSubscription {
@@ -32,6 +43,107 @@ func (a *AfData) NewSub(numCorreID uint64, tiSub *models_nef.TrafficInfluSub) *A
 	return &sub
 }
 
+func (a *AfData) NewQosSub(
+	numCorreID uint64,
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) *AfQosSubscription {
+	a.NumSubscID++
+	sub := AfQosSubscription{
+		NotifCorreID: strconv.FormatUint(numCorreID, 10),
+		SubID:        strconv.FormatUint(a.NumSubscID, 10),
+		QosSub:       qosSub,
+		Log:          a.Log.WithField(logger.FieldSubID, fmt.Sprintf("QOS:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New AS session with QoS subscription")
+	return &sub
+}
+
+func (a *AfData) NewMonSub(
+	numCorreID uint64,
+	monSub *nef_models.MonitoringEventSubscription,
+) *AfMonitoringSubscription {
+	a.NumSubscID++
+	sub := AfMonitoringSubscription{
+		NotifCorreID: strconv.FormatUint(numCorreID, 10),
+		SubID:        strconv.FormatUint(a.NumSubscID, 10),
+		MonSub:       monSub,
+		Log:          a.Log.WithField(logger.FieldSubID, fmt.Sprintf("MON:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New monitoring event subscription")
+	return &sub
+}
+
+func (a *AfData) NewBdtSub(bdt *nef_models.Bdt) *AfBdtSubscription {
+	a.NumSubscID++
+	sub := AfBdtSubscription{
+		SubID: strconv.FormatUint(a.NumSubscID, 10),
+		Bdt:   bdt,
+		Log:   a.Log.WithField(logger.FieldSubID, fmt.Sprintf("BDT:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New BDT subscription")
+	return &sub
+}
+
+func (a *AfData) NewPpSub(ppConfig *nef_models.PpConfig) *AfPpSubscription {
+	a.NumSubscID++
+	sub := AfPpSubscription{
+		SubID:    strconv.FormatUint(a.NumSubscID, 10),
+		PpConfig: ppConfig,
+		Log:      a.Log.WithField(logger.FieldSubID, fmt.Sprintf("PP:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New parameter provisioning subscription")
+	return &sub
+}
+
+func (a *AfData) NewLanSub(lanParams *nef_models.FiveGLanParametersProvision) *AfLanSubscription {
+	a.NumSubscID++
+	sub := AfLanSubscription{
+		SubID:     strconv.FormatUint(a.NumSubscID, 10),
+		LanParams: lanParams,
+		Log:       a.Log.WithField(logger.FieldSubID, fmt.Sprintf("LAN:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New 5G LAN parameters provision subscription")
+	return &sub
+}
+
+func (a *AfData) NewNiddCfg(niddCfg *nef_models.NiddConfiguration) *AfNiddConfiguration {
+	a.NumSubscID++
+	cfg := AfNiddConfiguration{
+		ConfigID: strconv.FormatUint(a.NumSubscID, 10),
+		NiddCfg:  niddCfg,
+		DlDatas:  make(map[string]*nef_models.NiddDownlinkDataTransfer),
+		Log:      a.Log.WithField(logger.FieldSubID, fmt.Sprintf("NIDD:%d", a.NumSubscID)),
+	}
+	cfg.Log.Infoln("New NIDD configuration")
+	return &cfg
+}
+
+func (a *AfData) NewAnaSub(
+	numCorreID uint64,
+	anaSub *nef_models.AnalyticsExposureSubsc,
+) *AfAnalyticsSubscription {
+	a.NumSubscID++
+	sub := AfAnalyticsSubscription{
+		NotifCorreID: strconv.FormatUint(numCorreID, 10),
+		SubID:        strconv.FormatUint(a.NumSubscID, 10),
+		AnaSub:       anaSub,
+		Log:          a.Log.WithField(logger.FieldSubID, fmt.Sprintf("ANA:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New analytics exposure subscription")
+	return &sub
+}
+
+func (a *AfData) NewSpSub(spData *nef_models.ServiceParameterData) *AfSpSubscription {
+	a.NumSubscID++
+	sub := AfSpSubscription{
+		SubID:  strconv.FormatUint(a.NumSubscID, 10),
+		SpData: spData,
+		Log:    a.Log.WithField(logger.FieldSubID, fmt.Sprintf("SP:%d", a.NumSubscID)),
+	}
+	sub.Log.Infoln("New service parameter subscription")
+	return &sub
+}
+
 func (a *AfData) NewPfdTrans() *AfPfdTransaction {
 	a.NumTransID++
 	pfdTr := AfPfdTransaction{
@@ -43,6 +155,118 @@ func (a *AfData) NewPfdTrans() *AfPfdTransaction {
 	return &pfdTr
 }
 
+func (a *AfData) NewDevTrig(
+	numCorreID uint64,
+	devTrig *nef_models.DeviceTriggering,
+) *AfDeviceTrigger {
+	a.NumTransID++
+	dt := AfDeviceTrigger{
+		TransID: strconv.FormatUint(a.NumTransID, 10),
+		DevTrig: devTrig,
+		MsgID:   strconv.FormatUint(numCorreID, 10),
+		Log:     a.Log.WithField(logger.FieldSubID, fmt.Sprintf("DT:%d", a.NumTransID)),
+	}
+	dt.Log.Infoln("New device triggering transaction")
+	return &dt
+}
+
+func (a *AfData) NewChgParty(
+	numCorreID uint64,
+	chgParty *nef_models.ChargeableParty,
+) *AfChargeableParty {
+	a.NumTransID++
+	cp := AfChargeableParty{
+		TransID:      strconv.FormatUint(a.NumTransID, 10),
+		ChgParty:     chgParty,
+		NotifCorreID: strconv.FormatUint(numCorreID, 10),
+		Log:          a.Log.WithField(logger.FieldSubID, fmt.Sprintf("CP:%d", a.NumTransID)),
+	}
+	cp.Log.Infoln("New chargeable party transaction")
+	return &cp
+}
+
+// restoreLog sets the loggers of the AF restored from the store
+func (a *AfData) restoreLog() {
+	if a.Subs == nil {
+		a.Subs = make(map[string]*AfSubscription)
+	}
+	if a.PfdTrans == nil {
+		a.PfdTrans = make(map[string]*AfPfdTransaction)
+	}
+	if a.QosSubs == nil {
+		a.QosSubs = make(map[string]*AfQosSubscription)
+	}
+	if a.MonSubs == nil {
+		a.MonSubs = make(map[string]*AfMonitoringSubscription)
+	}
+	if a.DevTrigs == nil {
+		a.DevTrigs = make(map[string]*AfDeviceTrigger)
+	}
+	if a.ChgParties == nil {
+		a.ChgParties = make(map[string]*AfChargeableParty)
+	}
+	if a.BdtSubs == nil {
+		a.BdtSubs = make(map[string]*AfBdtSubscription)
+	}
+	if a.PpSubs == nil {
+		a.PpSubs = make(map[string]*AfPpSubscription)
+	}
+	if a.LanSubs == nil {
+		a.LanSubs = make(map[string]*AfLanSubscription)
+	}
+	if a.NiddCfgs == nil {
+		a.NiddCfgs = make(map[string]*AfNiddConfiguration)
+	}
+	if a.AnaSubs == nil {
+		a.AnaSubs = make(map[string]*AfAnalyticsSubscription)
+	}
+	if a.SpSubs == nil {
+		a.SpSubs = make(map[string]*AfSpSubscription)
+	}
+	for _, sub := range a.Subs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("SUB:%s", sub.SubID))
+	}
+	for _, sub := range a.QosSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("QOS:%s", sub.SubID))
+	}
+	for _, sub := range a.MonSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("MON:%s", sub.SubID))
+	}
+	for _, sub := range a.BdtSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("BDT:%s", sub.SubID))
+	}
+	for _, sub := range a.PpSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("PP:%s", sub.SubID))
+	}
+	for _, sub := range a.LanSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("LAN:%s", sub.SubID))
+	}
+	for _, sub := range a.AnaSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("ANA:%s", sub.SubID))
+	}
+	for _, sub := range a.SpSubs {
+		sub.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("SP:%s", sub.SubID))
+	}
+	for _, cfg := range a.NiddCfgs {
+		if cfg.DlDatas == nil {
+			cfg.DlDatas = make(map[string]*nef_models.NiddDownlinkDataTransfer)
+		}
+		cfg.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("NIDD:%s", cfg.ConfigID))
+	}
+	for _, dt := range a.DevTrigs {
+		dt.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("DT:%s", dt.TransID))
+	}
+	for _, cp := range a.ChgParties {
+		cp.Log = a.Log.WithField(logger.FieldSubID, fmt.Sprintf("CP:%s", cp.TransID))
+	}
+	for _, pfdTr := range a.PfdTrans {
+		if pfdTr.ExtAppIDs == nil {
+			pfdTr.ExtAppIDs = make(map[string]struct{})
+		}
+		pfdTr.Log = a.Log.WithField(logger.FieldPfdTransID, fmt.Sprintf("PFDT:%s", pfdTr.TransID))
+	}
+}
+
 func (a *AfData) IsAppIDExisted(appID string) (string, bool) {
 	for _, pfdTrans := range a.PfdTrans {
 		if _, ok := pfdTrans.ExtAppIDs[appID]; ok {
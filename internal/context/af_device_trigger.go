@@ -0,0 +1,15 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfDeviceTrigger struct {
+	TransID string
+	DevTrig *nef_models.DeviceTriggering
+	Supi    string
+	// Reference of the trigger message in the SMS-SC
+	MsgID string
+	Log   *logrus.Entry `json:"-"`
+}
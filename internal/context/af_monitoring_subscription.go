@@ -0,0 +1,24 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfMonitoringSubscription struct {
+	SubID        string
+	MonSub       *nef_models.MonitoringEventSubscription
+	UeIdentity   string // ueIdentity of the UDM EE subscription, e.g. msisdn-xxx, extid-xxx
+	EeSubID      string
+	NotifCorreID string
+	NumReports   int32
+	Log          *logrus.Entry `json:"-"`
+}
+
+// IsMaxReportsReached counts the reports notified to the AF and returns true if
+// the maximumNumberOfReports of the subscription is reached
+func (s *AfMonitoringSubscription) IsMaxReportsReached(numReports int) bool {
+	s.NumReports += int32(numReports)
+	return s.MonSub.MaximumNumberOfReports > 0 &&
+		s.NumReports >= s.MonSub.MaximumNumberOfReports
+}
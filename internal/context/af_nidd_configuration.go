@@ -0,0 +1,70 @@
+package context
+
+import (
+	"sort"
+	"strconv"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfNiddConfiguration struct {
+	ConfigID string
+	NiddCfg  *nef_models.NiddConfiguration
+	Gpsi     string
+	NumDlID  uint64
+	// Downlink data deliveries with the delivery status of the UE
+	DlDatas map[string]*nef_models.NiddDownlinkDataTransfer
+	Log     *logrus.Entry `json:"-"`
+
+	// IDs of the buffered downlink data being delivered to the SMF
+	delivering map[string]bool
+}
+
+func (n *AfNiddConfiguration) NewDlDataID() string {
+	n.NumDlID++
+	return strconv.FormatUint(n.NumDlID, 10)
+}
+
+// BufferedDlDataIDs returns the IDs of the downlink data waiting for the SM context
+// of the UE, in the order of the deliveries.
+func (n *AfNiddConfiguration) BufferedDlDataIDs() []string {
+	var ids []uint64
+	for id, dlData := range n.DlDatas {
+		if dlData.DeliveryStatus != nef_models.DeliveryStatus_BUFFERING {
+			continue
+		}
+		if numID, err := strconv.ParseUint(id, 10, 64); err == nil {
+			ids = append(ids, numID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	dlIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		dlIDs = append(dlIDs, strconv.FormatUint(id, 10))
+	}
+	return dlIDs
+}
+
+// StartDelivery marks the buffered downlink data as being delivered and returns it.
+// Nil is returned if the data isn't buffered, or is being delivered already.
+func (n *AfNiddConfiguration) StartDelivery(dlID string) *nef_models.NiddDownlinkDataTransfer {
+	dlData, ok := n.DlDatas[dlID]
+	if !ok || dlData.DeliveryStatus != nef_models.DeliveryStatus_BUFFERING || n.delivering[dlID] {
+		return nil
+	}
+	if n.delivering == nil {
+		n.delivering = make(map[string]bool)
+	}
+	n.delivering[dlID] = true
+	return dlData
+}
+
+func (n *AfNiddConfiguration) FinishDelivery(dlID string) {
+	delete(n.delivering, dlID)
+}
+
+func (n *AfNiddConfiguration) IsDelivering(dlID string) bool {
+	return n.delivering[dlID]
+}
@@ -7,7 +7,9 @@ import (
 type AfPfdTransaction struct {
 	TransID   string
 	ExtAppIDs map[string]struct{}
-	Log       *logrus.Entry
+	// Where the PFD failures reported by the SMFs are notified
+	NotifDest string
+	Log       *logrus.Entry `json:"-"`
 }
 
 func (a *AfPfdTransaction) GetExtAppIDs() []string {
@@ -0,0 +1,31 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfPpSubscription struct {
+	SubID    string
+	PpConfig *nef_models.PpConfig
+	Gpsi     string
+	Log      *logrus.Entry `json:"-"`
+}
+
+type AfLanSubscription struct {
+	SubID     string
+	LanParams *nef_models.FiveGLanParametersProvision
+	Log       *logrus.Entry `json:"-"`
+}
+
+// UdmDataKey returns the key of the parameters provisioned in the UDM, which are
+// shared by the subscriptions of the same UE
+func (s *AfPpSubscription) UdmDataKey() string {
+	return s.Gpsi
+}
+
+// UdmDataKey returns the key of the 5G VN group provisioned in the UDM, which is
+// shared by the subscriptions of the same group
+func (s *AfLanSubscription) UdmDataKey() string {
+	return "extgroupid-" + s.LanParams.FiveGLanParams.ExterGroupId
+}
@@ -0,0 +1,47 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfQosSubscription struct {
+	SubID        string
+	QosSub       *nef_models.AsSessionWithQoSSubscription
+	AppSessID    string
+	NotifCorreID string
+	Log          *logrus.Entry `json:"-"`
+}
+
+func (s *AfQosSubscription) PatchQosSubData(qosSubPatch *nef_models.AsSessionWithQoSSubscriptionPatch) {
+	if qosSubPatch.ExterAppId != "" {
+		s.QosSub.ExterAppId = qosSubPatch.ExterAppId
+	}
+	if qosSubPatch.FlowInfo != nil {
+		s.QosSub.FlowInfo = qosSubPatch.FlowInfo
+	}
+	if qosSubPatch.EthFlowInfo != nil {
+		s.QosSub.EthFlowInfo = qosSubPatch.EthFlowInfo
+	}
+	if qosSubPatch.QosReference != "" {
+		s.QosSub.QosReference = qosSubPatch.QosReference
+	}
+	if qosSubPatch.AltQoSReferences != nil {
+		s.QosSub.AltQoSReferences = qosSubPatch.AltQoSReferences
+	}
+	if qosSubPatch.UsageThreshold != nil {
+		s.QosSub.UsageThreshold = &models.UsageThreshold{
+			Duration:       qosSubPatch.UsageThreshold.Duration,
+			TotalVolume:    qosSubPatch.UsageThreshold.TotalVolume,
+			DownlinkVolume: qosSubPatch.UsageThreshold.DownlinkVolume,
+			UplinkVolume:   qosSubPatch.UsageThreshold.UplinkVolume,
+		}
+	}
+	if qosSubPatch.TscQosReq != nil {
+		s.QosSub.TscQosReq = qosSubPatch.TscQosReq
+	}
+	if qosSubPatch.NotificationDestination != "" {
+		s.QosSub.NotificationDestination = qosSubPatch.NotificationDestination
+	}
+}
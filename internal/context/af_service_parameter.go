@@ -0,0 +1,26 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AfSpSubscription struct {
+	SubID  string
+	SpData *nef_models.ServiceParameterData
+	SpID   string        // serviceParamId of the data stored in UDR
+	Supi   string        // translated from the GPSI of the individual UE
+	Log    *logrus.Entry `json:"-"`
+}
+
+func (s *AfSpSubscription) PatchSpData(spPatch *nef_models.ServiceParameterDataPatch) {
+	if spPatch.ParamOverPc5 != "" {
+		s.SpData.ParamOverPc5 = spPatch.ParamOverPc5
+	}
+	if spPatch.ParamOverUu != "" {
+		s.SpData.ParamOverUu = spPatch.ParamOverUu
+	}
+	if spPatch.UrspGuidance != nil {
+		s.SpData.UrspGuidance = spPatch.UrspGuidance
+	}
+}
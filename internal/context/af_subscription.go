@@ -1,6 +1,10 @@
 package context
 
 import (
+	"reflect"
+	"time"
+
+	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/openapi/models_nef"
 	"github.com/sirupsen/logrus"
 )
@@ -9,9 +13,23 @@ type AfSubscription struct {
 	SubID        string
 	TiSub        *models_nef.TrafficInfluSub
 	AppSessID    string // use in single UE case
+	PcfUri       string // PCF serving the PDU session in single UE case
+	PcfID        string // NF instance ID of the PCF, to find the PCF again if PcfUri is dropped
+	Supi         string // translated from the Gpsi in single UE case
 	InfluID      string // use in multiple UE case
+	InfluSubsID  string // subscription to the changes of the influence data in UDR
+	InterGroupID string // internal group ID translated from the ExternalGroupId
 	NotifCorreID string
-	Log          *logrus.Entry
+	NotifStatus  NotifDeliveryStatus
+	Log          *logrus.Entry `json:"-"`
+}
+
+// NotifDeliveryStatus records the results of the notifications sent to the AF
+type NotifDeliveryStatus struct {
+	NumSuccess   uint64
+	NumFailure   uint64
+	LastDelivery time.Time
+	LastError    string
 }
 
 func (s *AfSubscription) PatchTiSubData(tiSubPatch *models_nef.TrafficInfluSubPatch) {
@@ -25,3 +43,41 @@ func (s *AfSubscription) PatchTiSubData(tiSubPatch *models_nef.TrafficInfluSubPa
 	s.TiSub.AfAckInd = tiSubPatch.AfAckInd
 	s.TiSub.AddrPreserInd = tiSubPatch.AddrPreserInd
 }
+
+// UpdateTiSubData applies the influence data stored in the UDR to the subscription,
+// and reports whether the subscription is changed.
+func (s *AfSubscription) UpdateTiSubData(tiData *models.TrafficInfluData) bool {
+	tiSub := *s.TiSub
+	tiSub.AfAppId = tiData.AfAppId
+	tiSub.AppReloInd = tiData.AppReloInd
+	tiSub.DnaiChgType = tiData.DnaiChgType
+	tiSub.Dnn = tiData.Dnn
+	tiSub.Snssai = tiData.Snssai
+	tiSub.EthTrafficFilters = tiData.EthTrafficFilters
+	tiSub.TrafficFilters = tiData.TrafficFilters
+	tiSub.TrafficRoutes = tiData.TrafficRoutes
+	tiSub.TfcCorrInd = tiData.TraffCorreInd
+	tiSub.TempValidities = tiData.TempValidities
+	tiSub.AfAckInd = tiData.AfAckInd
+	tiSub.AddrPreserInd = tiData.AddrPreserInd
+	if reflect.DeepEqual(&tiSub, s.TiSub) {
+		return false
+	}
+	*s.TiSub = tiSub
+	return true
+}
+
+func (s *AfSubscription) RecordNotifDelivery(err error) {
+	s.NotifStatus.LastDelivery = time.Now()
+	if err != nil {
+		s.NotifStatus.NumFailure++
+		s.NotifStatus.LastError = err.Error()
+		s.Log.Warnf("Notification delivery failed(%d/%d): %+v",
+			s.NotifStatus.NumFailure, s.NotifStatus.NumSuccess+s.NotifStatus.NumFailure, err)
+		return
+	}
+	s.NotifStatus.NumSuccess++
+	s.NotifStatus.LastError = ""
+	s.Log.Infof("Notification delivered(%d/%d)",
+		s.NotifStatus.NumSuccess, s.NotifStatus.NumSuccess+s.NotifStatus.NumFailure)
+}
@@ -2,16 +2,42 @@ package context
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/internal/store"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/openapi/models_nef"
 	"github.com/free5gc/openapi/oauth"
 	"github.com/google/uuid"
 )
 
+const (
+	bucketNef   string = "nef"
+	bucketAf    string = "af"
+	bucketNefEe string = "nefEe"
+
+	keyNumCorreID string = "numCorreID"
+)
+
+// supiCacheEntry is the SUPI translated from a GPSI, which is valid until expiry
+type supiCacheEntry struct {
+	supi   string
+	expiry time.Time
+}
+
+// udmDataOwner is the AF subscription which provisioned the data in the UDM
+type udmDataOwner struct {
+	afID  string
+	subID string
+}
+
 type nef interface {
 	Config() *factory.Config
 }
@@ -21,23 +47,146 @@ type NefContext struct {
 
 	nfInstID       string // NF Instance ID
 	pcfPaUri       string
+	pcfPaID        string // NF instance ID of the PCF of pcfPaUri
+	pcfBdtUri      string
+	bsfMgmtUri     string
 	udrDrUri       string
+	udmEeUri       string
+	amfEvtsUri     string
+	smfEeUri       string
+	udmSdmUri      string
+	udmPpUri       string
+	nwdafEsUri     string
+	nwdafAiUri     string
 	numCorreID     uint64
+	numSmCtxID     uint64
 	OAuth2Required bool
 	afs            map[string]*AfData
+	afAckWaiters   map[string]chan *models_nef.AfAckInfo
+	smContexts     map[string]*SmContext
+	nefEeSubs      map[string]*NefEeSubscription
+	supiCache      map[string]supiCacheEntry
+	nfStatusSubIDs map[models.NfType]string    // subscriptions to the NF status in NRF
+	nfStatusSubExp map[models.NfType]time.Time // expiry of the NF status subscriptions
+	udmDataOwners  map[string]udmDataOwner     // owners of the UE and group data provisioned in UDM
+	store          store.Store
+	correIDStoreMu sync.Mutex // serializes the saves of numCorreID
+	reconcileRpt   *ReconcileReport
 	mu             sync.RWMutex
 }
 
 func NewContext(nef nef) (*NefContext, error) {
+	var err error
 	c := &NefContext{
 		nef:      nef,
 		nfInstID: uuid.New().String(),
 	}
 	c.afs = make(map[string]*AfData)
+	c.afAckWaiters = make(map[string]chan *models_nef.AfAckInfo)
+	c.smContexts = make(map[string]*SmContext)
+	c.nefEeSubs = make(map[string]*NefEeSubscription)
+	c.supiCache = make(map[string]supiCacheEntry)
+	c.nfStatusSubIDs = make(map[models.NfType]string)
+	c.nfStatusSubExp = make(map[models.NfType]time.Time)
+	c.udmDataOwners = make(map[string]udmDataOwner)
 	logger.CtxLog.Infof("New nfInstID: [%s]", c.nfInstID)
+
+	if c.store, err = store.NewStore(nef.Config().StoreType(), nef.Config().StorePath()); err != nil {
+		return nil, fmt.Errorf("open store error: %w", err)
+	}
+	if err = c.restore(); err != nil {
+		return nil, fmt.Errorf("restore context error: %w", err)
+	}
 	return c, nil
 }
 
+// restore loads the ID counter, the AFs and the Nnef_EventExposure subscriptions kept in the store
+func (c *NefContext) restore() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.store.ForEach(bucketNef, func(key string, value []byte) error {
+		if key == keyNumCorreID {
+			return json.Unmarshal(value, &c.numCorreID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.store.ForEach(bucketAf, func(key string, value []byte) error {
+		af := c.NewAf(key)
+		if err = json.Unmarshal(value, af); err != nil {
+			return fmt.Errorf("AF[%s]: %w", key, err)
+		}
+		af.restoreLog()
+		c.afs[af.AfID] = af
+		for _, sub := range af.PpSubs {
+			c.udmDataOwners[sub.UdmDataKey()] = udmDataOwner{af.AfID, sub.SubID}
+		}
+		for _, sub := range af.LanSubs {
+			c.udmDataOwners[sub.UdmDataKey()] = udmDataOwner{af.AfID, sub.SubID}
+		}
+		af.Log.Infof("AF is restored with %d subscriptions and %d PFD transactions",
+			len(af.Subs), len(af.PfdTrans))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.store.ForEach(bucketNefEe, func(key string, value []byte) error {
+		sub := &NefEeSubscription{
+			SubID: key,
+			Log:   logger.NefEeLog.WithField(logger.FieldSubID, fmt.Sprintf("EE:%s", key)),
+		}
+		if err = json.Unmarshal(value, sub); err != nil {
+			return fmt.Errorf("EE subscription[%s]: %w", key, err)
+		}
+		c.nefEeSubs[sub.SubID] = sub
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.CtxLog.Infof("%d AFs and %d EE subscriptions are restored, numCorreID[%d]",
+		len(c.afs), len(c.nefEeSubs), c.numCorreID)
+	return nil
+}
+
+func (c *NefContext) Store() store.Store {
+	return c.store
+}
+
+func (c *NefContext) CloseStore() {
+	if err := c.store.Close(); err != nil {
+		logger.CtxLog.Errorf("Close store error: %+v", err)
+	}
+}
+
+// StoreAf saves the AF with its subscriptions and PFD transactions.
+// The caller shall hold the lock of the AF.
+func (c *NefContext) StoreAf(af *AfData) {
+	if err := c.store.Put(bucketAf, af.AfID, af); err != nil {
+		af.Log.Errorf("Store AF error: %+v", err)
+	}
+}
+
+// storeNumCorreID saves the latest numCorreID. It's called without the lock of the context,
+// and the saves are serialized so that a smaller numCorreID never overwrites a larger one.
+func (c *NefContext) storeNumCorreID() {
+	c.correIDStoreMu.Lock()
+	defer c.correIDStoreMu.Unlock()
+
+	c.mu.RLock()
+	numCorreID := c.numCorreID
+	c.mu.RUnlock()
+	if err := c.store.Put(bucketNef, keyNumCorreID, numCorreID); err != nil {
+		logger.CtxLog.Errorf("Store numCorreID error: %+v", err)
+	}
+}
+
 func (c *NefContext) NfInstID() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -64,6 +213,44 @@ func (c *NefContext) SetPcfPaUri(uri string) {
 	logger.CtxLog.Infof("Set pcfPaUri: [%s]", c.pcfPaUri)
 }
 
+func (c *NefContext) PcfPaID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pcfPaID
+}
+
+func (c *NefContext) SetPcfPaID(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pcfPaID = id
+}
+
+func (c *NefContext) PcfBdtUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pcfBdtUri
+}
+
+func (c *NefContext) SetPcfBdtUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pcfBdtUri = uri
+	logger.CtxLog.Infof("Set pcfBdtUri: [%s]", c.pcfBdtUri)
+}
+
+func (c *NefContext) BsfMgmtUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bsfMgmtUri
+}
+
+func (c *NefContext) SetBsfMgmtUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bsfMgmtUri = uri
+	logger.CtxLog.Infof("Set bsfMgmtUri: [%s]", c.bsfMgmtUri)
+}
+
 func (c *NefContext) UdrDrUri() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -77,20 +264,125 @@ func (c *NefContext) SetUdrDrUri(uri string) {
 	logger.CtxLog.Infof("Set udrDrUri: [%s]", c.udrDrUri)
 }
 
+func (c *NefContext) UdmEeUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.udmEeUri
+}
+
+func (c *NefContext) SetUdmEeUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.udmEeUri = uri
+	logger.CtxLog.Infof("Set udmEeUri: [%s]", c.udmEeUri)
+}
+
+func (c *NefContext) AmfEvtsUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.amfEvtsUri
+}
+
+func (c *NefContext) SetAmfEvtsUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.amfEvtsUri = uri
+	logger.CtxLog.Infof("Set amfEvtsUri: [%s]", c.amfEvtsUri)
+}
+
+func (c *NefContext) SmfEeUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.smfEeUri
+}
+
+func (c *NefContext) SetSmfEeUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.smfEeUri = uri
+	logger.CtxLog.Infof("Set smfEeUri: [%s]", c.smfEeUri)
+}
+
+func (c *NefContext) UdmSdmUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.udmSdmUri
+}
+
+func (c *NefContext) SetUdmSdmUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.udmSdmUri = uri
+	logger.CtxLog.Infof("Set udmSdmUri: [%s]", c.udmSdmUri)
+}
+
+func (c *NefContext) UdmPpUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.udmPpUri
+}
+
+func (c *NefContext) SetUdmPpUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.udmPpUri = uri
+	logger.CtxLog.Infof("Set udmPpUri: [%s]", c.udmPpUri)
+}
+
+func (c *NefContext) NwdafEsUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nwdafEsUri
+}
+
+func (c *NefContext) SetNwdafEsUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nwdafEsUri = uri
+	logger.CtxLog.Infof("Set nwdafEsUri: [%s]", c.nwdafEsUri)
+}
+
+func (c *NefContext) NwdafAiUri() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nwdafAiUri
+}
+
+func (c *NefContext) SetNwdafAiUri(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nwdafAiUri = uri
+	logger.CtxLog.Infof("Set nwdafAiUri: [%s]", c.nwdafAiUri)
+}
+
 func (c *NefContext) NewAf(afID string) *AfData {
 	af := &AfData{
-		AfID:     afID,
-		Subs:     make(map[string]*AfSubscription),
-		PfdTrans: make(map[string]*AfPfdTransaction),
-		Log:      logger.CtxLog.WithField(logger.FieldAFID, fmt.Sprintf("AF:%s", afID)),
+		AfID:       afID,
+		Subs:       make(map[string]*AfSubscription),
+		PfdTrans:   make(map[string]*AfPfdTransaction),
+		QosSubs:    make(map[string]*AfQosSubscription),
+		MonSubs:    make(map[string]*AfMonitoringSubscription),
+		DevTrigs:   make(map[string]*AfDeviceTrigger),
+		ChgParties: make(map[string]*AfChargeableParty),
+		BdtSubs:    make(map[string]*AfBdtSubscription),
+		PpSubs:     make(map[string]*AfPpSubscription),
+		LanSubs:    make(map[string]*AfLanSubscription),
+		NiddCfgs:   make(map[string]*AfNiddConfiguration),
+		AnaSubs:    make(map[string]*AfAnalyticsSubscription),
+		SpSubs:     make(map[string]*AfSpSubscription),
+		Log:        logger.CtxLog.WithField(logger.FieldAFID, fmt.Sprintf("AF:%s", afID)),
 	}
 	return af
 }
 
+// AddAf adds the AF and saves it in the store. The store is written without
+// the lock of the context, so that the lookups of the AFs don't wait for it.
 func (c *NefContext) AddAf(af *AfData) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.afs[af.AfID] = af
+	c.mu.Unlock()
+
+	c.StoreAf(af)
 	af.Log.Infoln("AF is added")
 }
 
@@ -100,31 +392,88 @@ func (c *NefContext) GetAf(afID string) *AfData {
 	return c.afs[afID]
 }
 
+// GetAfs returns a snapshot of the AFs. The lookups of the AFs iterate over the snapshot,
+// as the lock of an AF shall not be taken with the lock of the context held, which is
+// taken by e.g. NewCorreID with the lock of the AF held.
+func (c *NefContext) GetAfs() []*AfData {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	afs := make([]*AfData, 0, len(c.afs))
+	for _, af := range c.afs {
+		afs = append(afs, af)
+	}
+	return afs
+}
+
 func (c *NefContext) DeleteAf(afID string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	delete(c.afs, afID)
+	for key, owner := range c.udmDataOwners {
+		if owner.afID == afID {
+			delete(c.udmDataOwners, key)
+		}
+	}
+	c.mu.Unlock()
+
+	if err := c.store.Delete(bucketAf, afID); err != nil {
+		logger.CtxLog.Errorf("Delete AF[%s] from store error: %+v", afID, err)
+	}
 	logger.CtxLog.Infof("AF[%s] is deleted", afID)
 }
 
-func (c *NefContext) NewCorreID() uint64 {
+// ClaimUdmData records the subscription as the owner of the data provisioned in the
+// UDM for the key, so that the data isn't overwritten or deleted by another subscription.
+// False is returned if the data is owned by another subscription.
+func (c *NefContext) ClaimUdmData(key, afID, subID string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	owner, ok := c.udmDataOwners[key]
+	if ok && owner != (udmDataOwner{afID, subID}) {
+		return false
+	}
+	c.udmDataOwners[key] = udmDataOwner{afID, subID}
+	return true
+}
+
+// ReleaseUdmData removes the ownership of the data if it's owned by the subscription
+func (c *NefContext) ReleaseUdmData(key, afID, subID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.udmDataOwners[key] == (udmDataOwner{afID, subID}) {
+		delete(c.udmDataOwners, key)
+	}
+}
+
+func (c *NefContext) NewCorreID() uint64 {
+	c.mu.Lock()
 	c.numCorreID++
+	numCorreID := c.numCorreID
+	c.mu.Unlock()
+
+	c.storeNumCorreID()
+	return numCorreID
+}
+
+// NumCorreID returns the last allocated correlation ID
+func (c *NefContext) NumCorreID() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.numCorreID
 }
 
 func (c *NefContext) ResetCorreID() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.numCorreID = 0
+	c.mu.Unlock()
+
+	c.storeNumCorreID()
 }
 
 func (c *NefContext) IsAppIDExisted(appID string) (string, string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	for _, af := range c.afs {
+	for _, af := range c.GetAfs() {
 		af.Mu.RLock()
 		if transID, ok := af.IsAppIDExisted(appID); ok {
 			defer af.Mu.RUnlock()
@@ -136,9 +485,7 @@ func (c *NefContext) IsAppIDExisted(appID string) (string, string, bool) {
 }
 
 func (c *NefContext) FindAfSub(CorrID string) (*AfData, *AfSubscription) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	for _, af := range c.afs {
+	for _, af := range c.GetAfs() {
 		af.Mu.RLock()
 		for _, sub := range af.Subs {
 			if sub.NotifCorreID == CorrID {
@@ -151,6 +498,333 @@ func (c *NefContext) FindAfSub(CorrID string) (*AfData, *AfSubscription) {
 	return nil, nil
 }
 
+func (c *NefContext) FindAfQosSub(CorrID string) (*AfData, *AfQosSubscription) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, sub := range af.QosSubs {
+			if sub.NotifCorreID == CorrID {
+				defer af.Mu.RUnlock()
+				return af, sub
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+func (c *NefContext) FindAfMonSub(CorrID string) (*AfData, *AfMonitoringSubscription) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, sub := range af.MonSubs {
+			if sub.NotifCorreID == CorrID {
+				defer af.Mu.RUnlock()
+				return af, sub
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+func (c *NefContext) FindAfAnaSub(CorrID string) (*AfData, *AfAnalyticsSubscription) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, sub := range af.AnaSubs {
+			if sub.NotifCorreID == CorrID {
+				defer af.Mu.RUnlock()
+				return af, sub
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+func (c *NefContext) FindAfDevTrig(msgID string) (*AfData, *AfDeviceTrigger) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, dt := range af.DevTrigs {
+			if dt.MsgID == msgID {
+				defer af.Mu.RUnlock()
+				return af, dt
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+func (c *NefContext) FindAfChgParty(CorrID string) (*AfData, *AfChargeableParty) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, cp := range af.ChgParties {
+			if cp.NotifCorreID == CorrID {
+				defer af.Mu.RUnlock()
+				return af, cp
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+// FindAfNiddCfg returns the active NIDD configuration of the UE for the DNN.
+// The DNN isn't checked if it's not specified in the NIDD configuration.
+func (c *NefContext) FindAfNiddCfg(gpsi, dnn string) (*AfData, *AfNiddConfiguration) {
+	for _, af := range c.GetAfs() {
+		af.Mu.RLock()
+		for _, cfg := range af.NiddCfgs {
+			if cfg.Gpsi == gpsi && cfg.NiddCfg.Status == nef_models.NiddStatus_ACTIVE &&
+				(cfg.NiddCfg.Dnn == "" || cfg.NiddCfg.Dnn == dnn) {
+				defer af.Mu.RUnlock()
+				return af, cfg
+			}
+		}
+		af.Mu.RUnlock()
+	}
+	return nil, nil
+}
+
+// NewSmContext adds the SM context established by the SMF. The SM contexts
+// aren't kept in the store since the PDU sessions are released with the SMF.
+func (c *NefContext) NewSmContext(createData *nef_models.SmContextCreateData) *SmContext {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.numSmCtxID++
+	smCtx := &SmContext{
+		SmContextID: strconv.FormatUint(c.numSmCtxID, 10),
+		CreateData:  createData,
+		Log:         logger.NiddLog.WithField(logger.FieldSubID, fmt.Sprintf("SMCTX:%d", c.numSmCtxID)),
+	}
+	c.smContexts[smCtx.SmContextID] = smCtx
+	smCtx.Log.Infof("New SM context of UE[%s] PDU session[%d]", createData.Gpsi, createData.PduSessionId)
+	return smCtx
+}
+
+func (c *NefContext) GetSmContext(smContextID string) *SmContext {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.smContexts[smContextID]
+}
+
+func (c *NefContext) DeleteSmContext(smContextID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.smContexts, smContextID)
+	logger.NiddLog.Infof("SM context[%s] is deleted", smContextID)
+}
+
+// FindSmContext returns the SM context of the UE for the DNN, and any DNN if dnn is empty
+func (c *NefContext) FindSmContext(gpsi, dnn string) *SmContext {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, smCtx := range c.smContexts {
+		if smCtx.CreateData.Gpsi == gpsi && (dnn == "" || smCtx.CreateData.Dnn == dnn) {
+			return smCtx
+		}
+	}
+	return nil
+}
+
+// NewNefEeSub allocates the ID of the Nnef_EventExposure subscription from the
+// correlation IDs, which are kept in the store.
+func (c *NefContext) NewNefEeSub(eeSubsc *nef_models.NefEventExposureSubsc) *NefEeSubscription {
+	subID := strconv.FormatUint(c.NewCorreID(), 10)
+	sub := &NefEeSubscription{
+		SubID:   subID,
+		EeSubsc: eeSubsc,
+		Log:     logger.NefEeLog.WithField(logger.FieldSubID, fmt.Sprintf("EE:%s", subID)),
+	}
+	sub.Log.Infoln("New EE subscription")
+	return sub
+}
+
+func (c *NefContext) AddNefEeSub(sub *NefEeSubscription) {
+	c.mu.Lock()
+	c.nefEeSubs[sub.SubID] = sub
+	c.mu.Unlock()
+
+	c.StoreNefEeSub(sub)
+	sub.Log.Infoln("EE subscription is added")
+}
+
+// StoreNefEeSub saves the Nnef_EventExposure subscription.
+// The caller shall hold the lock of the subscription.
+func (c *NefContext) StoreNefEeSub(sub *NefEeSubscription) {
+	if err := c.store.Put(bucketNefEe, sub.SubID, sub); err != nil {
+		sub.Log.Errorf("Store EE subscription error: %+v", err)
+	}
+}
+
+func (c *NefContext) GetNefEeSub(subID string) *NefEeSubscription {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nefEeSubs[subID]
+}
+
+// GetNefEeSubs returns a snapshot of the Nnef_EventExposure subscriptions
+func (c *NefContext) GetNefEeSubs() []*NefEeSubscription {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	subs := make([]*NefEeSubscription, 0, len(c.nefEeSubs))
+	for _, sub := range c.nefEeSubs {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// FindNefEeSub returns the Nnef_EventExposure subscription of the southbound
+// EE subscription notified with correID
+func (c *NefContext) FindNefEeSub(correID string) *NefEeSubscription {
+	for _, sub := range c.GetNefEeSubs() {
+		sub.Mu.RLock()
+		found := sub.HasSouthboundSub(correID)
+		sub.Mu.RUnlock()
+		if found {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *NefContext) DeleteNefEeSub(subID string) {
+	c.mu.Lock()
+	delete(c.nefEeSubs, subID)
+	c.mu.Unlock()
+
+	if err := c.store.Delete(bucketNefEe, subID); err != nil {
+		logger.CtxLog.Errorf("Delete EE subscription[%s] from store error: %+v", subID, err)
+	}
+	logger.CtxLog.Infof("EE subscription[%s] is deleted", subID)
+}
+
+// NewAfAckWaiter allocates an ID for the AF acknowledgement of an UP path change
+// notification and returns the channel on which the acknowledgement is delivered.
+func (c *NefContext) NewAfAckWaiter() (string, <-chan *models_nef.AfAckInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ackID := uuid.New().String()
+	ch := make(chan *models_nef.AfAckInfo, 1)
+	c.afAckWaiters[ackID] = ch
+	return ackID, ch
+}
+
+func (c *NefContext) DeleteAfAckWaiter(ackID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.afAckWaiters, ackID)
+}
+
+// DeliverAfAck passes the AF acknowledgement to the waiter of ackID.
+// It returns false if nobody is waiting for the acknowledgement anymore.
+func (c *NefContext) DeliverAfAck(ackID string, afAckInfo *models_nef.AfAckInfo) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.afAckWaiters[ackID]
+	if !ok {
+		return false
+	}
+	delete(c.afAckWaiters, ackID)
+	ch <- afAckInfo
+	return true
+}
+
+// GetCachedSupi returns the SUPI translated from the gpsi if it is not expired yet.
+func (c *NefContext) GetCachedSupi(gpsi string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.supiCache[gpsi]
+	if !ok || time.Now().After(entry.expiry) {
+		return "", false
+	}
+	return entry.supi, true
+}
+
+// CacheSupi keeps the SUPI translated from the gpsi for the configured SupiCacheTtl,
+// and drops the expired ones meanwhile.
+func (c *NefContext) CacheSupi(gpsi, supi string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.supiCache {
+		if now.After(entry.expiry) {
+			delete(c.supiCache, k)
+		}
+	}
+	c.supiCache[gpsi] = supiCacheEntry{
+		supi:   supi,
+		expiry: now.Add(c.Config().SupiCacheTtl()),
+	}
+}
+
+// ResetSupiCache drops all the cached SUPIs
+func (c *NefContext) ResetSupiCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.supiCache = make(map[string]supiCacheEntry)
+}
+
+func (c *NefContext) SetNfStatusSubID(nfType models.NfType, subID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nfStatusSubIDs[nfType] = subID
+	// The expiry of the previous subscription is not applicable
+	delete(c.nfStatusSubExp, nfType)
+}
+
+func (c *NefContext) DeleteNfStatusSubID(nfType models.NfType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nfStatusSubIDs, nfType)
+	delete(c.nfStatusSubExp, nfType)
+}
+
+// SetNfStatusSubValidityTime records the expiry of the NF status subscription granted by the NRF
+func (c *NefContext) SetNfStatusSubValidityTime(nfType models.NfType, validityTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nfStatusSubExp[nfType] = validityTime
+}
+
+// NfStatusSubValidityTime returns the expiry of the NF status subscription,
+// or false if the subscription never expires.
+func (c *NefContext) NfStatusSubValidityTime(nfType models.NfType) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	validityTime, ok := c.nfStatusSubExp[nfType]
+	return validityTime, ok
+}
+
+// NfStatusSubIDs returns a copy of the IDs of the NF status subscriptions indexed by the NF type
+func (c *NefContext) NfStatusSubIDs() map[models.NfType]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	subIDs := make(map[models.NfType]string, len(c.nfStatusSubIDs))
+	for nfType, subID := range c.nfStatusSubIDs {
+		subIDs[nfType] = subID
+	}
+	return subIDs
+}
+
+func (c *NefContext) ReconcileReport() *ReconcileReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reconcileRpt
+}
+
+func (c *NefContext) SetReconcileReport(rpt *ReconcileReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconcileRpt = rpt
+}
+
 func (c *NefContext) GetTokenCtx(serviceName models.ServiceName, targetNF models.NfType) (
 	context.Context, *models.ProblemDetails, error,
 ) {
@@ -0,0 +1,95 @@
+package context
+
+import (
+	"sync"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/sirupsen/logrus"
+)
+
+// NefEeSubscription is a subscription of an NF or AF to the events exposed by
+// the NEF with Nnef_EventExposure.
+type NefEeSubscription struct {
+	SubID          string
+	EeSubsc        *nef_models.NefEventExposureSubsc
+	SouthboundSubs []*NefEeSouthboundSub
+	NumReports     int32
+	Mu             sync.RWMutex  `json:"-"`
+	Log            *logrus.Entry `json:"-"`
+}
+
+// NefEeSouthboundSub is an EE subscription of the NEF to the AMF, UDM or SMF,
+// from which the events of the Nnef_EventExposure subscription are collected.
+type NefEeSouthboundSub struct {
+	NfType       models.NfType
+	UeIdentity   string // e.g. imsi-xxx, msisdn-xxx, anyUE
+	SubID        string // ID of the subscription allocated by the NF
+	NotifCorreID string
+}
+
+// HasSouthboundSub returns true if the notifications of correID belong to the subscription
+func (s *NefEeSubscription) HasSouthboundSub(correID string) bool {
+	for _, sbSub := range s.SouthboundSubs {
+		if sbSub.NotifCorreID == correID {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterEvents returns the events matching the subscribed events and their target UEs
+func (s *NefEeSubscription) FilterEvents(
+	eventNotifs []nef_models.NefEventNotification,
+) []nef_models.NefEventNotification {
+	var matched []nef_models.NefEventNotification
+	for i := range eventNotifs {
+		for j := range s.EeSubsc.EventsSubs {
+			eventSubs := &s.EeSubsc.EventsSubs[j]
+			if eventSubs.Event != eventNotifs[i].Event {
+				continue
+			}
+			if eventSubs.EventFilter == nil || isTargetUe(eventSubs.EventFilter.TgtUe, &eventNotifs[i]) {
+				matched = append(matched, eventNotifs[i])
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// IsMaxReportsReached counts the reported events and returns true if
+// the maxReportNbr of the subscription is reached
+func (s *NefEeSubscription) IsMaxReportsReached(numReports int) bool {
+	s.NumReports += int32(numReports)
+	repInfo := s.EeSubsc.EventsRepInfo
+	return repInfo != nil && repInfo.MaxReportNbr > 0 && s.NumReports >= repInfo.MaxReportNbr
+}
+
+// IsExpired returns true if the monitoring duration of the subscription is over
+func (s *NefEeSubscription) IsExpired(now time.Time) bool {
+	repInfo := s.EeSubsc.EventsRepInfo
+	return repInfo != nil && repInfo.MonDur != nil && now.After(*repInfo.MonDur)
+}
+
+func isTargetUe(tgtUe *nef_models.TargetUeIdentification, eventNotif *nef_models.NefEventNotification) bool {
+	if tgtUe == nil || tgtUe.AnyUeId {
+		return true
+	}
+	if eventNotif.Supi != "" {
+		for _, supi := range tgtUe.Supis {
+			if supi == eventNotif.Supi {
+				return true
+			}
+		}
+	}
+	if eventNotif.Gpsi != "" {
+		for _, gpsi := range tgtUe.Gpsis {
+			if gpsi == eventNotif.Gpsi {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package context
+
+import (
+	"time"
+)
+
+const (
+	ReconcileResultVerified   string = "VERIFIED"
+	ReconcileResultRecreated  string = "RECREATED"
+	ReconcileResultUnverified string = "UNVERIFIED"
+	ReconcileResultRemoved    string = "REMOVED"
+	ReconcileResultOrphan     string = "ORPHAN_DELETED"
+)
+
+// ReconcileReport summarizes the reconciliation of the restored traffic
+// influence subscriptions against the PCF and UDR
+type ReconcileReport struct {
+	StartTime     time.Time       `json:"startTime"`
+	EndTime       time.Time       `json:"endTime"`
+	NumVerified   int             `json:"numVerified"`
+	NumRecreated  int             `json:"numRecreated"`
+	NumUnverified int             `json:"numUnverified"`
+	NumRemoved    int             `json:"numRemoved"`
+	NumOrphans    int             `json:"numOrphans"`
+	Items         []ReconcileItem `json:"items,omitempty"`
+}
+
+// ReconcileItem is a subscription or an UDR entry that is not verified as is
+type ReconcileItem struct {
+	AfID     string `json:"afId,omitempty"`
+	SubID    string `json:"subId,omitempty"`
+	InfluID  string `json:"influId,omitempty"`
+	Result   string `json:"result"`
+	Cause    string `json:"cause,omitempty"`
+	RspCode  int    `json:"rspCode,omitempty"`
+	NewResID string `json:"newResId,omitempty"`
+}
+
+func (r *ReconcileReport) AddItem(item ReconcileItem) {
+	switch item.Result {
+	case ReconcileResultVerified:
+		r.NumVerified++
+		return
+	case ReconcileResultRecreated:
+		r.NumRecreated++
+	case ReconcileResultUnverified:
+		r.NumUnverified++
+	case ReconcileResultRemoved:
+		r.NumRemoved++
+	case ReconcileResultOrphan:
+		r.NumOrphans++
+	}
+	r.Items = append(r.Items, item)
+}
@@ -0,0 +1,14 @@
+package context
+
+import (
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// SmContext is the NEF-anchored PDU session of the UE established by the SMF
+// with Nnef_SMContext for NIDD.
+type SmContext struct {
+	SmContextID string
+	CreateData  *nef_models.SmContextCreateData
+	Log         *logrus.Entry
+}
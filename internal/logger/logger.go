@@ -21,6 +21,19 @@ var (
 	PFDManageLog *logrus.Entry
 	PFDFLog      *logrus.Entry
 	OamLog       *logrus.Entry
+	NotifierLog  *logrus.Entry
+	StoreLog     *logrus.Entry
+	AsSessQosLog *logrus.Entry
+	MonEvtLog    *logrus.Entry
+	DevTrigLog   *logrus.Entry
+	ChgPartyLog  *logrus.Entry
+	BdtLog       *logrus.Entry
+	PpLog        *logrus.Entry
+	NiddLog      *logrus.Entry
+	NefEeLog     *logrus.Entry
+	AnaExpoLog   *logrus.Entry
+	SvcParamLog  *logrus.Entry
+	SmscLog      *logrus.Entry
 )
 
 const (
@@ -52,4 +65,17 @@ func init() {
 	PFDManageLog = NfLog.WithField(logger_util.FieldCategory, "PFDMng")
 	PFDFLog = NfLog.WithField(logger_util.FieldCategory, "PFDF")
 	OamLog = NfLog.WithField(logger_util.FieldCategory, "OAM")
+	NotifierLog = NfLog.WithField(logger_util.FieldCategory, "Notifier")
+	StoreLog = NfLog.WithField(logger_util.FieldCategory, "Store")
+	AsSessQosLog = NfLog.WithField(logger_util.FieldCategory, "AsSessQos")
+	MonEvtLog = NfLog.WithField(logger_util.FieldCategory, "MonEvt")
+	DevTrigLog = NfLog.WithField(logger_util.FieldCategory, "DevTrig")
+	ChgPartyLog = NfLog.WithField(logger_util.FieldCategory, "ChgParty")
+	BdtLog = NfLog.WithField(logger_util.FieldCategory, "BDT")
+	PpLog = NfLog.WithField(logger_util.FieldCategory, "PP")
+	NiddLog = NfLog.WithField(logger_util.FieldCategory, "NIDD")
+	NefEeLog = NfLog.WithField(logger_util.FieldCategory, "NefEE")
+	AnaExpoLog = NfLog.WithField(logger_util.FieldCategory, "AnaExpo")
+	SvcParamLog = NfLog.WithField(logger_util.FieldCategory, "SvcParam")
+	SmscLog = NfLog.WithField(logger_util.FieldCategory, "SMSC")
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// FiveGLanParametersProvision represents an individual 5G LAN parameters
+// provision subscription resource (TS 29.522).
+type FiveGLanParametersProvision struct {
+	// Link to the resource "Individual 5GLAN Parameters Provision Subscription"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	FiveGLanParams *FiveGLanParameters `json:"5gLanParams"`
+}
+
+// FiveGLanParameters represents the parameters of a 5G LAN group (TS 29.522).
+type FiveGLanParameters struct {
+	ExterGroupId string `json:"exterGroupId"`
+
+	// GPSIs of the members of the 5G LAN group
+	Gpsis map[string]string `json:"gpsis"`
+
+	Dnn string `json:"dnn"`
+
+	Snssai *models.Snssai `json:"snssai"`
+
+	SessionType models.PduSessionType `json:"sessionType,omitempty"`
+}
+
+// FiveGVnGroupConfiguration is the 5G VN group provisioned in the UDM with
+// Nudm_ParameterProvision (TS 29.503).
+type FiveGVnGroupConfiguration struct {
+	FiveGVnGroupData *FiveGVnGroupData `json:"5gVnGroupData,omitempty"`
+
+	Members []string `json:"members,omitempty"`
+
+	ReferenceId int32 `json:"referenceId,omitempty"`
+
+	AfInstanceId string `json:"afInstanceId,omitempty"`
+}
+
+// FiveGVnGroupData contains the session parameters of the 5G VN group (TS 29.503).
+type FiveGVnGroupData struct {
+	Dnn string `json:"dnn"`
+
+	SNssai *models.Snssai `json:"sNssai"`
+
+	PduSessionTypes []models.PduSessionType `json:"pduSessionTypes,omitempty"`
+}
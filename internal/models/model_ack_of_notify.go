@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models_nef"
+)
+
+// AckOfNotify is the acknowledgement of the UP path change notification sent
+// to the SMF (TS 29.508 clause 5.6.2.6).
+type AckOfNotify struct {
+	NotifId string `json:"notifId"`
+
+	AckResult *models_nef.AfResultInfo `json:"ackResult"`
+
+	Gpsi string `json:"gpsi,omitempty"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// AnalyticsExposureSubsc represents an analytics exposure subscription (TS 29.522).
+type AnalyticsExposureSubsc struct {
+	AnalyEventsSubs []AnalyticsEventSubsc `json:"analyEventsSubs"`
+
+	AnalyRepInfo *ReportingInformation `json:"analyRepInfo,omitempty"`
+
+	NotifUri string `json:"notifUri"`
+
+	NotifId string `json:"notifId"`
+
+	// Analytics notifications are only returned in the response for the immediate reporting
+	EventNotifs []AnalyticsEventNotif `json:"eventNotifs,omitempty"`
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+
+	// Link to the resource "Individual Analytics Exposure Subscription"
+	Self string `json:"self,omitempty"`
+}
+
+// AnalyticsEventSubsc represents an analytics event to be subscribed (TS 29.522).
+type AnalyticsEventSubsc struct {
+	AnalyEvent AnalyticsEvent `json:"analyEvent"`
+
+	AnalyEventFilter *AnalyticsEventFilter `json:"analyEventFilter,omitempty"`
+
+	TgtUe *TargetUeId `json:"tgtUe,omitempty"`
+}
+
+// AnalyticsEventFilter represents the filter of the analytics event (TS 29.522).
+type AnalyticsEventFilter struct {
+	AppIds []string `json:"appIds,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+}
+
+// TargetUeId identifies the UEs to which the analytics apply (TS 29.522).
+type TargetUeId struct {
+	AnyUeInd bool `json:"anyUeInd,omitempty"`
+
+	Gpsi string `json:"gpsi,omitempty"`
+
+	ExterGroupId string `json:"exterGroupId,omitempty"`
+}
+
+// AnalyticsEventNotification represents the notification of the analytics events
+// (TS 29.522).
+type AnalyticsEventNotification struct {
+	NotifId string `json:"notifId"`
+
+	AnalyEventNotifs []AnalyticsEventNotif `json:"analyEventNotifs"`
+}
+
+// AnalyticsEventNotif represents the analytics information of an analytics event
+// (TS 29.522).
+type AnalyticsEventNotif struct {
+	AnalyEvent AnalyticsEvent `json:"analyEvent"`
+
+	// Time at which the analytics information becomes invalid
+	Expiry *time.Time `json:"expiry,omitempty"`
+
+	TimeStamp *time.Time `json:"timeStamp"`
+
+	AnalyticsInfos
+}
+
+// AnalyticsRequest represents the request of the analytics fetch (TS 29.522).
+type AnalyticsRequest struct {
+	AnalyEvent AnalyticsEvent `json:"analyEvent"`
+
+	AnalyEventFilter *AnalyticsEventFilter `json:"analyEventFilter,omitempty"`
+
+	TgtUe *TargetUeId `json:"tgtUe,omitempty"`
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// AnalyticsData represents the analytics information of the analytics fetch
+// (TS 29.522).
+type AnalyticsData struct {
+	Start *time.Time `json:"start,omitempty"`
+
+	Expiry *time.Time `json:"expiry,omitempty"`
+
+	TimeStamp *time.Time `json:"timeStamp,omitempty"`
+
+	AnalyticsInfos
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// AnalyticsInfos carries the analytics information of the events. The information
+// is relayed from the NWDAF as is, since it doesn't identify the UEs by SUPI.
+type AnalyticsInfos struct {
+	UeMobilityInfos json.RawMessage `json:"ueMobilityInfos,omitempty"`
+
+	UeCommInfos json.RawMessage `json:"ueCommInfos,omitempty"`
+
+	AbnormalInfos json.RawMessage `json:"abnormalInfos,omitempty"`
+
+	CongestInfos json.RawMessage `json:"congestInfos,omitempty"`
+
+	NwPerfInfos json.RawMessage `json:"nwPerfInfos,omitempty"`
+
+	QosSustainInfos json.RawMessage `json:"qosSustainInfos,omitempty"`
+
+	SvcExpInfos json.RawMessage `json:"svcExpInfos,omitempty"`
+}
+
+// AnalyticsEvent represents the analytics events exposed to the AF (TS 29.522).
+type AnalyticsEvent string
+
+const (
+	AnalyticsEvent_UE_MOBILITY         AnalyticsEvent = "UE_MOBILITY"
+	AnalyticsEvent_UE_COMM             AnalyticsEvent = "UE_COMM"
+	AnalyticsEvent_ABNORMAL_BEHAVIOR   AnalyticsEvent = "ABNORMAL_BEHAVIOR"
+	AnalyticsEvent_CONGESTION          AnalyticsEvent = "CONGESTION"
+	AnalyticsEvent_NETWORK_PERFORMANCE AnalyticsEvent = "NETWORK_PERFORMANCE"
+	AnalyticsEvent_QOS_SUSTAINABILITY  AnalyticsEvent = "QOS_SUSTAINABILITY"
+	AnalyticsEvent_SERVICE_EXPERIENCE  AnalyticsEvent = "SERVICE_EXPERIENCE"
+)
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// AsSessionWithQoSSubscription represents an individual AS session with required
+// QoS subscription resource (TS 29.122 clause 5.14.2.1.2).
+type AsSessionWithQoSSubscription struct {
+	// Link to the resource "Individual AS Session with Required QoS Subscription"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	// URI where the user plane event notifications are sent to
+	NotificationDestination string `json:"notificationDestination"`
+
+	ExterAppId string `json:"exterAppId,omitempty"`
+
+	// IP flows of the application, applicable for IP UE address
+	FlowInfo []models.FlowInfo `json:"flowInfo,omitempty"`
+
+	// Ethernet flows of the application, applicable for MAC UE address
+	EthFlowInfo []models.EthFlowDescription `json:"ethFlowInfo,omitempty"`
+
+	// Pre-defined QoS information
+	QosReference string `json:"qosReference,omitempty"`
+
+	// Ordered list of the alternative pre-defined QoS information
+	AltQoSReferences []string `json:"altQoSReferences,omitempty"`
+
+	UeIpv4Addr string `json:"ueIpv4Addr,omitempty"`
+
+	UeIpv6Addr string `json:"ueIpv6Addr,omitempty"`
+
+	MacAddr string `json:"macAddr,omitempty"`
+
+	UsageThreshold *models.UsageThreshold `json:"usageThreshold,omitempty"`
+
+	// QoS requirements with the bitrates requested by the AF
+	TscQosReq *TscQosRequirement `json:"tscQosReq,omitempty"`
+}
+
+// AsSessionWithQoSSubscriptionPatch represents the modification of an AS session
+// with required QoS subscription (TS 29.122 clause 5.14.2.1.3).
+type AsSessionWithQoSSubscriptionPatch struct {
+	ExterAppId string `json:"exterAppId,omitempty"`
+
+	FlowInfo []models.FlowInfo `json:"flowInfo,omitempty"`
+
+	EthFlowInfo []models.EthFlowDescription `json:"ethFlowInfo,omitempty"`
+
+	QosReference string `json:"qosReference,omitempty"`
+
+	AltQoSReferences []string `json:"altQoSReferences,omitempty"`
+
+	UsageThreshold *models.UsageThresholdRm `json:"usageThreshold,omitempty"`
+
+	TscQosReq *TscQosRequirement `json:"tscQosReq,omitempty"`
+
+	NotificationDestination string `json:"notificationDestination,omitempty"`
+}
+
+// TscQosRequirement contains the QoS requirements requested by the AF
+// (TS 29.122 clause 5.14.2.1.4).
+type TscQosRequirement struct {
+	// Requested guaranteed bitrate for DL
+	ReqGbrDl string `json:"reqGbrDl,omitempty"`
+
+	// Requested guaranteed bitrate for UL
+	ReqGbrUl string `json:"reqGbrUl,omitempty"`
+
+	// Requested maximum bitrate for DL
+	ReqMbrDl string `json:"reqMbrDl,omitempty"`
+
+	// Requested maximum bitrate for UL
+	ReqMbrUl string `json:"reqMbrUl,omitempty"`
+
+	MaxTscBurstSize int32 `json:"maxTscBurstSize,omitempty"`
+
+	Req5Gsdelay int32 `json:"req5Gsdelay,omitempty"`
+
+	Priority int32 `json:"priority,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// Bdt represents an individual BDT subscription resource (TS 29.122 clause 5.4.2.1.2).
+type Bdt struct {
+	// Link to the resource "Individual BDT Subscription"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	// Volume of the data transfer per UE
+	VolumePerUE *models.UsageThreshold `json:"volumePerUE"`
+
+	// Number of UEs of the data transfer
+	NumberOfUEs int32 `json:"numberOfUEs"`
+
+	DesiredTimeWindow *models.TimeWindow `json:"desiredTimeWindow"`
+
+	LocationArea5G *LocationArea5G `json:"locationArea5G,omitempty"`
+
+	// BDT reference ID assigned by the PCF, read only
+	ReferenceId string `json:"referenceId,omitempty"`
+
+	// Transfer policies offered by the PCF, read only
+	TransferPolicies []TransferPolicy `json:"transferPolicies,omitempty"`
+
+	// Identity of the transfer policy selected by the AF
+	SelectedPolicy int32 `json:"selectedPolicy,omitempty"`
+
+	ExternalGroupId string `json:"externalGroupId,omitempty"`
+}
+
+// BdtPatch represents the selection of a transfer policy (TS 29.122 clause 5.4.2.1.3).
+type BdtPatch struct {
+	SelectedPolicy int32 `json:"selectedPolicy"`
+}
+
+// TransferPolicy represents a transfer policy offered for the background data
+// transfer (TS 29.122 clause 5.4.2.1.4).
+type TransferPolicy struct {
+	BdtPolicyId int32 `json:"bdtPolicyId"`
+
+	MaxBitRateDl string `json:"maxBitRateDl,omitempty"`
+
+	MaxBitRateUl string `json:"maxBitRateUl,omitempty"`
+
+	RatingGroup int32 `json:"ratingGroup"`
+
+	TimeWindow *models.TimeWindow `json:"timeWindow"`
+}
+
+// LocationArea5G represents a user location area in 5G, only the network area
+// info of the NG-RAN is supported.
+type LocationArea5G struct {
+	NwAreaInfo *models.NetworkAreaInfo `json:"nwAreaInfo,omitempty"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// ChargeableParty represents the configuration of a chargeable party
+// transaction (TS 29.122 clause 5.6.2.1.2).
+type ChargeableParty struct {
+	// Link to the resource "Individual Chargeable Party Transaction"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	// URI where the user plane event notifications are sent to
+	NotificationDestination string `json:"notificationDestination"`
+
+	ExterAppId string `json:"exterAppId,omitempty"`
+
+	Ipv4Addr string `json:"ipv4Addr,omitempty"`
+
+	Ipv6Addr string `json:"ipv6Addr,omitempty"`
+
+	MacAddr string `json:"macAddr,omitempty"`
+
+	// IP flows of the application, applicable for IP UE address
+	FlowInfo []models.FlowInfo `json:"flowInfo,omitempty"`
+
+	// Ethernet flows of the application, applicable for MAC UE address
+	EthFlowInfo []models.EthFlowDescription `json:"ethFlowInfo,omitempty"`
+
+	SponsorInformation *SponsorInformation `json:"sponsorInformation"`
+
+	// Indicates whether the sponsoring data connectivity is enabled
+	SponsoringEnabled bool `json:"sponsoringEnabled"`
+
+	// Identifies the selected transfer policy of the background data transfer
+	ReferenceId string `json:"referenceId,omitempty"`
+
+	UsageThreshold *models.UsageThreshold `json:"usageThreshold,omitempty"`
+
+	// The user plane events the AF subscribes to
+	Events []UserPlaneEvent `json:"events,omitempty"`
+}
+
+// ChargeablePartyPatch represents the modification of a chargeable party
+// transaction (TS 29.122 clause 5.6.2.1.3).
+type ChargeablePartyPatch struct {
+	ExterAppId string `json:"exterAppId,omitempty"`
+
+	FlowInfo []models.FlowInfo `json:"flowInfo,omitempty"`
+
+	EthFlowInfo []models.EthFlowDescription `json:"ethFlowInfo,omitempty"`
+
+	SponsoringEnabled *bool `json:"sponsoringEnabled,omitempty"`
+
+	ReferenceId string `json:"referenceId,omitempty"`
+
+	UsageThreshold *models.UsageThresholdRm `json:"usageThreshold,omitempty"`
+
+	NotificationDestination string `json:"notificationDestination,omitempty"`
+
+	Events []UserPlaneEvent `json:"events,omitempty"`
+}
+
+// SponsorInformation identifies the sponsor and the application service provider
+// (TS 29.122 clause 5.6.2.1.4).
+type SponsorInformation struct {
+	SponsorId string `json:"sponsorId"`
+
+	AspId string `json:"aspId"`
+}
@@ -0,0 +1,52 @@
+package models
+
+type DeliveryResult string
+
+// List of DeliveryResult (TS 29.122 clause 5.7.2.3.3)
+const (
+	DeliveryResult_SUCCESS     DeliveryResult = "SUCCESS"
+	DeliveryResult_UNKNOWN     DeliveryResult = "UNKNOWN"
+	DeliveryResult_FAILURE     DeliveryResult = "FAILURE"
+	DeliveryResult_TRIGGERED   DeliveryResult = "TRIGGERED"
+	DeliveryResult_EXPIRED     DeliveryResult = "EXPIRED"
+	DeliveryResult_UNCONFIRMED DeliveryResult = "UNCONFIRMED"
+	DeliveryResult_REPLACED    DeliveryResult = "REPLACED"
+	DeliveryResult_RECALLED    DeliveryResult = "RECALLED"
+)
+
+type Priority string
+
+// List of Priority (TS 29.122 clause 5.7.2.3.4)
+const (
+	Priority_NO_PRIORITY Priority = "NO_PRIORITY"
+	Priority_PRIORITY    Priority = "PRIORITY"
+)
+
+// DeviceTriggering is the device triggering transaction of the AF (TS 29.122 clause 5.7.2.1.2)
+type DeviceTriggering struct {
+	// Link to the resource "Individual Device Triggering Transaction"
+	Self              string `json:"self,omitempty"`
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	// One of externalId or msisdn identifies the UE
+	ExternalId string `json:"externalId,omitempty"`
+	Msisdn     string `json:"msisdn,omitempty"`
+
+	// Unit: second
+	ValidityPeriod          int32    `json:"validityPeriod"`
+	Priority                Priority `json:"priority"`
+	ApplicationPortId       int32    `json:"applicationPortId"`
+	AppSrcPortId            int32    `json:"appSrcPortId,omitempty"`
+	TriggerPayload          []byte   `json:"triggerPayload"`
+	NotificationDestination string   `json:"notificationDestination"`
+
+	DeliveryResult DeliveryResult `json:"deliveryResult,omitempty"`
+}
+
+// DeviceTriggeringDeliveryReportNotification is the delivery report sent to the AF
+// (TS 29.122 clause 5.7.2.1.3).
+type DeviceTriggeringDeliveryReportNotification struct {
+	// Link to the transaction resource to which this notification is related
+	Transaction string         `json:"transaction"`
+	Result      DeliveryResult `json:"result"`
+}
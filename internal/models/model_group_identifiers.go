@@ -0,0 +1,18 @@
+package models
+
+// GroupIdentifiers contains the identifiers of a group of UEs returned by the
+// Nudm_SubscriberDataManagement service (TS 29.503).
+type GroupIdentifiers struct {
+	ExtGroupId string `json:"extGroupId,omitempty"`
+
+	IntGroupId string `json:"intGroupId,omitempty"`
+
+	UeIdList []UeId `json:"ueIdList,omitempty"`
+}
+
+// UeId identifies a member of the group of UEs (TS 29.503).
+type UeId struct {
+	Supi string `json:"supi"`
+
+	GpsiList []string `json:"gpsiList,omitempty"`
+}
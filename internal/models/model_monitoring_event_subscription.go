@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+
+	"github.com/free5gc/openapi/models"
+)
+
+type MonitoringType string
+
+// List of MonitoringType (TS 29.122 clause 5.3.2.4.3)
+const (
+	MonitoringType_LOSS_OF_CONNECTIVITY            MonitoringType = "LOSS_OF_CONNECTIVITY"
+	MonitoringType_UE_REACHABILITY                 MonitoringType = "UE_REACHABILITY"
+	MonitoringType_LOCATION_REPORTING              MonitoringType = "LOCATION_REPORTING"
+	MonitoringType_CHANGE_OF_IMSI_IMEI_ASSOCIATION MonitoringType = "CHANGE_OF_IMSI_IMEI_ASSOCIATION"
+	MonitoringType_ROAMING_STATUS                  MonitoringType = "ROAMING_STATUS"
+	MonitoringType_COMMUNICATION_FAILURE           MonitoringType = "COMMUNICATION_FAILURE"
+	MonitoringType_AVAILABILITY_AFTER_DDN_FAILURE  MonitoringType = "AVAILABILITY_AFTER_DDN_FAILURE"
+	MonitoringType_NUMBER_OF_UES_IN_AN_AREA        MonitoringType = "NUMBER_OF_UES_IN_AN_AREA"
+	MonitoringType_PDN_CONNECTIVITY_STATUS         MonitoringType = "PDN_CONNECTIVITY_STATUS"
+	MonitoringType_DOWNLINK_DATA_DELIVERY_STATUS   MonitoringType = "DOWNLINK_DATA_DELIVERY_STATUS"
+	MonitoringType_API_SUPPORT_CAPABILITY          MonitoringType = "API_SUPPORT_CAPABILITY"
+)
+
+type ReachabilityType string
+
+// List of ReachabilityType (TS 29.122 clause 5.3.2.4.4)
+const (
+	ReachabilityType_SMS  ReachabilityType = "SMS"
+	ReachabilityType_DATA ReachabilityType = "DATA"
+)
+
+type LocationType string
+
+// List of LocationType (TS 29.122 clause 5.3.2.4.5)
+const (
+	LocationType_CURRENT_LOCATION    LocationType = "CURRENT_LOCATION"
+	LocationType_LAST_KNOWN_LOCATION LocationType = "LAST_KNOWN_LOCATION"
+)
+
+type Accuracy string
+
+// List of Accuracy (TS 29.122 clause 5.3.2.4.2), only the levels supported by 5GC are listed
+const (
+	Accuracy_CGI_ECGI Accuracy = "CGI_ECGI"
+	Accuracy_TA_RA    Accuracy = "TA_RA"
+)
+
+// MonitoringEventSubscription is the monitoring event subscription of the AF
+// (TS 29.122 clause 5.3.2.1.2).
+type MonitoringEventSubscription struct {
+	// Link to the resource "Individual Monitoring Event Subscription"
+	Self              string   `json:"self,omitempty"`
+	SupportedFeatures string   `json:"supportedFeatures,omitempty"`
+	MtcProviderId     string   `json:"mtcProviderId,omitempty"`
+	AppIds            []string `json:"appIds,omitempty"`
+
+	// One of externalId, msisdn or externalGroupId identifies the UE(s)
+	ExternalId      string `json:"externalId,omitempty"`
+	Msisdn          string `json:"msisdn,omitempty"`
+	ExternalGroupId string `json:"externalGroupId,omitempty"`
+
+	Dnn                     string         `json:"dnn,omitempty"`
+	Snssai                  *models.Snssai `json:"snssai,omitempty"`
+	NotificationDestination string         `json:"notificationDestination"`
+	MonitoringType          MonitoringType `json:"monitoringType"`
+	MaximumNumberOfReports  int32          `json:"maximumNumberOfReports,omitempty"`
+	MonitorExpireTime       *time.Time     `json:"monitorExpireTime,omitempty"`
+	LocationType            LocationType   `json:"locationType,omitempty"`
+	Accuracy                Accuracy       `json:"accuracy,omitempty"`
+
+	// Unit: second
+	MinimumReportInterval int32 `json:"minimumReportInterval,omitempty"`
+	MaximumDetectionTime  int32 `json:"maximumDetectionTime,omitempty"`
+
+	ReachabilityType ReachabilityType `json:"reachabilityType,omitempty"`
+	ImmediateRep     bool             `json:"immediateRep,omitempty"`
+
+	// Reports available at the creation of the subscription, only in the response
+	MonitoringEventReports []MonitoringEventReport `json:"monitoringEventReports,omitempty"`
+}
+
+// MonitoringEventReport is a monitoring event reported to the AF (TS 29.122 clause 5.3.2.1.4)
+type MonitoringEventReport struct {
+	ExternalId       string           `json:"externalId,omitempty"`
+	Msisdn           string           `json:"msisdn,omitempty"`
+	MonitoringType   MonitoringType   `json:"monitoringType"`
+	EventTime        *time.Time       `json:"eventTime,omitempty"`
+	LocationInfo     *LocationInfo    `json:"locationInfo,omitempty"`
+	ReachabilityType ReachabilityType `json:"reachabilityType,omitempty"`
+	RoamingStatus    *bool            `json:"roamingStatus,omitempty"`
+	PlmnId           *models.PlmnId   `json:"plmnId,omitempty"`
+	ImeiChange       string           `json:"imeiChange,omitempty"`
+}
+
+// LocationInfo is the location of the UE (TS 29.122 clause 5.3.2.2.3)
+type LocationInfo struct {
+	CellId         string         `json:"cellId,omitempty"`
+	TrackingAreaId string         `json:"trackingAreaId,omitempty"`
+	PlmnId         *models.PlmnId `json:"plmnId,omitempty"`
+}
+
+// MonitoringNotification is the notification sent to the notificationDestination
+// of the monitoring event subscription (TS 29.122 clause 5.3.2.1.3).
+type MonitoringNotification struct {
+	// Link to the subscription resource to which this notification is related
+	Subscription           string                  `json:"subscription"`
+	MonitoringEventReports []MonitoringEventReport `json:"monitoringEventReports,omitempty"`
+
+	// Set if the subscription is cancelled by the NEF, e.g. the maximum number of reports is reached
+	CancelInd bool `json:"cancelInd,omitempty"`
+}
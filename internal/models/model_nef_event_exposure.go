@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// NefEventExposureSubsc represents a subscription to the events exposed by the
+// NEF (TS 29.591).
+type NefEventExposureSubsc struct {
+	EventsSubs []NefEventSubs `json:"eventsSubs"`
+
+	EventsRepInfo *ReportingInformation `json:"eventsRepInfo,omitempty"`
+
+	NotifUri string `json:"notifUri"`
+
+	NotifId string `json:"notifId"`
+
+	// Event notifications are only returned in the response for the immediate reporting
+	EventNotifs []NefEventNotification `json:"eventNotifs,omitempty"`
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// NefEventSubs represents an event to be subscribed and the related event filter
+// (TS 29.591).
+type NefEventSubs struct {
+	Event NefEvent `json:"event"`
+
+	EventFilter *NefEventFilter `json:"eventFilter,omitempty"`
+}
+
+// NefEventFilter represents the event filter of the subscribed event (TS 29.591).
+type NefEventFilter struct {
+	TgtUe *TargetUeIdentification `json:"tgtUe"`
+}
+
+// TargetUeIdentification identifies the UEs to which the subscription applies
+// (TS 29.591).
+type TargetUeIdentification struct {
+	Supis []string `json:"supis,omitempty"`
+
+	Gpsis []string `json:"gpsis,omitempty"`
+
+	AnyUeId bool `json:"anyUeId,omitempty"`
+}
+
+// NefEventExposureNotif represents the notification of the exposed events
+// (TS 29.591).
+type NefEventExposureNotif struct {
+	NotifId string `json:"notifId"`
+
+	EventNotifs []NefEventNotification `json:"eventNotifs"`
+}
+
+// NefEventNotification represents an event reported to the consumer (TS 29.591).
+type NefEventNotification struct {
+	Event NefEvent `json:"event"`
+
+	TimeStamp *time.Time `json:"timeStamp"`
+
+	Supi string `json:"supi,omitempty"`
+
+	Gpsi string `json:"gpsi,omitempty"`
+
+	// Location of the UE for UE_MOBILITY
+	Location *models.UserLocation `json:"location,omitempty"`
+
+	// Failure cause for COMMUNICATION_FAILURE
+	CommFailure *models.CommunicationFailure `json:"commFailure,omitempty"`
+
+	// QoS notification type for QOS_SUSTAINABILITY
+	QosNotifType models.QosNotifType `json:"qosNotifType,omitempty"`
+}
+
+// NefEvent represents the events exposed by the NEF (TS 29.591).
+type NefEvent string
+
+const (
+	NefEvent_UE_MOBILITY           NefEvent = "UE_MOBILITY"
+	NefEvent_COMMUNICATION_FAILURE NefEvent = "COMMUNICATION_FAILURE"
+	NefEvent_QOS_SUSTAINABILITY    NefEvent = "QOS_SUSTAINABILITY"
+)
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// NiddConfiguration represents the configuration for NIDD (TS 29.122).
+type NiddConfiguration struct {
+	// Link to the resource "Individual NIDD Configuration"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	MtcProviderId string `json:"mtcProviderId,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	// Time until which the NIDD configuration is valid
+	Duration string `json:"duration,omitempty"`
+
+	ExternalId string `json:"externalId,omitempty"`
+
+	Msisdn string `json:"msisdn,omitempty"`
+
+	ExternalGroupId string `json:"externalGroupId,omitempty"`
+
+	ReliableDataService bool `json:"reliableDataService,omitempty"`
+
+	NotificationDestination string `json:"notificationDestination"`
+
+	MaximumPacketSize int32 `json:"maximumPacketSize,omitempty"`
+
+	Status NiddStatus `json:"status,omitempty"`
+}
+
+// NiddConfigurationPatch represents the modification of the NIDD configuration
+// (TS 29.122).
+type NiddConfigurationPatch struct {
+	Duration string `json:"duration,omitempty"`
+
+	ReliableDataService *bool `json:"reliableDataService,omitempty"`
+
+	NotificationDestination string `json:"notificationDestination,omitempty"`
+}
+
+// NiddDownlinkDataTransfer represents a downlink data delivery (TS 29.122).
+type NiddDownlinkDataTransfer struct {
+	ExternalId string `json:"externalId,omitempty"`
+
+	Msisdn string `json:"msisdn,omitempty"`
+
+	// Link to the resource "Individual Downlink Data Delivery"
+	Self string `json:"self,omitempty"`
+
+	Data []byte `json:"data"`
+
+	ReliableDataService bool `json:"reliableDataService,omitempty"`
+
+	MaximumLatency int32 `json:"maximumLatency,omitempty"`
+
+	Priority int32 `json:"priority,omitempty"`
+
+	DeliveryStatus DeliveryStatus `json:"deliveryStatus,omitempty"`
+}
+
+// NiddUplinkDataNotification carries the MO data of the UE to the AF
+// (TS 29.122).
+type NiddUplinkDataNotification struct {
+	// Link to the NIDD configuration resource
+	Niddconfiguration string `json:"niddConfiguration"`
+
+	ExternalId string `json:"externalId,omitempty"`
+
+	Msisdn string `json:"msisdn,omitempty"`
+
+	Data []byte `json:"data"`
+}
+
+// NiddDownlinkDataDeliveryStatusNotification reports the delivery status of the
+// buffered downlink data to the AF (TS 29.122).
+type NiddDownlinkDataDeliveryStatusNotification struct {
+	// Link to the NIDD configuration resource
+	Niddconfiguration string `json:"niddConfiguration"`
+
+	ExternalId string `json:"externalId,omitempty"`
+
+	Msisdn string `json:"msisdn,omitempty"`
+
+	// Link to the downlink data delivery resource
+	NiddDownlinkDataTransfer string `json:"niddDownlinkDataTransfer,omitempty"`
+
+	DeliveryStatus DeliveryStatus `json:"deliveryStatus"`
+}
+
+// NiddStatus represents the status of the NIDD configuration (TS 29.122).
+type NiddStatus string
+
+const (
+	NiddStatus_ACTIVE                       NiddStatus = "ACTIVE"
+	NiddStatus_TERMINATED_UE_NOT_AUTHORIZED NiddStatus = "TERMINATED_UE_NOT_AUTHORIZED"
+	NiddStatus_TERMINATED                   NiddStatus = "TERMINATED"
+)
+
+// DeliveryStatus represents the status of the downlink data delivery (TS 29.122).
+type DeliveryStatus string
+
+const (
+	DeliveryStatus_SUCCESS   DeliveryStatus = "SUCCESS"
+	DeliveryStatus_BUFFERING DeliveryStatus = "BUFFERING"
+	DeliveryStatus_FAILURE   DeliveryStatus = "FAILURE"
+)
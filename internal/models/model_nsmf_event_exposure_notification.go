@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// NsmfEventExposureNotification extends the openapi model with the "ackUri"
+// attribute, which the SMF includes when it requests the AF acknowledgement
+// to be sent asynchronously (TS 29.508 clause 5.6.2.5).
+type NsmfEventExposureNotification struct {
+	// Notification correlation ID
+	NotifId string `json:"notifId"`
+
+	// Notifications about Individual Events
+	EventNotifs []models.EventNotification `json:"eventNotifs"`
+
+	// Notification URI for the AF acknowledgement
+	AckUri string `json:"ackUri,omitempty"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// NnwdafEventsSubscription represents a subscription to the analytics events of
+// the NWDAF (TS 29.520).
+type NnwdafEventsSubscription struct {
+	EventSubscriptions []NwdafEventSubscription `json:"eventSubscriptions"`
+
+	EvtReq *ReportingInformation `json:"evtReq,omitempty"`
+
+	NotificationURI string `json:"notificationURI,omitempty"`
+
+	NotifCorrId string `json:"notifCorrId,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	// Notifications of the immediate reporting
+	EventNotifications []NwdafEventNotification `json:"eventNotifications,omitempty"`
+}
+
+// NwdafEventSubscription represents an analytics event subscribed to the NWDAF
+// (TS 29.520).
+type NwdafEventSubscription struct {
+	Event NwdafEvent `json:"event"`
+
+	NwdafEventFilter
+
+	TgtUe *NwdafTargetUeInformation `json:"tgtUe,omitempty"`
+}
+
+// NwdafTargetUeInformation identifies the UEs of the analytics (TS 29.520).
+type NwdafTargetUeInformation struct {
+	AnyUe bool `json:"anyUe,omitempty"`
+
+	Gpsis []string `json:"gpsis,omitempty"`
+
+	IntGroupIds []string `json:"intGroupIds,omitempty"`
+}
+
+// NwdafEventFilter represents the filter of the analytics event, which is also the
+// event-filter query parameter of the analytics information request (TS 29.520).
+type NwdafEventFilter struct {
+	AppIds []string `json:"appIds,omitempty"`
+
+	Dnns []string `json:"dnns,omitempty"`
+
+	Snssais []models.Snssai `json:"snssais,omitempty"`
+}
+
+// NnwdafEventsSubscriptionNotification represents the notification of the
+// analytics events subscribed to the NWDAF (TS 29.520).
+type NnwdafEventsSubscriptionNotification struct {
+	EventNotifications []NwdafEventNotification `json:"eventNotifications,omitempty"`
+
+	SubscriptionId string `json:"subscriptionId"`
+
+	NotifCorrId string `json:"notifCorrId,omitempty"`
+}
+
+// NwdafEventNotification represents the analytics information of an event
+// notified by the NWDAF (TS 29.520).
+type NwdafEventNotification struct {
+	Event NwdafEvent `json:"event"`
+
+	Start *time.Time `json:"start,omitempty"`
+
+	Expiry *time.Time `json:"expiry,omitempty"`
+
+	TimeStampGen *time.Time `json:"timeStampGen,omitempty"`
+
+	NwdafAnalyticsInfos
+}
+
+// NwdafAnalyticsData represents the analytics information returned by the
+// Nnwdaf_AnalyticsInfo service (TS 29.520).
+type NwdafAnalyticsData struct {
+	Start *time.Time `json:"start,omitempty"`
+
+	Expiry *time.Time `json:"expiry,omitempty"`
+
+	TimeStampGen *time.Time `json:"timeStampGen,omitempty"`
+
+	NwdafAnalyticsInfos
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// NwdafAnalyticsInfos carries the analytics information of the events (TS 29.520)
+type NwdafAnalyticsInfos struct {
+	UeMobs json.RawMessage `json:"ueMobs,omitempty"`
+
+	UeComms json.RawMessage `json:"ueComms,omitempty"`
+
+	AbnorBehavrs json.RawMessage `json:"abnorBehavrs,omitempty"`
+
+	UserDataCongInfos json.RawMessage `json:"userDataCongInfos,omitempty"`
+
+	NwPerfs json.RawMessage `json:"nwPerfs,omitempty"`
+
+	QosSustainInfos json.RawMessage `json:"qosSustainInfos,omitempty"`
+
+	SvcExps json.RawMessage `json:"svcExps,omitempty"`
+}
+
+// NwdafEvent represents the analytics events of the NWDAF (TS 29.520).
+type NwdafEvent string
+
+const (
+	NwdafEvent_UE_MOBILITY          NwdafEvent = "UE_MOBILITY"
+	NwdafEvent_UE_COMM              NwdafEvent = "UE_COMM"
+	NwdafEvent_ABNORMAL_BEHAVIOUR   NwdafEvent = "ABNORMAL_BEHAVIOUR"
+	NwdafEvent_USER_DATA_CONGESTION NwdafEvent = "USER_DATA_CONGESTION"
+	NwdafEvent_NETWORK_PERFORMANCE  NwdafEvent = "NETWORK_PERFORMANCE"
+	NwdafEvent_QOS_SUSTAINABILITY   NwdafEvent = "QOS_SUSTAINABILITY"
+	NwdafEvent_SERVICE_EXPERIENCE   NwdafEvent = "SERVICE_EXPERIENCE"
+)
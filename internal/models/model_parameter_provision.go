@@ -0,0 +1,54 @@
+package models
+
+type StationaryIndication string
+
+// List of StationaryIndication (TS 29.122)
+const (
+	StationaryIndication_STATIONARY StationaryIndication = "STATIONARY"
+	StationaryIndication_MOBILE     StationaryIndication = "MOBILE"
+)
+
+type ScheduledCommunicationType string
+
+// List of ScheduledCommunicationType (TS 29.122)
+const (
+	ScheduledCommunicationType_DOWNLINK_ONLY ScheduledCommunicationType = "DOWNLINK_ONLY"
+	ScheduledCommunicationType_UPLINK_ONLY   ScheduledCommunicationType = "UPLINK_ONLY"
+	ScheduledCommunicationType_BIDIRECTIONAL ScheduledCommunicationType = "BIDIRECTIONAL"
+)
+
+// PpConfig represents an individual parameter provisioning subscription
+// resource (TS 29.522).
+type PpConfig struct {
+	// Link to the resource "Individual ParameterProvision Subscription"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	MtcProviderId string `json:"mtcProviderId,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	ExternalId string `json:"externalId,omitempty"`
+
+	Msisdn string `json:"msisdn,omitempty"`
+
+	ExpectedUeBehaviourParameters *ExpectedUeBehaviourData `json:"expectedUeBehaviourParameters,omitempty"`
+}
+
+// ExpectedUeBehaviourData represents the expected UE behaviour parameters
+// provisioned by the AF (TS 29.522).
+type ExpectedUeBehaviourData struct {
+	StationaryIndication StationaryIndication `json:"stationaryIndication,omitempty"`
+
+	// Duration in seconds of the expected communication
+	CommunicationDurationTime int32 `json:"communicationDurationTime,omitempty"`
+
+	// Interval in seconds of the periodic communication
+	PeriodicTime int32 `json:"periodicTime,omitempty"`
+
+	ScheduledCommunicationType ScheduledCommunicationType `json:"scheduledCommunicationType,omitempty"`
+
+	// Time until which the parameters are valid
+	ValidityTime string `json:"validityTime,omitempty"`
+}
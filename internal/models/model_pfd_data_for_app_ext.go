@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// PfdDataForAppExt represents the PFDs of an application together with the
+// features negotiated for the Nnef_PFDManagement service (TS 29.551).
+type PfdDataForAppExt struct {
+	ApplicationId string `json:"applicationId"`
+
+	Pfds []models.PfdContent `json:"pfds"`
+
+	CachingTime *time.Time `json:"cachingTime,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+}
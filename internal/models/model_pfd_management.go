@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// PfdManagement represents a PFD management transaction of the AF, which is
+// notified of the PFD failures reported by the SMFs if the notificationDestination
+// is provided (TS 29.122).
+type PfdManagement struct {
+	// Link to the resource "Individual PFD Management Transaction"
+	Self string `json:"self,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+
+	// The PFDs of each external application identifier
+	PfdDatas map[string]models.PfdData `json:"pfdDatas"`
+
+	// The external application identifiers of which the PFDs are not provisioned, keyed by the failure code
+	PfdReports map[string]models.PfdReport `json:"pfdReports,omitempty"`
+
+	NotificationDestination string `json:"notificationDestination,omitempty"`
+}
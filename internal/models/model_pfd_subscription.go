@@ -0,0 +1,11 @@
+package models
+
+// PfdSubscription is the PFD subscription returned to the consumer. Unlike the one of
+// the openapi models, the supportedFeatures is omitted if no feature is negotiated.
+type PfdSubscription struct {
+	ApplicationIds []string `json:"applicationIds,omitempty"`
+
+	NotifyUri string `json:"notifyUri"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+}
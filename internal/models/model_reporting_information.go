@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ReportingInformation represents the reporting requirements of the event
+// subscription (TS 29.523).
+type ReportingInformation struct {
+	ImmRep bool `json:"immRep,omitempty"`
+
+	MaxReportNbr int32 `json:"maxReportNbr,omitempty"`
+
+	// Time at which the monitoring ends
+	MonDur *time.Time `json:"monDur,omitempty"`
+
+	// Periodic reporting interval in seconds
+	RepPeriod int32 `json:"repPeriod,omitempty"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// ServiceParameterData represents an individual service parameter subscription
+// resource (TS 29.522).
+type ServiceParameterData struct {
+	AfServiceId string `json:"afServiceId,omitempty"`
+
+	AppId string `json:"appId,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	ExternalGroupId string `json:"externalGroupId,omitempty"`
+
+	AnyUeInd bool `json:"anyUeInd,omitempty"`
+
+	Gpsi string `json:"gpsi,omitempty"`
+
+	UeIpv4Addr string `json:"ueIpv4Addr,omitempty"`
+
+	UeIpv6Addr string `json:"ueIpv6Addr,omitempty"`
+
+	UeMacAddr string `json:"ueMacAddr,omitempty"`
+
+	// Link to the resource "Individual subscription"
+	Self string `json:"self,omitempty"`
+
+	// Service parameters for V2X/ProSe communications over PC5, base64 encoded
+	ParamOverPc5 string `json:"paramOverPc5,omitempty"`
+
+	// Service parameters for V2X communications over Uu, base64 encoded
+	ParamOverUu string `json:"paramOverUu,omitempty"`
+
+	UrspGuidance []UrspRuleRequest `json:"urspGuidance,omitempty"`
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// ServiceParameterDataPatch represents the modification of a service parameter
+// subscription (TS 29.522).
+type ServiceParameterDataPatch struct {
+	ParamOverPc5 string `json:"paramOverPc5,omitempty"`
+
+	ParamOverUu string `json:"paramOverUu,omitempty"`
+
+	UrspGuidance []UrspRuleRequest `json:"urspGuidance,omitempty"`
+}
+
+// UrspRuleRequest contains the guidance for the URSP rule determination
+// (TS 29.522).
+type UrspRuleRequest struct {
+	// Traffic descriptor is relayed to the UDR as is
+	TrafficDesc json.RawMessage `json:"trafficDesc,omitempty"`
+
+	RelatPrecedence int32 `json:"relatPrecedence,omitempty"`
+
+	RouteSelParamSets []RouteSelectionParameterSet `json:"routeSelParamSets,omitempty"`
+}
+
+// RouteSelectionParameterSet contains the route selection parameters of a URSP
+// rule (TS 29.522).
+type RouteSelectionParameterSet struct {
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	Precedence int32 `json:"precedence"`
+}
+
+// ServiceParamData is the service parameter data stored in the UDR
+// application-data/serviceParamData (TS 29.519).
+type ServiceParamData struct {
+	AppId string `json:"appId,omitempty"`
+
+	Dnn string `json:"dnn,omitempty"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	InterGroupId string `json:"interGroupId,omitempty"`
+
+	Supi string `json:"supi,omitempty"`
+
+	UeIpv4 string `json:"ueIpv4,omitempty"`
+
+	UeIpv6 string `json:"ueIpv6,omitempty"`
+
+	UeMac string `json:"ueMac,omitempty"`
+
+	AnyUeInd bool `json:"anyUeInd,omitempty"`
+
+	ParamOverPc5 string `json:"paramOverPc5,omitempty"`
+
+	ParamOverUu string `json:"paramOverUu,omitempty"`
+
+	UrspGuidance []UrspRuleRequest `json:"urspGuidance,omitempty"`
+
+	SuppFeat string `json:"suppFeat,omitempty"`
+}
+
+// ServiceParamDataPatch is the modification of the service parameter data
+// stored in the UDR (TS 29.519).
+type ServiceParamDataPatch struct {
+	ParamOverPc5 string `json:"paramOverPc5,omitempty"`
+
+	ParamOverUu string `json:"paramOverUu,omitempty"`
+
+	UrspGuidance []UrspRuleRequest `json:"urspGuidance,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// SmContextCreateData is sent by the SMF to establish an NEF-anchored PDU
+// session for NIDD (TS 29.541).
+type SmContextCreateData struct {
+	Supi string `json:"supi"`
+
+	Gpsi string `json:"gpsi,omitempty"`
+
+	PduSessionId int32 `json:"pduSessionId"`
+
+	Dnn string `json:"dnn"`
+
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+
+	// NF instance ID of the SMF
+	SmfId string `json:"smfId"`
+
+	// Reference of the PDU session in the SMF, to which the MT data is delivered with Nsmf_NIDD
+	PduSessionRef string `json:"pduSessionRef"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+}
+
+// SmContextCreatedData is returned to the SMF when the SM context is created (TS 29.541).
+type SmContextCreatedData struct {
+	Gpsi string `json:"gpsi,omitempty"`
+
+	PduSessionId int32 `json:"pduSessionId"`
+
+	Dnn string `json:"dnn"`
+
+	MaximumPacketSize int32 `json:"maximumPacketSize,omitempty"`
+
+	SupportedFeatures string `json:"supportedFeatures,omitempty"`
+}
+
+// SmContextDeliverData carries the MO data of the UE from the SMF, or the MT data
+// to the SMF with Nsmf_NIDD. The data is encoded in base64 in the JSON body.
+type SmContextDeliverData struct {
+	Data []byte `json:"data"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models_nef"
+)
+
+// TrafficInfluSubNotification is sent to the notificationDestination of the traffic
+// influence subscription when its influence data is changed in the UDR by others.
+type TrafficInfluSubNotification struct {
+	// Link to the subscription resource to which this notification is related
+	Subscription string `json:"subscription"`
+
+	// The subscription updated with the influence data in the UDR
+	TrafficInfluSub *models_nef.TrafficInfluSub `json:"trafficInfluSub,omitempty"`
+
+	// Set to true if the influence data is removed and the subscription is cancelled
+	CancelInd bool `json:"cancelInd,omitempty"`
+}
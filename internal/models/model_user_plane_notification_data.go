@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+type UserPlaneEvent string
+
+// List of UserPlaneEvent (TS 29.122 clause 5.14.2.3.1)
+const (
+	UserPlaneEvent_SESSION_TERMINATION             UserPlaneEvent = "SESSION_TERMINATION"
+	UserPlaneEvent_LOSS_OF_BEARER                  UserPlaneEvent = "LOSS_OF_BEARER"
+	UserPlaneEvent_RECOVERY_OF_BEARER              UserPlaneEvent = "RECOVERY_OF_BEARER"
+	UserPlaneEvent_RELEASE_OF_BEARER               UserPlaneEvent = "RELEASE_OF_BEARER"
+	UserPlaneEvent_USAGE_REPORT                    UserPlaneEvent = "USAGE_REPORT"
+	UserPlaneEvent_FAILED_RESOURCES_ALLOCATION     UserPlaneEvent = "FAILED_RESOURCES_ALLOCATION"
+	UserPlaneEvent_QOS_GUARANTEED                  UserPlaneEvent = "QOS_GUARANTEED"
+	UserPlaneEvent_QOS_NOT_GUARANTEED              UserPlaneEvent = "QOS_NOT_GUARANTEED"
+	UserPlaneEvent_SUCCESSFUL_RESOURCES_ALLOCATION UserPlaneEvent = "SUCCESSFUL_RESOURCES_ALLOCATION"
+	UserPlaneEvent_ACCESS_TYPE_CHANGE              UserPlaneEvent = "ACCESS_TYPE_CHANGE"
+	UserPlaneEvent_PLMN_CHG                        UserPlaneEvent = "PLMN_CHG"
+)
+
+// UserPlaneNotificationData is the notification sent to the AF about the user plane
+// events of an AS session with required QoS (TS 29.122 clause 5.14.2.1.5).
+type UserPlaneNotificationData struct {
+	// Link to the transaction resource to which this notification is related
+	Transaction string `json:"transaction"`
+
+	EventReports []UserPlaneEventReport `json:"eventReports"`
+}
+
+// UserPlaneEventReport is a user plane event and the related information
+// (TS 29.122 clause 5.14.2.1.6).
+type UserPlaneEventReport struct {
+	Event UserPlaneEvent `json:"event"`
+
+	AccumulatedUsage *models.AccumulatedUsage `json:"accumulatedUsage,omitempty"`
+
+	// Identifies the affected flows
+	FlowIds []int32 `json:"flowIds,omitempty"`
+
+	// The currently applied QoS reference
+	AppliedQosRef string `json:"appliedQosRef,omitempty"`
+
+	AccessType models.AccessType `json:"accessType,omitempty"`
+
+	PlmnId *models.PlmnId `json:"plmnId,omitempty"`
+}
@@ -0,0 +1,99 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) get5GLanParameterProvisionRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiGet5GLanParametersProvisions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiPost5GLanParametersProvision,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividual5GLanParametersProvision,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividual5GLanParametersProvision,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividual5GLanParametersProvision,
+		},
+	}
+}
+
+func (s *Server) apiGet5GLanParametersProvisions(gc *gin.Context) {
+	s.Processor().Get5GLanParametersProvisions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPost5GLanParametersProvision(gc *gin.Context) {
+	var lanParams nef_models.FiveGLanParametersProvision
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&lanParams, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().Post5GLanParametersProvision(
+		gc, gc.Param("scsAsID"), &lanParams)
+}
+
+func (s *Server) apiGetIndividual5GLanParametersProvision(gc *gin.Context) {
+	s.Processor().GetIndividual5GLanParametersProvision(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividual5GLanParametersProvision(gc *gin.Context) {
+	var lanParams nef_models.FiveGLanParametersProvision
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&lanParams, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividual5GLanParametersProvision(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &lanParams)
+}
+
+func (s *Server) apiDeleteIndividual5GLanParametersProvision(gc *gin.Context) {
+	s.Processor().DeleteIndividual5GLanParametersProvision(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
@@ -0,0 +1,126 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getAnalyticsExposureRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:afID/subscriptions",
+			APIFunc: s.apiGetAnalyticsExposureSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:afID/subscriptions",
+			APIFunc: s.apiPostAnalyticsExposureSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualAnalyticsExposureSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividualAnalyticsExposureSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualAnalyticsExposureSubscription,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:afID/fetch",
+			APIFunc: s.apiPostFetchAnalyticsExposure,
+		},
+	}
+}
+
+func (s *Server) apiGetAnalyticsExposureSubscriptions(gc *gin.Context) {
+	s.Processor().GetAnalyticsExposureSubscriptions(
+		gc, gc.Param("afID"))
+}
+
+func (s *Server) apiPostAnalyticsExposureSubscription(gc *gin.Context) {
+	var anaSub nef_models.AnalyticsExposureSubsc
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&anaSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostAnalyticsExposureSubscription(
+		gc, gc.Param("afID"), &anaSub)
+}
+
+func (s *Server) apiGetIndividualAnalyticsExposureSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualAnalyticsExposureSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividualAnalyticsExposureSubscription(gc *gin.Context) {
+	var anaSub nef_models.AnalyticsExposureSubsc
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&anaSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualAnalyticsExposureSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"), &anaSub)
+}
+
+func (s *Server) apiDeleteIndividualAnalyticsExposureSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualAnalyticsExposureSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPostFetchAnalyticsExposure(gc *gin.Context) {
+	var anaReq nef_models.AnalyticsRequest
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&anaReq, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().FetchAnalyticsExposure(
+		gc, gc.Param("afID"), &anaReq)
+}
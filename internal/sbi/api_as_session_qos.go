@@ -0,0 +1,126 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getAsSessionWithQosRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiGetAsSessionWithQosSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiPostAsSessionWithQosSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualAsSessionWithQosSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividualAsSessionWithQosSubscription,
+		},
+		{
+			Method:  http.MethodPatch,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPatchIndividualAsSessionWithQosSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualAsSessionWithQosSubscription,
+		},
+	}
+}
+
+func (s *Server) apiGetAsSessionWithQosSubscriptions(gc *gin.Context) {
+	s.Processor().GetAsSessionWithQosSubscriptions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostAsSessionWithQosSubscription(gc *gin.Context) {
+	var qosSub nef_models.AsSessionWithQoSSubscription
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&qosSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostAsSessionWithQosSubscription(
+		gc, gc.Param("scsAsID"), &qosSub)
+}
+
+func (s *Server) apiGetIndividualAsSessionWithQosSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualAsSessionWithQosSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividualAsSessionWithQosSubscription(gc *gin.Context) {
+	var qosSub nef_models.AsSessionWithQoSSubscription
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&qosSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualAsSessionWithQosSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &qosSub)
+}
+
+func (s *Server) apiPatchIndividualAsSessionWithQosSubscription(gc *gin.Context) {
+	var qosSubPatch nef_models.AsSessionWithQoSSubscriptionPatch
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&qosSubPatch, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PatchIndividualAsSessionWithQosSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &qosSubPatch)
+}
+
+func (s *Server) apiDeleteIndividualAsSessionWithQosSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualAsSessionWithQosSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
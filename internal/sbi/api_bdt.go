@@ -0,0 +1,99 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getBdtRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiGetBdtSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiPostBdtSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualBdtSubscription,
+		},
+		{
+			Method:  http.MethodPatch,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPatchIndividualBdtSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualBdtSubscription,
+		},
+	}
+}
+
+func (s *Server) apiGetBdtSubscriptions(gc *gin.Context) {
+	s.Processor().GetBdtSubscriptions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostBdtSubscription(gc *gin.Context) {
+	var bdt nef_models.Bdt
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&bdt, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostBdtSubscription(
+		gc, gc.Param("scsAsID"), &bdt)
+}
+
+func (s *Server) apiGetIndividualBdtSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualBdtSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPatchIndividualBdtSubscription(gc *gin.Context) {
+	var bdtPatch nef_models.BdtPatch
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&bdtPatch, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PatchIndividualBdtSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &bdtPatch)
+}
+
+func (s *Server) apiDeleteIndividualBdtSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualBdtSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
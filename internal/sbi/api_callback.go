@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/openapi/models_nef"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,11 +18,66 @@ func (s *Server) getCallbackRoutes() []Route {
 			Pattern: "/notification/smf",
 			APIFunc: s.apiPostSmfNotification,
 		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/af-ack/:ackID",
+			APIFunc: s.apiPostAfAck,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/pcf/:correID/notify",
+			APIFunc: s.apiPostPcfEventNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/pcf/:correID/terminate",
+			APIFunc: s.apiPostPcfTerminationNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/pcf-chg-party/:correID/notify",
+			APIFunc: s.apiPostPcfChgPartyEventNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/pcf-chg-party/:correID/terminate",
+			APIFunc: s.apiPostPcfChgPartyTerminationNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/udm-ee/:correID",
+			APIFunc: s.apiPostUdmEeNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/amf-ee/:correID",
+			APIFunc: s.apiPostAmfEeNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/smf-ee/:correID",
+			APIFunc: s.apiPostSmfEeNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/nwdaf-es/:correID",
+			APIFunc: s.apiPostNwdafEventsNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/nrf-nf-status/:nfType",
+			APIFunc: s.apiPostNfStatusNotification,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/notification/udr-influence-data/:correID",
+			APIFunc: s.apiPostInfluenceDataUpdateNotification,
+		},
 	}
 }
 
 func (s *Server) apiPostSmfNotification(gc *gin.Context) {
-	var eeNotif models.NsmfEventExposureNotification
+	var eeNotif nef_models.NsmfEventExposureNotification
 	reqBody, err := gc.GetRawData()
 	if err != nil {
 		logger.SBILog.Errorf("Get Request Body error: %+v", err)
@@ -39,3 +96,234 @@ func (s *Server) apiPostSmfNotification(gc *gin.Context) {
 
 	s.Processor().SmfNotification(gc, &eeNotif)
 }
+
+func (s *Server) apiPostAfAck(gc *gin.Context) {
+	var afAckInfo models_nef.AfAckInfo
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&afAckInfo, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostAfAck(gc, gc.Param("ackID"), &afAckInfo)
+}
+
+func (s *Server) apiPostPcfEventNotification(gc *gin.Context) {
+	var evsNotif models.EventsNotification
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&evsNotif, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PcfEventNotification(gc, gc.Param("correID"), &evsNotif)
+}
+
+func (s *Server) apiPostPcfTerminationNotification(gc *gin.Context) {
+	var termInfo models.TerminationInfo
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&termInfo, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PcfTerminationNotification(gc, gc.Param("correID"), &termInfo)
+}
+
+func (s *Server) apiPostPcfChgPartyEventNotification(gc *gin.Context) {
+	var evsNotif models.EventsNotification
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&evsNotif, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PcfChgPartyEventNotification(gc, gc.Param("correID"), &evsNotif)
+}
+
+func (s *Server) apiPostPcfChgPartyTerminationNotification(gc *gin.Context) {
+	var termInfo models.TerminationInfo
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&termInfo, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PcfChgPartyTerminationNotification(gc, gc.Param("correID"), &termInfo)
+}
+
+func (s *Server) apiPostUdmEeNotification(gc *gin.Context) {
+	var monReports []models.MonitoringReport
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&monReports, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().UdmEeNotification(gc, gc.Param("correID"), monReports)
+}
+
+func (s *Server) apiPostAmfEeNotification(gc *gin.Context) {
+	var amfNotif models.AmfEventNotification
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&amfNotif, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().AmfEeNotification(gc, gc.Param("correID"), &amfNotif)
+}
+
+func (s *Server) apiPostSmfEeNotification(gc *gin.Context) {
+	var smfNotif models.NsmfEventExposureNotification
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&smfNotif, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().SmfEeNotification(gc, gc.Param("correID"), &smfNotif)
+}
+
+func (s *Server) apiPostNwdafEventsNotification(gc *gin.Context) {
+	var nwdafNotifs []nef_models.NnwdafEventsSubscriptionNotification
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&nwdafNotifs, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().NwdafEventsNotification(gc, gc.Param("correID"), nwdafNotifs)
+}
+
+func (s *Server) apiPostNfStatusNotification(gc *gin.Context) {
+	var notifData models.NotificationData
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&notifData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().NfStatusNotification(gc, gc.Param("nfType"), &notifData)
+}
+
+func (s *Server) apiPostInfluenceDataUpdateNotification(gc *gin.Context) {
+	var influDataNotifs []models.TrafficInfluDataNotif
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&influDataNotifs, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().InfluenceDataUpdateNotification(gc, gc.Param("correID"), influDataNotifs)
+}
@@ -0,0 +1,99 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getChargeablePartyRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/transactions",
+			APIFunc: s.apiGetChargeablePartyTransactions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/transactions",
+			APIFunc: s.apiPostChargeablePartyTransaction,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/transactions/:transID",
+			APIFunc: s.apiGetIndividualChargeablePartyTransaction,
+		},
+		{
+			Method:  http.MethodPatch,
+			Pattern: "/:scsAsID/transactions/:transID",
+			APIFunc: s.apiPatchIndividualChargeablePartyTransaction,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/transactions/:transID",
+			APIFunc: s.apiDeleteIndividualChargeablePartyTransaction,
+		},
+	}
+}
+
+func (s *Server) apiGetChargeablePartyTransactions(gc *gin.Context) {
+	s.Processor().GetChargeablePartyTransactions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostChargeablePartyTransaction(gc *gin.Context) {
+	var chgParty nef_models.ChargeableParty
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&chgParty, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostChargeablePartyTransaction(
+		gc, gc.Param("scsAsID"), &chgParty)
+}
+
+func (s *Server) apiGetIndividualChargeablePartyTransaction(gc *gin.Context) {
+	s.Processor().GetIndividualChargeablePartyTransaction(
+		gc, gc.Param("scsAsID"), gc.Param("transID"))
+}
+
+func (s *Server) apiPatchIndividualChargeablePartyTransaction(gc *gin.Context) {
+	var chgPartyPatch nef_models.ChargeablePartyPatch
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&chgPartyPatch, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PatchIndividualChargeablePartyTransaction(
+		gc, gc.Param("scsAsID"), gc.Param("transID"), &chgPartyPatch)
+}
+
+func (s *Server) apiDeleteIndividualChargeablePartyTransaction(gc *gin.Context) {
+	s.Processor().DeleteIndividualChargeablePartyTransaction(
+		gc, gc.Param("scsAsID"), gc.Param("transID"))
+}
@@ -0,0 +1,72 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getDeviceTriggeringRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/transactions",
+			APIFunc: s.apiGetDeviceTriggeringTransactions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/transactions",
+			APIFunc: s.apiPostDeviceTriggeringTransaction,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/transactions/:transID",
+			APIFunc: s.apiGetIndividualDeviceTriggeringTransaction,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/transactions/:transID",
+			APIFunc: s.apiDeleteIndividualDeviceTriggeringTransaction,
+		},
+	}
+}
+
+func (s *Server) apiGetDeviceTriggeringTransactions(gc *gin.Context) {
+	s.Processor().GetDeviceTriggeringTransactions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostDeviceTriggeringTransaction(gc *gin.Context) {
+	var devTrig nef_models.DeviceTriggering
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&devTrig, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostDeviceTriggeringTransaction(
+		gc, gc.Param("scsAsID"), &devTrig)
+}
+
+func (s *Server) apiGetIndividualDeviceTriggeringTransaction(gc *gin.Context) {
+	s.Processor().GetIndividualDeviceTriggeringTransaction(
+		gc, gc.Param("scsAsID"), gc.Param("transID"))
+}
+
+func (s *Server) apiDeleteIndividualDeviceTriggeringTransaction(gc *gin.Context) {
+	s.Processor().DeleteIndividualDeviceTriggeringTransaction(
+		gc, gc.Param("scsAsID"), gc.Param("transID"))
+}
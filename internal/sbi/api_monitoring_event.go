@@ -0,0 +1,99 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getMonitoringEventRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiGetMonitoringEventSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiPostMonitoringEventSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualMonitoringEventSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividualMonitoringEventSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualMonitoringEventSubscription,
+		},
+	}
+}
+
+func (s *Server) apiGetMonitoringEventSubscriptions(gc *gin.Context) {
+	s.Processor().GetMonitoringEventSubscriptions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostMonitoringEventSubscription(gc *gin.Context) {
+	var monSub nef_models.MonitoringEventSubscription
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&monSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostMonitoringEventSubscription(
+		gc, gc.Param("scsAsID"), &monSub)
+}
+
+func (s *Server) apiGetIndividualMonitoringEventSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualMonitoringEventSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividualMonitoringEventSubscription(gc *gin.Context) {
+	var monSub nef_models.MonitoringEventSubscription
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&monSub, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualMonitoringEventSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &monSub)
+}
+
+func (s *Server) apiDeleteIndividualMonitoringEventSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualMonitoringEventSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
@@ -0,0 +1,88 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getNefEventExposureRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodPost,
+			Pattern: "/subscriptions",
+			APIFunc: s.apiPostNefEeSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/subscriptions/:subscriptionID",
+			APIFunc: s.apiGetNefEeSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/subscriptions/:subscriptionID",
+			APIFunc: s.apiPutNefEeSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/subscriptions/:subscriptionID",
+			APIFunc: s.apiDeleteNefEeSubscription,
+		},
+	}
+}
+
+func (s *Server) apiPostNefEeSubscription(gc *gin.Context) {
+	var eeSubsc nef_models.NefEventExposureSubsc
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&eeSubsc, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostNefEeSubscription(gc, &eeSubsc)
+}
+
+func (s *Server) apiGetNefEeSubscription(gc *gin.Context) {
+	s.Processor().GetNefEeSubscription(
+		gc, gc.Param("subscriptionID"))
+}
+
+func (s *Server) apiPutNefEeSubscription(gc *gin.Context) {
+	var eeSubsc nef_models.NefEventExposureSubsc
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&eeSubsc, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutNefEeSubscription(
+		gc, gc.Param("subscriptionID"), &eeSubsc)
+}
+
+func (s *Server) apiDeleteNefEeSubscription(gc *gin.Context) {
+	s.Processor().DeleteNefEeSubscription(
+		gc, gc.Param("subscriptionID"))
+}
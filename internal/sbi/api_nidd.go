@@ -0,0 +1,183 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getNiddRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/configurations",
+			APIFunc: s.apiGetNiddConfigurations,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/configurations",
+			APIFunc: s.apiPostNiddConfiguration,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/configurations/:configID",
+			APIFunc: s.apiGetIndividualNiddConfiguration,
+		},
+		{
+			Method:  http.MethodPatch,
+			Pattern: "/:scsAsID/configurations/:configID",
+			APIFunc: s.apiPatchIndividualNiddConfiguration,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/configurations/:configID",
+			APIFunc: s.apiDeleteIndividualNiddConfiguration,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/configurations/:configID/downlink-data-deliveries",
+			APIFunc: s.apiGetNiddDownlinkDataDeliveries,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/configurations/:configID/downlink-data-deliveries",
+			APIFunc: s.apiPostNiddDownlinkDataDelivery,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/configurations/:configID/downlink-data-deliveries/:dlID",
+			APIFunc: s.apiGetIndividualNiddDownlinkDataDelivery,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:scsAsID/configurations/:configID/downlink-data-deliveries/:dlID",
+			APIFunc: s.apiPutIndividualNiddDownlinkDataDelivery,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/configurations/:configID/downlink-data-deliveries/:dlID",
+			APIFunc: s.apiDeleteIndividualNiddDownlinkDataDelivery,
+		},
+	}
+}
+
+func (s *Server) apiGetNiddConfigurations(gc *gin.Context) {
+	s.Processor().GetNiddConfigurations(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostNiddConfiguration(gc *gin.Context) {
+	var niddCfg nef_models.NiddConfiguration
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&niddCfg, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostNiddConfiguration(
+		gc, gc.Param("scsAsID"), &niddCfg)
+}
+
+func (s *Server) apiGetIndividualNiddConfiguration(gc *gin.Context) {
+	s.Processor().GetIndividualNiddConfiguration(
+		gc, gc.Param("scsAsID"), gc.Param("configID"))
+}
+
+func (s *Server) apiPatchIndividualNiddConfiguration(gc *gin.Context) {
+	var niddCfgPatch nef_models.NiddConfigurationPatch
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&niddCfgPatch, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PatchIndividualNiddConfiguration(
+		gc, gc.Param("scsAsID"), gc.Param("configID"), &niddCfgPatch)
+}
+
+func (s *Server) apiDeleteIndividualNiddConfiguration(gc *gin.Context) {
+	s.Processor().DeleteIndividualNiddConfiguration(
+		gc, gc.Param("scsAsID"), gc.Param("configID"))
+}
+
+func (s *Server) apiGetNiddDownlinkDataDeliveries(gc *gin.Context) {
+	s.Processor().GetNiddDownlinkDataDeliveries(
+		gc, gc.Param("scsAsID"), gc.Param("configID"))
+}
+
+func (s *Server) apiPostNiddDownlinkDataDelivery(gc *gin.Context) {
+	var dlData nef_models.NiddDownlinkDataTransfer
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&dlData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostNiddDownlinkDataDelivery(
+		gc, gc.Param("scsAsID"), gc.Param("configID"), &dlData)
+}
+
+func (s *Server) apiGetIndividualNiddDownlinkDataDelivery(gc *gin.Context) {
+	s.Processor().GetIndividualNiddDownlinkDataDelivery(
+		gc, gc.Param("scsAsID"), gc.Param("configID"), gc.Param("dlID"))
+}
+
+func (s *Server) apiPutIndividualNiddDownlinkDataDelivery(gc *gin.Context) {
+	var dlData nef_models.NiddDownlinkDataTransfer
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&dlData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualNiddDownlinkDataDelivery(
+		gc, gc.Param("scsAsID"), gc.Param("configID"), gc.Param("dlID"), &dlData)
+}
+
+func (s *Server) apiDeleteIndividualNiddDownlinkDataDelivery(gc *gin.Context) {
+	s.Processor().DeleteIndividualNiddDownlinkDataDelivery(
+		gc, gc.Param("scsAsID"), gc.Param("configID"), gc.Param("dlID"))
+}
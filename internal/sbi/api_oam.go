@@ -13,9 +13,18 @@ func (s *Server) getOamRoutes() []Route {
 			Pattern: "/",
 			APIFunc: s.apiGetOamIndex,
 		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/reconciliation",
+			APIFunc: s.apiGetReconcileReport,
+		},
 	}
 }
 
 func (s *Server) apiGetOamIndex(gc *gin.Context) {
 	s.Processor().GetOamIndex(gc)
 }
+
+func (s *Server) apiGetReconcileReport(gc *gin.Context) {
+	s.Processor().GetReconcileReport(gc)
+}
@@ -0,0 +1,99 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getParameterProvisionRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiGetParameterProvisionSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:scsAsID/subscriptions",
+			APIFunc: s.apiPostParameterProvisionSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualParameterProvisionSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividualParameterProvisionSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:scsAsID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualParameterProvisionSubscription,
+		},
+	}
+}
+
+func (s *Server) apiGetParameterProvisionSubscriptions(gc *gin.Context) {
+	s.Processor().GetParameterProvisionSubscriptions(
+		gc, gc.Param("scsAsID"))
+}
+
+func (s *Server) apiPostParameterProvisionSubscription(gc *gin.Context) {
+	var ppConfig nef_models.PpConfig
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&ppConfig, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostParameterProvisionSubscription(
+		gc, gc.Param("scsAsID"), &ppConfig)
+}
+
+func (s *Server) apiGetIndividualParameterProvisionSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualParameterProvisionSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividualParameterProvisionSubscription(gc *gin.Context) {
+	var ppConfig nef_models.PpConfig
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&ppConfig, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualParameterProvisionSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"), &ppConfig)
+}
+
+func (s *Server) apiDeleteIndividualParameterProvisionSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualParameterProvisionSubscription(
+		gc, gc.Param("scsAsID"), gc.Param("subID"))
+}
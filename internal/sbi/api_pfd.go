@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/gin-gonic/gin"
@@ -69,7 +70,7 @@ func (s *Server) apiGetPFDManagementTransactions(gc *gin.Context) {
 }
 
 func (s *Server) apiPostPFDManagementTransactions(gc *gin.Context) {
-	var pfdMng models.PfdManagement
+	var pfdMng nef_models.PfdManagement
 	reqBody, err := gc.GetRawData()
 	if err != nil {
 		logger.SBILog.Errorf("Get Request Body error: %+v", err)
@@ -99,7 +100,7 @@ func (s *Server) apiGetIndividualPFDManagementTransaction(gc *gin.Context) {
 }
 
 func (s *Server) apiPutIndividualPFDManagementTransaction(gc *gin.Context) {
-	var pfdMng models.PfdManagement
+	var pfdMng nef_models.PfdManagement
 	reqBody, err := gc.GetRawData()
 	if err != nil {
 		logger.SBILog.Errorf("Get Request Body error: %+v", err)
@@ -35,13 +35,11 @@ func (s *Server) getPFDFRoutes() []Route {
 }
 
 func (s *Server) apiGetApplicationsPFD(gc *gin.Context) {
-	// TODO: support URI query parameters: supported-features
-	s.Processor().GetApplicationsPFD(gc, gc.QueryArray("application-ids"))
+	s.Processor().GetApplicationsPFD(gc, gc.QueryArray("application-ids"), gc.Query("supported-features"))
 }
 
 func (s *Server) apiGetIndividualApplicationPFD(gc *gin.Context) {
-	// TODO: support URI query parameters: supported-features
-	s.Processor().GetIndividualApplicationPFD(gc, gc.Param("appID"))
+	s.Processor().GetIndividualApplicationPFD(gc, gc.Param("appID"), gc.Query("supported-features"))
 }
 
 func (s *Server) apiPostPFDSubscriptions(gc *gin.Context) {
@@ -0,0 +1,126 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getServiceParameterRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:afID/subscriptions",
+			APIFunc: s.apiGetServiceParameterSubscriptions,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/:afID/subscriptions",
+			APIFunc: s.apiPostServiceParameterSubscription,
+		},
+		{
+			Method:  http.MethodGet,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiGetIndividualServiceParameterSubscription,
+		},
+		{
+			Method:  http.MethodPut,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiPutIndividualServiceParameterSubscription,
+		},
+		{
+			Method:  http.MethodPatch,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiPatchIndividualServiceParameterSubscription,
+		},
+		{
+			Method:  http.MethodDelete,
+			Pattern: "/:afID/subscriptions/:subID",
+			APIFunc: s.apiDeleteIndividualServiceParameterSubscription,
+		},
+	}
+}
+
+func (s *Server) apiGetServiceParameterSubscriptions(gc *gin.Context) {
+	s.Processor().GetServiceParameterSubscriptions(
+		gc, gc.Param("afID"))
+}
+
+func (s *Server) apiPostServiceParameterSubscription(gc *gin.Context) {
+	var spData nef_models.ServiceParameterData
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&spData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostServiceParameterSubscription(
+		gc, gc.Param("afID"), &spData)
+}
+
+func (s *Server) apiGetIndividualServiceParameterSubscription(gc *gin.Context) {
+	s.Processor().GetIndividualServiceParameterSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"))
+}
+
+func (s *Server) apiPutIndividualServiceParameterSubscription(gc *gin.Context) {
+	var spData nef_models.ServiceParameterData
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&spData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PutIndividualServiceParameterSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"), &spData)
+}
+
+func (s *Server) apiPatchIndividualServiceParameterSubscription(gc *gin.Context) {
+	var spPatch nef_models.ServiceParameterDataPatch
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&spPatch, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PatchIndividualServiceParameterSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"), &spPatch)
+}
+
+func (s *Server) apiDeleteIndividualServiceParameterSubscription(gc *gin.Context) {
+	s.Processor().DeleteIndividualServiceParameterSubscription(
+		gc, gc.Param("afID"), gc.Param("subID"))
+}
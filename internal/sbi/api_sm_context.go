@@ -0,0 +1,78 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getSmContextRoutes() []Route {
+	return []Route{
+		{
+			Method:  http.MethodPost,
+			Pattern: "/sm-contexts",
+			APIFunc: s.apiPostSmContext,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/sm-contexts/:smContextID/release",
+			APIFunc: s.apiPostSmContextRelease,
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/sm-contexts/:smContextID/delivery",
+			APIFunc: s.apiPostSmContextDelivery,
+		},
+	}
+}
+
+func (s *Server) apiPostSmContext(gc *gin.Context) {
+	var createData nef_models.SmContextCreateData
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&createData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostSmContext(gc, &createData)
+}
+
+func (s *Server) apiPostSmContextRelease(gc *gin.Context) {
+	s.Processor().PostSmContextRelease(
+		gc, gc.Param("smContextID"))
+}
+
+func (s *Server) apiPostSmContextDelivery(gc *gin.Context) {
+	var moData nef_models.SmContextDeliverData
+	reqBody, err := gc.GetRawData()
+	if err != nil {
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		gc.JSON(http.StatusInternalServerError,
+			openapi.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+
+	err = openapi.Deserialize(&moData, reqBody, "application/json")
+	if err != nil {
+		logger.SBILog.Errorf("Deserialize Request Body error: %+v", err)
+		gc.JSON(http.StatusBadRequest,
+			openapi.ProblemDetailsMalformedReqSyntax(err.Error()))
+		return
+	}
+
+	s.Processor().PostSmContextDelivery(
+		gc, gc.Param("smContextID"), &moData)
+}
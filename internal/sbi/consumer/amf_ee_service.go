@@ -0,0 +1,104 @@
+package consumer
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/models"
+)
+
+// namfEeService consumes Namf_EventExposure (TS 29.518). The DeleteSubscription of the
+// openapi client indexes an empty list of content types, so the raw API is used.
+type namfEeService struct {
+	consumer *Consumer
+}
+
+func (s *namfEeService) getAmfEvtsUri() (string, error) {
+	uri := s.consumer.Context().AmfEvtsUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NAMF_EVTS, nil)
+		if err == nil {
+			s.consumer.Context().SetAmfEvtsUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// CreateAmfEeSubscription subscribes to the events of the AMF, and returns
+// the subscription ID allocated by the AMF
+func (s *namfEeService) CreateAmfEeSubscription(
+	amfSub *models.AmfCreateEventSubscription,
+) (int, interface{}, string) {
+	var (
+		err      error
+		rspCode  int
+		rspBody  interface{}
+		amfSubID string
+		result   models.AmfCreatedEventSubscription
+		rsp      *http.Response
+	)
+
+	uri, err := s.getAmfEvtsUri()
+	if err != nil {
+		return rspCode, rspBody, amfSubID
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NAMF_EVTS, models.NfType_AMF)
+	if err != nil {
+		return rspCode, rspBody, amfSubID
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/namf-evts/v1", http.MethodPost,
+		"/subscriptions", "application/json", amfSub, &result)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			logger.ConsumerLog.Debugf("CreateAmfEeSubscription RspData: %+v", result)
+			rspBody = &result
+			amfSubID = result.SubscriptionId
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, amfSubID
+}
+
+func (s *namfEeService) DeleteAmfEeSubscription(amfSubID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getAmfEvtsUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NAMF_EVTS, models.NfType_AMF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/namf-evts/v1", http.MethodDelete,
+		"/subscriptions/"+url.PathEscape(amfSubID), "", nil, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
@@ -0,0 +1,111 @@
+package consumer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/antihax/optional"
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/Nbsf_Management"
+	"github.com/free5gc/openapi/models"
+)
+
+type nbsfService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Nbsf_Management.APIClient
+}
+
+func (s *nbsfService) getClient(uri string) *Nbsf_Management.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Nbsf_Management.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Nbsf_Management.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *nbsfService) getBsfMgmtUri() (string, error) {
+	uri := s.consumer.Context().BsfMgmtUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NBSF_MANAGEMENT, nil)
+		if err == nil {
+			s.consumer.Context().SetBsfMgmtUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// InvalidateBsfMgmtUri clears the cached URI of the BSF
+func (s *nbsfService) InvalidateBsfMgmtUri() {
+	s.consumer.Context().SetBsfMgmtUri("")
+}
+
+// GetPcfBinding gets the binding of the PCF serving the PDU session of the UE address.
+// 204 No Content is returned if there is no such PDU session.
+func (s *nbsfService) GetPcfBinding(
+	ueIpv4, ueIpv6, ueMac, dnn string,
+	snssai *models.Snssai,
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		result  models.PcfBinding
+		rsp     *http.Response
+	)
+
+	uri, err := s.getBsfMgmtUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NBSF_MANAGEMENT, models.NfType_BSF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	param := &Nbsf_Management.GetPCFBindingsParamOpts{
+		Ipv4Addr:   optional.NewString(ueIpv4),
+		Ipv6Prefix: optional.NewString(ueIpv6),
+		MacAddr48:  optional.NewString(ueMac),
+		Dnn:        optional.NewString(dnn),
+	}
+	if snssai != nil {
+		var snssaiJSON []byte
+		if snssaiJSON, err = json.Marshal(snssai); err != nil {
+			return rspCode, rspBody
+		}
+		param.Snssai = optional.NewInterface(string(snssaiJSON))
+	}
+
+	result, rsp, err = client.PCFBindingsCollectionApi.GetPCFBindings(ctx, param)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusOK {
+			logger.ConsumerLog.Debugf("GetPCFBindings RspData: %+v", result)
+			rspBody = &result
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
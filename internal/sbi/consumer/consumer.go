@@ -8,9 +8,15 @@ import (
 	"github.com/free5gc/nef/pkg/app"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/Nbsf_Management"
 	"github.com/free5gc/openapi/Nnrf_NFDiscovery"
 	"github.com/free5gc/openapi/Nnrf_NFManagement"
+	"github.com/free5gc/openapi/Npcf_BDTPolicyControl"
 	"github.com/free5gc/openapi/Npcf_PolicyAuthorization"
+	"github.com/free5gc/openapi/Nsmf_EventExposure"
+	"github.com/free5gc/openapi/Nudm_EventExposure"
+	"github.com/free5gc/openapi/Nudm_ParameterProvision"
+	"github.com/free5gc/openapi/Nudm_SubscriberDataManagement"
 	"github.com/free5gc/openapi/Nudr_DataRepository"
 	"github.com/free5gc/openapi/models"
 )
@@ -28,7 +34,16 @@ type Consumer struct {
 	// consumer services
 	*nnrfService
 	*npcfService
+	*npcfBdtService
+	*nbsfService
 	*nudrService
+	*nudmEeService
+	*namfEeService
+	*nsmfEeService
+	*nudmSdmService
+	*nudmPpService
+	*nsmfNiddService
+	*nnwdafService
 }
 
 func NewConsumer(nef nef) (*Consumer, error) {
@@ -47,10 +62,53 @@ func NewConsumer(nef nef) (*Consumer, error) {
 		clients:  make(map[string]*Npcf_PolicyAuthorization.APIClient),
 	}
 
+	c.npcfBdtService = &npcfBdtService{
+		consumer: c,
+		clients:  make(map[string]*Npcf_BDTPolicyControl.APIClient),
+	}
+
+	c.nbsfService = &nbsfService{
+		consumer: c,
+		clients:  make(map[string]*Nbsf_Management.APIClient),
+	}
+
 	c.nudrService = &nudrService{
 		consumer: c,
 		clients:  make(map[string]*Nudr_DataRepository.APIClient),
 	}
+
+	c.nudmEeService = &nudmEeService{
+		consumer: c,
+		clients:  make(map[string]*Nudm_EventExposure.APIClient),
+	}
+
+	c.namfEeService = &namfEeService{
+		consumer: c,
+	}
+
+	c.nsmfEeService = &nsmfEeService{
+		consumer: c,
+		clients:  make(map[string]*Nsmf_EventExposure.APIClient),
+	}
+
+	c.nudmSdmService = &nudmSdmService{
+		consumer: c,
+		clients:  make(map[string]*Nudm_SubscriberDataManagement.APIClient),
+	}
+
+	c.nudmPpService = &nudmPpService{
+		consumer: c,
+		clients:  make(map[string]*Nudm_ParameterProvision.APIClient),
+	}
+
+	c.nsmfNiddService = &nsmfNiddService{
+		consumer: c,
+		uris:     make(map[string]string),
+	}
+
+	c.nnwdafService = &nnwdafService{
+		consumer: c,
+	}
 	return c, nil
 }
 
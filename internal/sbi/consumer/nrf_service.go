@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +21,7 @@ import (
 
 const (
 	RetryRegisterNrfDuration = 2 * time.Second
+	DefaultHeartBeatTimer    = 60 * time.Second
 )
 
 var serviceNfType map[models.ServiceName]models.NfType
@@ -39,6 +41,7 @@ func init() {
 	serviceNfType[models.ServiceName_NAMF_LOC] = models.NfType_AMF
 	serviceNfType[models.ServiceName_NSMF_PDUSESSION] = models.NfType_SMF
 	serviceNfType[models.ServiceName_NSMF_EVENT_EXPOSURE] = models.NfType_SMF
+	serviceNfType[serviceNameNsmfNidd] = models.NfType_SMF
 	serviceNfType[models.ServiceName_NAUSF_AUTH] = models.NfType_AUSF
 	serviceNfType[models.ServiceName_NAUSF_SORPROTECTION] = models.NfType_AUSF
 	serviceNfType[models.ServiceName_NAUSF_UPUPROTECTION] = models.NfType_AUSF
@@ -70,6 +73,13 @@ type nnrfService struct {
 
 	nfMngmntMu      sync.RWMutex
 	nfMngmntClients map[string]*Nnrf_NFManagement.APIClient
+
+	// The NF profile accepted by the NRF and the heartbeat timer it assigned
+	nfProfileMu    sync.RWMutex
+	nfProfile      *models.NfProfile
+	heartBeatTimer time.Duration
+	// Called after the NF instance is registered again, as the NRF dropped its subscriptions
+	reregisteredHandler func()
 }
 
 func (s *nnrfService) getNFDiscoveryClient(uri string) *Nnrf_NFDiscovery.APIClient {
@@ -142,6 +152,7 @@ func (s *nnrfService) RegisterNFInstance(ctx context.Context) error {
 			switch status {
 			case http.StatusOK:
 				// NFUpdate
+				s.setRegisteredNfProfile(nfProfile, nf.HeartBeatTimer)
 				logger.ConsumerLog.Infof("NFRegister Update")
 				return nil
 			case http.StatusCreated:
@@ -163,6 +174,8 @@ func (s *nnrfService) RegisterNFInstance(ctx context.Context) error {
 					logger.CfgLog.Error("OAuth2 enable but no nrfCertPem provided in config.")
 				}
 
+				nfProfile.NfInstanceId = s.consumer.Context().NfInstID()
+				s.setRegisteredNfProfile(nfProfile, nf.HeartBeatTimer)
 				logger.ConsumerLog.Infof("NFRegister Created")
 				return nil
 			default:
@@ -172,6 +185,122 @@ func (s *nnrfService) RegisterNFInstance(ctx context.Context) error {
 	}
 }
 
+// HeartbeatNFInstance sends the heartbeat to the NRF at the interval of the heartBeatTimer,
+// which also pushes the changes of the NF profile, until ctx is done.
+func (s *nnrfService) HeartbeatNFInstance(ctx context.Context) {
+	timer := time.NewTimer(s.getHeartBeatTimer())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := s.UpdateNFInstance(ctx); err != nil {
+				logger.ConsumerLog.Warnf("NRF heartbeat failed: %+v", err)
+			}
+			timer.Reset(s.getHeartBeatTimer())
+		}
+	}
+}
+
+// UpdateNFInstance refreshes the status of the NF instance in the NRF, and replaces the
+// NF services if they differ from the registered ones. The NF instance is registered
+// again if it is no longer known by the NRF.
+func (s *nnrfService) UpdateNFInstance(ctx context.Context) error {
+	nfProfile, err := s.buildNfProfile()
+	if err != nil {
+		return fmt.Errorf("UpdateNFInstance err: %+v", err)
+	}
+
+	patchItems := []models.PatchItem{
+		{
+			Op:    models.PatchOperation_REPLACE,
+			Path:  "/nfStatus",
+			Value: models.NfStatus_REGISTERED,
+		},
+	}
+	registered := s.getRegisteredNfProfile()
+	if registered == nil || !reflect.DeepEqual(registered.NfServices, nfProfile.NfServices) {
+		logger.ConsumerLog.Infof("NF services are changed, update NF profile in NRF")
+		patchItems = append(patchItems, models.PatchItem{
+			Op:    models.PatchOperation_REPLACE,
+			Path:  "/nfServices",
+			Value: nfProfile.NfServices,
+		})
+	}
+
+	tokenCtx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NNRF_NFM, models.NfType_NRF)
+	if err != nil {
+		return err
+	}
+
+	client := s.getNFManagementClient(s.consumer.Config().NrfUri())
+	nf, rsp, err := client.NFInstanceIDDocumentApi.UpdateNFInstance(
+		tokenCtx, s.consumer.Context().NfInstID(), patchItems)
+	if rsp != nil && rsp.Body != nil {
+		if bodyCloseErr := rsp.Body.Close(); bodyCloseErr != nil {
+			logger.ConsumerLog.Errorf("UpdateNFInstance err: response body cannot close: %+v", bodyCloseErr)
+		}
+	}
+	if rsp != nil && rsp.StatusCode == http.StatusNotFound {
+		// The NRF may have removed the NF instance after missing heartbeats or a restart
+		logger.ConsumerLog.Warnf("NF instance is not found in NRF, register again")
+		if err = s.RegisterNFInstance(ctx); err != nil {
+			return err
+		}
+		s.nfProfileMu.RLock()
+		handler := s.reregisteredHandler
+		s.nfProfileMu.RUnlock()
+		if handler != nil {
+			handler()
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("UpdateNFInstance Error[%+v]", err)
+	}
+
+	s.setRegisteredNfProfile(nfProfile, nf.HeartBeatTimer)
+	return nil
+}
+
+// SetNfReregisteredHandler sets the handler called after the NF instance is registered again
+func (s *nnrfService) SetNfReregisteredHandler(handler func()) {
+	s.nfProfileMu.Lock()
+	defer s.nfProfileMu.Unlock()
+
+	s.reregisteredHandler = handler
+}
+
+func (s *nnrfService) getRegisteredNfProfile() *models.NfProfile {
+	s.nfProfileMu.RLock()
+	defer s.nfProfileMu.RUnlock()
+	return s.nfProfile
+}
+
+// setRegisteredNfProfile records the NF profile accepted by the NRF.
+// A zero heartBeatTimer, e.g. in the 204 response, keeps the current one.
+func (s *nnrfService) setRegisteredNfProfile(nfProfile *models.NfProfile, heartBeatTimer int32) {
+	s.nfProfileMu.Lock()
+	defer s.nfProfileMu.Unlock()
+
+	s.nfProfile = nfProfile
+	if heartBeatTimer > 0 {
+		s.heartBeatTimer = time.Duration(heartBeatTimer) * time.Second
+	}
+}
+
+func (s *nnrfService) getHeartBeatTimer() time.Duration {
+	s.nfProfileMu.RLock()
+	defer s.nfProfileMu.RUnlock()
+
+	if s.heartBeatTimer == 0 {
+		return DefaultHeartBeatTimer
+	}
+	return s.heartBeatTimer
+}
+
 func (s *nnrfService) buildNfProfile() (*models.NfProfile, error) {
 	profile := &models.NfProfile{
 		NfInstanceId: s.consumer.Context().NfInstID(),
@@ -218,6 +347,106 @@ func (s *nnrfService) DeregisterNFInstance() error {
 	return nil
 }
 
+// SubscribeNFStatus subscribes to the status changes of the NF instances of nfType,
+// and returns the ID of the subscription and the validity time granted by the NRF,
+// which is nil if the subscription never expires.
+func (s *nnrfService) SubscribeNFStatus(nfType models.NfType, notifUri string) (string, *time.Time, error) {
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NNRF_NFM, models.NfType_NRF)
+	if err != nil {
+		return "", nil, err
+	}
+
+	client := s.getNFManagementClient(s.consumer.Config().NrfUri())
+	subscrData := models.NrfSubscriptionData{
+		NfStatusNotificationUri: notifUri,
+		SubscrCond: &models.NfTypeCond{
+			NfType: nfType,
+		},
+		ReqNotifEvents: []models.NotificationEventType{
+			models.NotificationEventType_DEREGISTERED,
+			models.NotificationEventType_PROFILE_CHANGED,
+		},
+		ReqNfType: models.NfType_NEF,
+	}
+
+	res, rsp, err := client.SubscriptionsCollectionApi.CreateSubscription(ctx, subscrData)
+	if rsp != nil && rsp.Body != nil {
+		if bodyCloseErr := rsp.Body.Close(); bodyCloseErr != nil {
+			logger.ConsumerLog.Errorf("SubscribeNFStatus err: response body cannot close: %+v", bodyCloseErr)
+		}
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("SubscribeNFStatus[%s] Error[%+v]", nfType, err)
+	}
+	if rsp.StatusCode != http.StatusCreated {
+		return "", nil, fmt.Errorf("SubscribeNFStatus[%s] NRF return wrong status: %d", nfType, rsp.StatusCode)
+	}
+
+	subscriptionID := res.SubscriptionId
+	if loc := rsp.Header.Get("Location"); subscriptionID == "" && loc != "" {
+		subscriptionID = loc[strings.LastIndex(loc, "/")+1:]
+	}
+	return subscriptionID, res.ValidityTime, nil
+}
+
+// UpdateNFStatusSubscription requests the NRF to extend the subscription to validityTime,
+// and returns the validity time granted by the NRF.
+func (s *nnrfService) UpdateNFStatusSubscription(subscriptionID string, validityTime time.Time) (*time.Time, error) {
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NNRF_NFM, models.NfType_NRF)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s.getNFManagementClient(s.consumer.Config().NrfUri())
+	patchItems := []models.PatchItem{
+		{
+			Op:    models.PatchOperation_REPLACE,
+			Path:  "/validityTime",
+			Value: validityTime,
+		},
+	}
+	res, rsp, err := client.SubscriptionIDDocumentApi.UpdateSubscription(ctx, subscriptionID, patchItems)
+	if rsp != nil && rsp.Body != nil {
+		if bodyCloseErr := rsp.Body.Close(); bodyCloseErr != nil {
+			logger.ConsumerLog.Errorf("UpdateNFStatusSubscription err: response body cannot close: %+v", bodyCloseErr)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("UpdateNFStatusSubscription[%s] Error[%+v]", subscriptionID, err)
+	}
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		if res.ValidityTime != nil {
+			return res.ValidityTime, nil
+		}
+		return &validityTime, nil
+	case http.StatusNoContent:
+		return &validityTime, nil
+	default:
+		return nil, fmt.Errorf("UpdateNFStatusSubscription[%s] NRF return wrong status: %d",
+			subscriptionID, rsp.StatusCode)
+	}
+}
+
+func (s *nnrfService) UnsubscribeNFStatus(subscriptionID string) error {
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NNRF_NFM, models.NfType_NRF)
+	if err != nil {
+		return err
+	}
+
+	client := s.getNFManagementClient(s.consumer.Config().NrfUri())
+	rsp, err := client.SubscriptionIDDocumentApi.RemoveSubscription(ctx, subscriptionID)
+	if rsp != nil && rsp.Body != nil {
+		if bodyCloseErr := rsp.Body.Close(); bodyCloseErr != nil {
+			logger.ConsumerLog.Errorf("UnsubscribeNFStatus err: response body cannot close: %+v", bodyCloseErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("UnsubscribeNFStatus[%s] Error[%+v]", subscriptionID, err)
+	}
+	return nil
+}
+
 func (s *nnrfService) SearchNFInstances(
 	nrfUri string,
 	srvName models.ServiceName,
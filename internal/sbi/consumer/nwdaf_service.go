@@ -0,0 +1,225 @@
+package consumer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+)
+
+// nnwdafService consumes Nnwdaf_EventsSubscription and Nnwdaf_AnalyticsInfo (TS 29.520),
+// which have no openapi client.
+type nnwdafService struct {
+	consumer *Consumer
+}
+
+func (s *nnwdafService) getNwdafEsUri() (string, error) {
+	uri := s.consumer.Context().NwdafEsUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NNWDAF_EVENTSSUBSCRIPTION, nil)
+		if err == nil {
+			s.consumer.Context().SetNwdafEsUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+func (s *nnwdafService) getNwdafAiUri() (string, error) {
+	uri := s.consumer.Context().NwdafAiUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NNWDAF_ANALYTICSINFO, nil)
+		if err == nil {
+			s.consumer.Context().SetNwdafAiUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// CreateNwdafEventsSubscription subscribes to the analytics events of the NWDAF,
+// and returns the subscription ID allocated by the NWDAF
+func (s *nnwdafService) CreateNwdafEventsSubscription(
+	eventsSub *nef_models.NnwdafEventsSubscription,
+) (int, interface{}, string) {
+	var (
+		err        error
+		rspCode    int
+		rspBody    interface{}
+		nwdafSubID string
+		result     nef_models.NnwdafEventsSubscription
+		rsp        *http.Response
+	)
+
+	uri, err := s.getNwdafEsUri()
+	if err != nil {
+		return rspCode, rspBody, nwdafSubID
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(
+		models.ServiceName_NNWDAF_EVENTSSUBSCRIPTION, models.NfType_NWDAF)
+	if err != nil {
+		return rspCode, rspBody, nwdafSubID
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nnwdaf-eventssubscription/v1", http.MethodPost,
+		"/subscriptions", "application/json", eventsSub, &result)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			logger.ConsumerLog.Debugf("CreateNwdafEventsSubscription RspData: %+v", result)
+			rspBody = &result
+			loc := rsp.Header.Get("Location")
+			nwdafSubID = loc[strings.LastIndex(loc, "/")+1:]
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, nwdafSubID
+}
+
+func (s *nnwdafService) UpdateNwdafEventsSubscription(
+	nwdafSubID string,
+	eventsSub *nef_models.NnwdafEventsSubscription,
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getNwdafEsUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(
+		models.ServiceName_NNWDAF_EVENTSSUBSCRIPTION, models.NfType_NWDAF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nnwdaf-eventssubscription/v1", http.MethodPut,
+		"/subscriptions/"+url.PathEscape(nwdafSubID), "application/json", eventsSub, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
+
+func (s *nnwdafService) DeleteNwdafEventsSubscription(nwdafSubID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getNwdafEsUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(
+		models.ServiceName_NNWDAF_EVENTSSUBSCRIPTION, models.NfType_NWDAF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nnwdaf-eventssubscription/v1", http.MethodDelete,
+		"/subscriptions/"+url.PathEscape(nwdafSubID), "", nil, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
+
+// GetNwdafAnalytics fetches the analytics information of the event from the NWDAF.
+// The NWDAF responds 204 No Content if there's no analytics information.
+func (s *nnwdafService) GetNwdafAnalytics(
+	event nef_models.NwdafEvent,
+	eventFilter *nef_models.NwdafEventFilter,
+	tgtUe *nef_models.NwdafTargetUeInformation,
+	suppFeat string,
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		result  nef_models.NwdafAnalyticsData
+		rsp     *http.Response
+	)
+
+	uri, err := s.getNwdafAiUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(
+		models.ServiceName_NNWDAF_ANALYTICSINFO, models.NfType_NWDAF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	query := url.Values{}
+	query.Set("event-id", string(event))
+	if eventFilter != nil {
+		var b []byte
+		if b, err = json.Marshal(eventFilter); err != nil {
+			return handleAPIServiceNoResponse(err)
+		}
+		query.Set("event-filter", string(b))
+	}
+	if tgtUe != nil {
+		var b []byte
+		if b, err = json.Marshal(tgtUe); err != nil {
+			return handleAPIServiceNoResponse(err)
+		}
+		query.Set("tgt-ue", string(b))
+	}
+	if suppFeat != "" {
+		query.Set("supported-features", suppFeat)
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nnwdaf-analyticsinfo/v1", http.MethodGet,
+		"/analytics?"+query.Encode(), "", nil, &result)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusOK {
+			logger.ConsumerLog.Debugf("GetNwdafAnalytics RspData: %+v", result)
+			rspBody = &result
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
@@ -0,0 +1,156 @@
+package consumer
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/Npcf_BDTPolicyControl"
+	"github.com/free5gc/openapi/models"
+)
+
+type npcfBdtService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Npcf_BDTPolicyControl.APIClient
+}
+
+func (s *npcfBdtService) getClient(uri string) *Npcf_BDTPolicyControl.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Npcf_BDTPolicyControl.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Npcf_BDTPolicyControl.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *npcfBdtService) getPcfBdtUri() (string, error) {
+	uri := s.consumer.Context().PcfBdtUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NPCF_BDTPOLICYCONTROL, nil)
+		if err == nil {
+			s.consumer.Context().SetPcfBdtUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// InvalidatePcfBdtUri clears the cached URI of the PCF for the BDT policies
+func (s *npcfBdtService) InvalidatePcfBdtUri() {
+	s.consumer.Context().SetPcfBdtUri("")
+}
+
+// CreateBDTPolicy requests the PCF for the transfer policies of the background
+// data transfer, and returns the BDT policy with the ID of the individual BDT policy.
+func (s *npcfBdtService) CreateBDTPolicy(bdtReqData *models.BdtReqData) (int, interface{}, string) {
+	var (
+		err         error
+		rspCode     int
+		rspBody     interface{}
+		bdtPolicyID string
+		result      models.BdtPolicy
+		rsp         *http.Response
+	)
+
+	uri, err := s.getPcfBdtUri()
+	if err != nil {
+		return rspCode, rspBody, bdtPolicyID
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NPCF_BDTPOLICYCONTROL, models.NfType_PCF)
+	if err != nil {
+		return rspCode, rspBody, bdtPolicyID
+	}
+
+	result, rsp, err = client.BDTPoliciesCollectionApi.CreateBDTPolicy(ctx, *bdtReqData)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			logger.ConsumerLog.Debugf("CreateBDTPolicy RspData: %+v", result)
+			rspBody = &result
+			loc := rsp.Header.Get("Location")
+			bdtPolicyID = loc[strings.LastIndex(loc, "/")+1:]
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, bdtPolicyID
+}
+
+// UpdateBDTPolicy informs the PCF of the transfer policy selected by the AF
+func (s *npcfBdtService) UpdateBDTPolicy(
+	bdtPolicyID string,
+	bdtPolicyDataPatch *models.BdtPolicyDataPatch,
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		result  models.BdtPolicy
+		rsp     *http.Response
+	)
+
+	uri, err := s.getPcfBdtUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NPCF_BDTPOLICYCONTROL, models.NfType_PCF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	result, rsp, err = client.IndividualBDTPolicyDocumentApi.UpdateBDTPolicy(
+		ctx, bdtPolicyID, *bdtPolicyDataPatch)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusOK {
+			logger.ConsumerLog.Debugf("UpdateBDTPolicy RspData: %+v", result)
+			rspBody = &result
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
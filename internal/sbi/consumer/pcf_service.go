@@ -7,6 +7,7 @@ import (
 
 	"github.com/antihax/optional"
 	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/Nnrf_NFDiscovery"
 	"github.com/free5gc/openapi/Npcf_PolicyAuthorization"
 	"github.com/free5gc/openapi/models"
 )
@@ -36,20 +37,91 @@ func (s *npcfService) getClient(uri string) *Npcf_PolicyAuthorization.APIClient
 	}
 }
 
-func (s *npcfService) getPcfPolicyAuthUri() (string, error) {
+// getPcfPolicyAuthUri returns the PCF discovered from the NRF and its NF instance ID
+func (s *npcfService) getPcfPolicyAuthUri() (string, string, error) {
 	uri := s.consumer.Context().PcfPaUri()
 	if uri == "" {
-		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+		nfProf, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
 			models.ServiceName_NPCF_POLICYAUTHORIZATION, nil)
+		if err != nil {
+			return "", "", err
+		}
+		s.consumer.Context().SetPcfPaUri(sUri)
+		s.consumer.Context().SetPcfPaID(nfProf.NfInstanceId)
+		return sUri, nfProf.NfInstanceId, nil
+	}
+	return uri, s.consumer.Context().PcfPaID(), nil
+}
+
+// InvalidatePcfPolicyAuthUri drops the cached PCF, so that it is discovered from the NRF again at next use
+func (s *npcfService) InvalidatePcfPolicyAuthUri() {
+	s.consumer.Context().SetPcfPaUri("")
+	s.consumer.Context().SetPcfPaID("")
+}
+
+// SelectPcfPolicyAuthUri finds the PCF serving the PDU session of the UE from the BSF,
+// and returns its URI and NF instance ID. The PCF discovered from the NRF is selected
+// if the binding is not found in the BSF. The NF instance ID is empty if the binding
+// only has the address of the PCF.
+func (s *npcfService) SelectPcfPolicyAuthUri(
+	ueIpv4, ueIpv6, ueMac, dnn string,
+	snssai *models.Snssai,
+) (string, string, error) {
+	if ueIpv4 == "" && ueIpv6 == "" && ueMac == "" {
+		return s.getPcfPolicyAuthUri()
+	}
+
+	rspCode, rspBody := s.consumer.GetPcfBinding(ueIpv4, ueIpv6, ueMac, dnn, snssai)
+	if binding, ok := rspBody.(*models.PcfBinding); ok && rspCode == http.StatusOK {
+		if uri := s.getPcfPolicyAuthUriByBinding(binding); uri != "" {
+			return uri, binding.PcfId, nil
+		}
+	}
+	logger.ConsumerLog.Infof("PCF binding is not found in BSF: rspCode[%d], select PCF from NRF", rspCode)
+	return s.getPcfPolicyAuthUri()
+}
+
+// DiscoverPcfPolicyAuthUri finds the URI of the PCF of the NF instance ID from the NRF
+func (s *npcfService) DiscoverPcfPolicyAuthUri(pcfID string) (string, error) {
+	_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+		models.ServiceName_NPCF_POLICYAUTHORIZATION, &Nnrf_NFDiscovery.SearchNFInstancesParamOpts{
+			TargetNfInstanceId: optional.NewInterface(pcfID),
+		})
+	return sUri, err
+}
+
+func (s *npcfService) getPcfPolicyAuthUriByBinding(binding *models.PcfBinding) string {
+	if binding.PcfId != "" {
+		sUri, err := s.DiscoverPcfPolicyAuthUri(binding.PcfId)
 		if err == nil {
-			s.consumer.Context().SetPcfPaUri(sUri)
+			return sUri
+		}
+		logger.ConsumerLog.Warnf("Discover PCF[%s] failed: %+v", binding.PcfId, err)
+	}
+
+	scheme := models.UriScheme(s.consumer.Config().SbiScheme())
+	for _, point := range binding.PcfIpEndPoints {
+		if point.Ipv4Address != "" {
+			return getUriFromIpEndPoint(scheme, point.Ipv4Address, point.Port)
 		}
-		return sUri, err
 	}
-	return uri, nil
+	if binding.PcfFqdn != "" {
+		return getUriFromIpEndPoint(scheme, binding.PcfFqdn, 0)
+	}
+	return ""
+}
+
+// getPcfUri returns the PCF selected for the UE, or the PCF discovered from the NRF
+// if no PCF is selected.
+func (s *npcfService) getPcfUri(pcfUri string) (string, error) {
+	if pcfUri != "" {
+		return pcfUri, nil
+	}
+	uri, _, err := s.getPcfPolicyAuthUri()
+	return uri, err
 }
 
-func (s *npcfService) GetAppSession(appSessionId string) (int, interface{}) {
+func (s *npcfService) GetAppSession(pcfUri, appSessionId string) (int, interface{}) {
 	var (
 		err     error
 		rspCode int
@@ -58,7 +130,7 @@ func (s *npcfService) GetAppSession(appSessionId string) (int, interface{}) {
 		rsp     *http.Response
 	)
 
-	uri, err := s.getPcfPolicyAuthUri()
+	uri, err := s.getPcfUri(pcfUri)
 	if err != nil {
 		return rspCode, rspBody
 	}
@@ -96,7 +168,7 @@ func (s *npcfService) GetAppSession(appSessionId string) (int, interface{}) {
 	return rspCode, rspBody
 }
 
-func (s *npcfService) PostAppSessions(asc *models.AppSessionContext) (int, interface{}, string) {
+func (s *npcfService) PostAppSessions(pcfUri string, asc *models.AppSessionContext) (int, interface{}, string) {
 	var (
 		err       error
 		rspCode   int
@@ -106,7 +178,7 @@ func (s *npcfService) PostAppSessions(asc *models.AppSessionContext) (int, inter
 		rsp       *http.Response
 	)
 
-	uri, err := s.getPcfPolicyAuthUri()
+	uri, err := s.getPcfUri(pcfUri)
 	if err != nil {
 		return rspCode, rspBody, appSessID
 	}
@@ -145,7 +217,7 @@ func (s *npcfService) PostAppSessions(asc *models.AppSessionContext) (int, inter
 }
 
 func (s *npcfService) PutAppSession(
-	appSessionId string,
+	pcfUri, appSessionId string,
 	ascUpdateData *models.AppSessionContextUpdateData,
 	asc *models.AppSessionContext,
 ) (int, interface{}, string) {
@@ -158,7 +230,7 @@ func (s *npcfService) PutAppSession(
 		rsp       *http.Response
 	)
 
-	uri, err := s.getPcfPolicyAuthUri()
+	uri, err := s.getPcfUri(pcfUri)
 	if err != nil {
 		return rspCode, rspBody, appSessID
 	}
@@ -221,7 +293,7 @@ func (s *npcfService) PutAppSession(
 	return rspCode, rspBody, appSessID
 }
 
-func (s *npcfService) PatchAppSession(appSessionId string,
+func (s *npcfService) PatchAppSession(pcfUri, appSessionId string,
 	ascUpdateData *models.AppSessionContextUpdateData,
 ) (int, interface{}) {
 	var (
@@ -232,7 +304,7 @@ func (s *npcfService) PatchAppSession(appSessionId string,
 		rsp     *http.Response
 	)
 
-	uri, err := s.getPcfPolicyAuthUri()
+	uri, err := s.getPcfUri(pcfUri)
 	if err != nil {
 		return rspCode, rspBody
 	}
@@ -270,7 +342,7 @@ func (s *npcfService) PatchAppSession(appSessionId string,
 	return rspCode, rspBody
 }
 
-func (s *npcfService) DeleteAppSession(appSessionId string) (int, interface{}) {
+func (s *npcfService) DeleteAppSession(pcfUri, appSessionId string) (int, interface{}) {
 	var (
 		err     error
 		rspCode int
@@ -279,7 +351,7 @@ func (s *npcfService) DeleteAppSession(appSessionId string) (int, interface{}) {
 		rsp     *http.Response
 	)
 
-	uri, err := s.getPcfPolicyAuthUri()
+	uri, err := s.getPcfUri(pcfUri)
 	if err != nil {
 		return rspCode, rspBody
 	}
@@ -0,0 +1,105 @@
+package consumer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+)
+
+// rawConfiguration implements openapi.Configuration for the requests of the
+// services which have no openapi client, e.g. Nsmf_NIDD.
+type rawConfiguration struct {
+	basePath      string
+	defaultHeader map[string]string
+}
+
+func newRawConfiguration(uri string) *rawConfiguration {
+	return &rawConfiguration{
+		basePath:      uri,
+		defaultHeader: make(map[string]string),
+	}
+}
+
+func (c *rawConfiguration) BasePath() string {
+	return c.basePath
+}
+
+func (c *rawConfiguration) Host() string {
+	return ""
+}
+
+func (c *rawConfiguration) UserAgent() string {
+	return "NEF"
+}
+
+func (c *rawConfiguration) DefaultHeader() map[string]string {
+	return c.defaultHeader
+}
+
+func (c *rawConfiguration) HTTPClient() *http.Client {
+	return nil
+}
+
+// callRawAPI builds the request with the openapi helpers, and returns the
+// ProblemDetails in the openapi.GenericOpenAPIError as the openapi client does.
+// The body of a successful response is decoded into rspData if it's not nil.
+func callRawAPI(
+	ctx context.Context,
+	uri, method, path, contentType string,
+	body interface{},
+	rspData interface{},
+) (*http.Response, error) {
+	cfg := newRawConfiguration(uri)
+
+	headers := map[string]string{
+		"Accept": "application/json, application/problem+json",
+	}
+	if body != nil {
+		headers["Content-Type"] = contentType
+	}
+	req, err := openapi.PrepareRequest(ctx, cfg, cfg.BasePath()+path, method, body, headers,
+		url.Values{}, url.Values{}, "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := openapi.CallAPI(cfg, req)
+	if err != nil || rsp == nil {
+		return rsp, err
+	}
+
+	rspBody, err := io.ReadAll(rsp.Body)
+	if closeErr := rsp.Body.Close(); closeErr != nil {
+		logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", closeErr)
+	}
+	if err != nil {
+		return rsp, err
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		if rspData != nil && len(rspBody) > 0 {
+			err = openapi.Deserialize(rspData, rspBody, rsp.Header.Get("Content-Type"))
+		}
+		return rsp, err
+	case http.StatusNoContent:
+		return rsp, nil
+	default:
+		apiError := openapi.GenericOpenAPIError{
+			RawBody:     rspBody,
+			ErrorStatus: rsp.Status,
+		}
+		var pd models.ProblemDetails
+		if err = openapi.Deserialize(&pd, rspBody, rsp.Header.Get("Content-Type")); err != nil {
+			apiError.ErrorStatus = err.Error()
+			return rsp, apiError
+		}
+		apiError.ErrorModel = pd
+		return rsp, apiError
+	}
+}
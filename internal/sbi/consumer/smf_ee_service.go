@@ -0,0 +1,142 @@
+package consumer
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/Nsmf_EventExposure"
+	"github.com/free5gc/openapi/models"
+)
+
+type nsmfEeService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Nsmf_EventExposure.APIClient
+}
+
+func (s *nsmfEeService) getClient(uri string) *Nsmf_EventExposure.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Nsmf_EventExposure.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Nsmf_EventExposure.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *nsmfEeService) getSmfEeUri() (string, error) {
+	uri := s.consumer.Context().SmfEeUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NSMF_EVENT_EXPOSURE, nil)
+		if err == nil {
+			s.consumer.Context().SetSmfEeUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// CreateSmfEeSubscription subscribes to the events of the SMF, and returns
+// the subscription ID allocated by the SMF
+func (s *nsmfEeService) CreateSmfEeSubscription(
+	smfSub *models.NsmfEventExposure,
+) (int, interface{}, string) {
+	var (
+		err      error
+		rspCode  int
+		rspBody  interface{}
+		smfSubID string
+		result   models.NsmfEventExposure
+		rsp      *http.Response
+	)
+
+	uri, err := s.getSmfEeUri()
+	if err != nil {
+		return rspCode, rspBody, smfSubID
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NSMF_EVENT_EXPOSURE, models.NfType_SMF)
+	if err != nil {
+		return rspCode, rspBody, smfSubID
+	}
+
+	result, rsp, err = client.DefaultApi.SubscriptionsPost(ctx, *smfSub)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			logger.ConsumerLog.Debugf("CreateSmfEeSubscription RspData: %+v", result)
+			rspBody = &result
+			smfSubID = result.SubId
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, smfSubID
+}
+
+func (s *nsmfEeService) DeleteSmfEeSubscription(smfSubID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getSmfEeUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NSMF_EVENT_EXPOSURE, models.NfType_SMF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = client.DefaultApi.SubscriptionsSubIdDelete(ctx, smfSubID)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode != http.StatusNoContent && err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
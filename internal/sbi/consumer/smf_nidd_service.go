@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/antihax/optional"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/Nnrf_NFDiscovery"
+	"github.com/free5gc/openapi/models"
+)
+
+// serviceNameNsmfNidd is the Nsmf_NIDD service (TS 29.542), which isn't defined in the openapi models
+const serviceNameNsmfNidd models.ServiceName = "nsmf-nidd"
+
+type nsmfNiddService struct {
+	consumer *Consumer
+
+	mu sync.RWMutex
+	// URIs of Nsmf_NIDD indexed by the NF instance ID of the SMF
+	uris map[string]string
+}
+
+func (s *nsmfNiddService) getSmfNiddUri(smfID string) (string, error) {
+	s.mu.RLock()
+	uri, ok := s.uris[smfID]
+	s.mu.RUnlock()
+	if ok {
+		return uri, nil
+	}
+
+	_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+		serviceNameNsmfNidd, &Nnrf_NFDiscovery.SearchNFInstancesParamOpts{
+			TargetNfInstanceId: optional.NewInterface(smfID),
+		})
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uris[smfID] = sUri
+	return sUri, nil
+}
+
+// DeliverMtData delivers the MT data to the UE via the SMF serving the PDU session
+func (s *nsmfNiddService) DeliverMtData(
+	smfID, pduSessionRef string,
+	mtData *nef_models.SmContextDeliverData,
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getSmfNiddUri(smfID)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(serviceNameNsmfNidd, models.NfType_SMF)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nsmf-nidd/v1", http.MethodPost,
+		"/pdu-sessions/"+url.PathEscape(pduSessionRef)+"/deliver", "application/json", mtData, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
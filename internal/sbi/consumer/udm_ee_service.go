@@ -0,0 +1,143 @@
+package consumer
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi/Nudm_EventExposure"
+	"github.com/free5gc/openapi/models"
+)
+
+type nudmEeService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Nudm_EventExposure.APIClient
+}
+
+func (s *nudmEeService) getClient(uri string) *Nudm_EventExposure.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Nudm_EventExposure.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Nudm_EventExposure.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *nudmEeService) getUdmEeUri() (string, error) {
+	uri := s.consumer.Context().UdmEeUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NUDM_EE, nil)
+		if err == nil {
+			s.consumer.Context().SetUdmEeUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+func (s *nudmEeService) CreateEeSubscription(
+	ueIdentity string,
+	eeSub *models.EeSubscription,
+) (int, interface{}, string) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		eeSubID string
+		result  models.CreatedEeSubscription
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmEeUri()
+	if err != nil {
+		return rspCode, rspBody, eeSubID
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_EE, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody, eeSubID
+	}
+
+	result, rsp, err = client.CreateEESubscriptionApi.CreateEeSubscription(ctx, ueIdentity, *eeSub)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			logger.ConsumerLog.Debugf("CreateEeSubscription RspData: %+v", result)
+			rspBody = &result
+			loc := rsp.Header.Get("Location")
+			eeSubID = loc[strings.LastIndex(loc, "/")+1:]
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, eeSubID
+}
+
+func (s *nudmEeService) DeleteEeSubscription(ueIdentity, eeSubID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmEeUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_EE, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = client.DeleteEESubscriptionApi.DeleteEeSubscription(ctx, ueIdentity, eeSubID)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode != http.StatusNoContent && err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
@@ -0,0 +1,150 @@
+package consumer
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/Nudm_ParameterProvision"
+	"github.com/free5gc/openapi/models"
+)
+
+type nudmPpService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Nudm_ParameterProvision.APIClient
+}
+
+func (s *nudmPpService) getClient(uri string) *Nudm_ParameterProvision.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Nudm_ParameterProvision.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Nudm_ParameterProvision.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *nudmPpService) getUdmPpUri() (string, error) {
+	uri := s.consumer.Context().UdmPpUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NUDM_PP, nil)
+		if err == nil {
+			s.consumer.Context().SetUdmPpUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// UpdatePpData provisions the parameters of the UE identified by the GPSI
+func (s *nudmPpService) UpdatePpData(gpsi string, ppData *models.PpData) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmPpUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_PP, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = client.SubscriptionDataUpdateApi.Update(ctx, gpsi, *ppData)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode != http.StatusNoContent && err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
+
+// DeletePpData removes the communication characteristics provisioned for the UE
+// with a JSON merge patch setting it to null.
+func (s *nudmPpService) DeletePpData(gpsi string) (int, interface{}) {
+	return s.sendRawRequest(http.MethodPatch, "/"+url.PathEscape(gpsi)+"/pp-data",
+		"application/merge-patch+json", []byte(`{"communicationCharacteristics":null}`))
+}
+
+// Put5GVnGroup creates or updates the 5G VN group identified by the external group ID
+func (s *nudmPpService) Put5GVnGroup(
+	extGroupID string,
+	vnGroupCfg *nef_models.FiveGVnGroupConfiguration,
+) (int, interface{}) {
+	return s.sendRawRequest(http.MethodPut, "/5g-vn-groups/"+url.PathEscape(extGroupID),
+		"application/json", vnGroupCfg)
+}
+
+func (s *nudmPpService) Delete5GVnGroup(extGroupID string) (int, interface{}) {
+	return s.sendRawRequest(http.MethodDelete, "/5g-vn-groups/"+url.PathEscape(extGroupID), "", nil)
+}
+
+// sendRawRequest sends the request of Nudm_ParameterProvision which isn't
+// supported by the openapi client, e.g. the 5G VN group management.
+func (s *nudmPpService) sendRawRequest(
+	method, path, contentType string,
+	body interface{},
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmPpUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_PP, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nudm-pp/v1", method, path, contentType, body, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
@@ -0,0 +1,138 @@
+package consumer
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/Nudm_SubscriberDataManagement"
+	"github.com/free5gc/openapi/models"
+)
+
+type nudmSdmService struct {
+	consumer *Consumer
+
+	mu      sync.RWMutex
+	clients map[string]*Nudm_SubscriberDataManagement.APIClient
+}
+
+func (s *nudmSdmService) getClient(uri string) *Nudm_SubscriberDataManagement.APIClient {
+	s.mu.RLock()
+	if client, ok := s.clients[uri]; ok {
+		defer s.mu.RUnlock()
+		return client
+	} else {
+		configuration := Nudm_SubscriberDataManagement.NewConfiguration()
+		configuration.SetBasePath(uri)
+		cli := Nudm_SubscriberDataManagement.NewAPIClient(configuration)
+
+		s.mu.RUnlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.clients[uri] = cli
+		return cli
+	}
+}
+
+func (s *nudmSdmService) getUdmSdmUri() (string, error) {
+	uri := s.consumer.Context().UdmSdmUri()
+	if uri == "" {
+		_, sUri, err := s.consumer.SearchNFInstances(s.consumer.Config().NrfUri(),
+			models.ServiceName_NUDM_SDM, nil)
+		if err == nil {
+			s.consumer.Context().SetUdmSdmUri(sUri)
+		}
+		return sUri, err
+	}
+	return uri, nil
+}
+
+// GetIdTranslationResult translates the GPSI, e.g. msisdn-xxx or extid-xxx, to the SUPI
+func (s *nudmSdmService) GetIdTranslationResult(gpsi string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		result  models.IdTranslationResult
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmSdmUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_SDM, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	result, rsp, err = client.GPSIToSUPITranslationApi.GetIdTranslationResult(ctx, gpsi, nil)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusOK {
+			logger.ConsumerLog.Debugf("GetIdTranslationResult RspData: %+v", result)
+			rspBody = &result
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
+
+// GetGroupIdentifiers translates the external group ID to the internal group ID.
+// The openapi client doesn't support the group identifiers, so it's sent as a raw request.
+func (s *nudmSdmService) GetGroupIdentifiers(extGroupID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		result  nef_models.GroupIdentifiers
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdmSdmUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDM_SDM, models.NfType_UDM)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	query := url.Values{}
+	query.Set("ext-group-id", extGroupID)
+	rsp, err = callRawAPI(ctx, uri+"/nudm-sdm/v1", http.MethodGet,
+		"/group-data/group-identifiers?"+query.Encode(), "", nil, &result)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusOK {
+			logger.ConsumerLog.Debugf("GetGroupIdentifiers RspData: %+v", result)
+			rspBody = &result
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
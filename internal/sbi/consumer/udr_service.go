@@ -2,10 +2,13 @@ package consumer
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 
 	"github.com/antihax/optional"
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/openapi/Nudr_DataRepository"
 	"github.com/free5gc/openapi/models"
 )
@@ -48,6 +51,11 @@ func (s *nudrService) getUdrDrUri() (string, error) {
 	return uri, nil
 }
 
+// InvalidateUdrDrUri clears the cached URI of the UDR
+func (s *nudrService) InvalidateUdrDrUri() {
+	s.consumer.Context().SetUdrDrUri("")
+}
+
 func (s *nudrService) AppDataInfluenceDataGet(influenceIDs []string) (int, interface{}) {
 	var (
 		err     error
@@ -63,8 +71,10 @@ func (s *nudrService) AppDataInfluenceDataGet(influenceIDs []string) (int, inter
 	}
 	client := s.getClient(uri)
 
-	param := &Nudr_DataRepository.ApplicationDataInfluenceDataGetParamOpts{
-		InfluenceIds: optional.NewInterface(influenceIDs),
+	// Get all the influence data if no influenceIDs is given
+	param := &Nudr_DataRepository.ApplicationDataInfluenceDataGetParamOpts{}
+	if len(influenceIDs) > 0 {
+		param.InfluenceIds = optional.NewInterface(influenceIDs)
 	}
 
 	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDR_DR, models.NfType_UDR)
@@ -473,3 +483,157 @@ func (s *nudrService) AppDataInfluenceDataDelete(influenceID string) (int, inter
 
 	return rspCode, rspBody
 }
+
+// AppDataInfluenceDataSubsToNotifyPost subscribes to the changes of the influence data
+// in UDR, and returns the subscription ID allocated by the UDR
+func (s *nudrService) AppDataInfluenceDataSubsToNotifyPost(
+	influSub *models.TrafficInfluSub,
+) (int, interface{}, string) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		subsID  string
+		result  models.TrafficInfluSub
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdrDrUri()
+	if err != nil {
+		return rspCode, rspBody, subsID
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDR_DR, models.NfType_UDR)
+	if err != nil {
+		return rspCode, rspBody, subsID
+	}
+
+	result, rsp, err = client.InfluenceDataSubscriptionsCollectionApi.
+		ApplicationDataInfluenceDataSubsToNotifyPost(ctx, *influSub)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if rsp.StatusCode == http.StatusCreated {
+			rspBody = &result
+			loc := rsp.Header.Get("Location")
+			subsID = loc[strings.LastIndex(loc, "/")+1:]
+		} else if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody, subsID
+}
+
+func (s *nudrService) AppDataInfluenceDataSubsToNotifyDelete(subsID string) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdrDrUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+	client := s.getClient(uri)
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDR_DR, models.NfType_UDR)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = client.IndividualInfluenceDataSubscriptionDocumentApi.
+		ApplicationDataInfluenceDataSubsToNotifySubscriptionIdDelete(ctx, subsID)
+	if rsp != nil {
+		defer func() {
+			if rsp.Request.Response != nil {
+				rsp_err := rsp.Request.Response.Body.Close()
+				if rsp_err != nil {
+					logger.ConsumerLog.Errorf("ResponseBody can't be close: %+v", err)
+				}
+			}
+		}()
+
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
+
+// AppDataServiceParamDataPut creates or updates the service parameter data in UDR
+func (s *nudrService) AppDataServiceParamDataPut(serviceParamID string,
+	spData *nef_models.ServiceParamData,
+) (int, interface{}) {
+	return s.sendRawRequest(http.MethodPut,
+		"/application-data/serviceParamData/"+url.PathEscape(serviceParamID), "application/json", spData)
+}
+
+func (s *nudrService) AppDataServiceParamDataPatch(
+	serviceParamID string, spDataPatch *nef_models.ServiceParamDataPatch,
+) (int, interface{}) {
+	return s.sendRawRequest(http.MethodPatch,
+		"/application-data/serviceParamData/"+url.PathEscape(serviceParamID),
+		"application/merge-patch+json", spDataPatch)
+}
+
+func (s *nudrService) AppDataServiceParamDataDelete(serviceParamID string) (int, interface{}) {
+	return s.sendRawRequest(http.MethodDelete,
+		"/application-data/serviceParamData/"+url.PathEscape(serviceParamID), "", nil)
+}
+
+// sendRawRequest sends the request of Nudr_DataRepository which isn't
+// supported by the openapi client, e.g. the service parameter data.
+func (s *nudrService) sendRawRequest(
+	method, path, contentType string,
+	body interface{},
+) (int, interface{}) {
+	var (
+		err     error
+		rspCode int
+		rspBody interface{}
+		rsp     *http.Response
+	)
+
+	uri, err := s.getUdrDrUri()
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	ctx, _, err := s.consumer.Context().GetTokenCtx(models.ServiceName_NUDR_DR, models.NfType_UDR)
+	if err != nil {
+		return rspCode, rspBody
+	}
+
+	rsp, err = callRawAPI(ctx, uri+"/nudr-dr/v1", method, path, contentType, body, nil)
+	if rsp != nil {
+		rspCode = rsp.StatusCode
+		if err != nil {
+			rspCode, rspBody = handleAPIServiceResponseError(rsp, err)
+		}
+	} else {
+		// API Service Internal Error or Server No Response
+		rspCode, rspBody = handleAPIServiceNoResponse(err)
+	}
+
+	return rspCode, rspBody
+}
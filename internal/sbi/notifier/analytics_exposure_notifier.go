@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type AnalyticsExposureNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewAnalyticsExposureNotifier() (*AnalyticsExposureNotifier, error) {
+	return &AnalyticsExposureNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyAnalyticsEvent sends the analytics information to the notifUri of the
+// analytics exposure subscription (TS 29.522).
+func (n *AnalyticsExposureNotifier) NotifyAnalyticsEvent(
+	uri string,
+	anaNotif *nef_models.AnalyticsEventNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, anaNotif, nil)
+	return err
+}
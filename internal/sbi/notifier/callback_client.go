@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi"
+)
+
+// callbackTimeout bounds a callback request, as the client of openapi has no timeout
+const callbackTimeout = 10 * time.Second
+
+// callbackConfiguration implements openapi.Configuration for the requests sent to
+// the callback URIs provided by AFs and NFs, which are absolute and have no API root.
+type callbackConfiguration struct {
+	defaultHeader map[string]string
+}
+
+func newCallbackConfiguration() *callbackConfiguration {
+	return &callbackConfiguration{
+		defaultHeader: make(map[string]string),
+	}
+}
+
+func (c *callbackConfiguration) BasePath() string {
+	return ""
+}
+
+func (c *callbackConfiguration) Host() string {
+	return ""
+}
+
+func (c *callbackConfiguration) UserAgent() string {
+	return "NEF"
+}
+
+func (c *callbackConfiguration) DefaultHeader() map[string]string {
+	return c.defaultHeader
+}
+
+func (c *callbackConfiguration) HTTPClient() *http.Client {
+	return nil
+}
+
+// postCallback sends body to uri and decodes the response body into result if
+// the receiver returns one. The returned status code is 0 if no response is received.
+// The request is cancelled after callbackTimeout, or earlier if ctx has a closer deadline.
+func postCallback(
+	ctx context.Context,
+	cfg openapi.Configuration,
+	uri string,
+	body interface{},
+	result interface{},
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+
+	headerParams := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json, application/problem+json",
+	}
+
+	req, err := openapi.PrepareRequest(ctx, cfg, uri, http.MethodPost, body,
+		headerParams, url.Values{}, url.Values{}, "", "", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, err := openapi.CallAPI(cfg, req)
+	if err != nil {
+		return 0, err
+	}
+	if rsp == nil {
+		return 0, fmt.Errorf("server no response")
+	}
+	defer func() {
+		if rspCloseErr := rsp.Body.Close(); rspCloseErr != nil {
+			logger.NotifierLog.Errorf("response body cannot close: %+v", rspCloseErr)
+		}
+	}()
+
+	rspBody, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return rsp.StatusCode, err
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		if result != nil && len(rspBody) > 0 {
+			if err = openapi.Deserialize(result, rspBody, "application/json"); err != nil {
+				return rsp.StatusCode, err
+			}
+		}
+		return rsp.StatusCode, nil
+	case http.StatusNoContent:
+		return rsp.StatusCode, nil
+	default:
+		return rsp.StatusCode, fmt.Errorf("callback returns status %d: %s", rsp.StatusCode, string(rspBody))
+	}
+}
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type ChargeablePartyNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewChargeablePartyNotifier() (*ChargeablePartyNotifier, error) {
+	return &ChargeablePartyNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyUserPlaneEvent sends the user plane events to the notificationDestination
+// of the chargeable party transaction (TS 29.122 clause 5.6.3.3.2).
+func (n *ChargeablePartyNotifier) NotifyUserPlaneEvent(
+	uri string,
+	upNotif *nef_models.UserPlaneNotificationData,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, upNotif, nil)
+	return err
+}
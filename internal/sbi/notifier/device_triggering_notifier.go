@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type DeviceTriggeringNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewDeviceTriggeringNotifier() (*DeviceTriggeringNotifier, error) {
+	return &DeviceTriggeringNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyDeliveryReport sends the delivery report to the notificationDestination
+// of the device triggering transaction (TS 29.122 clause 5.7.3.3.2).
+func (n *DeviceTriggeringNotifier) NotifyDeliveryReport(
+	uri string,
+	report *nef_models.DeviceTriggeringDeliveryReportNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, report, nil)
+	return err
+}
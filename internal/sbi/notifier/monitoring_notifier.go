@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type MonitoringEventNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewMonitoringEventNotifier() (*MonitoringEventNotifier, error) {
+	return &MonitoringEventNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyMonitoringEvent sends the monitoring event reports to the notificationDestination
+// of the monitoring event subscription (TS 29.122 clause 5.3.3.3.2).
+func (n *MonitoringEventNotifier) NotifyMonitoringEvent(
+	uri string,
+	monNotif *nef_models.MonitoringNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, monNotif, nil)
+	return err
+}
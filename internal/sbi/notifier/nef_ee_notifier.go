@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type NefEeNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewNefEeNotifier() (*NefEeNotifier, error) {
+	return &NefEeNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyNefEvent sends the events to the notifUri of the Nnef_EventExposure
+// subscription (TS 29.591).
+func (n *NefEeNotifier) NotifyNefEvent(
+	uri string,
+	eeNotif *nef_models.NefEventExposureNotif,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, eeNotif, nil)
+	return err
+}
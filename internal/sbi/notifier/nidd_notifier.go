@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type NiddNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewNiddNotifier() (*NiddNotifier, error) {
+	return &NiddNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyUplinkData delivers the MO data of the UE to the notificationDestination
+// of the NIDD configuration (TS 29.122).
+func (n *NiddNotifier) NotifyUplinkData(
+	uri string,
+	notif *nef_models.NiddUplinkDataNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, notif, nil)
+	return err
+}
+
+// NotifyDownlinkDataDeliveryStatus reports the delivery status of the buffered
+// downlink data to the notificationDestination of the NIDD configuration.
+func (n *NiddNotifier) NotifyDownlinkDataDeliveryStatus(
+	uri string,
+	notif *nef_models.NiddDownlinkDataDeliveryStatusNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, notif, nil)
+	return err
+}
@@ -1,14 +1,59 @@
 package notifier
 
+import (
+	"github.com/free5gc/nef/internal/store"
+)
+
 type Notifier struct {
-	PfdChangeNotifier *PfdChangeNotifier
+	PfdChangeNotifier    *PfdChangeNotifier
+	PfdMngNotifier       *PfdMngNotifier
+	TrafficInfluNotifier *TrafficInfluNotifier
+	AsSessionQosNotifier *AsSessionQosNotifier
+	MonitoringNotifier   *MonitoringEventNotifier
+	DevTrigNotifier      *DeviceTriggeringNotifier
+	ChgPartyNotifier     *ChargeablePartyNotifier
+	NiddNotifier         *NiddNotifier
+	NefEeNotifier        *NefEeNotifier
+	AnaExpoNotifier      *AnalyticsExposureNotifier
 }
 
-func NewNotifier() (*Notifier, error) {
+func NewNotifier(s store.Store) (*Notifier, error) {
 	var err error
 	n := &Notifier{}
-	if n.PfdChangeNotifier, err = NewPfdChangeNotifier(); err != nil {
+	if n.PfdChangeNotifier, err = NewPfdChangeNotifier(s); err != nil {
+		return nil, err
+	}
+	if n.PfdMngNotifier, err = NewPfdMngNotifier(); err != nil {
+		return nil, err
+	}
+	if n.TrafficInfluNotifier, err = NewTrafficInfluNotifier(); err != nil {
+		return nil, err
+	}
+	if n.AsSessionQosNotifier, err = NewAsSessionQosNotifier(); err != nil {
+		return nil, err
+	}
+	if n.MonitoringNotifier, err = NewMonitoringEventNotifier(); err != nil {
+		return nil, err
+	}
+	if n.DevTrigNotifier, err = NewDeviceTriggeringNotifier(); err != nil {
+		return nil, err
+	}
+	if n.ChgPartyNotifier, err = NewChargeablePartyNotifier(); err != nil {
+		return nil, err
+	}
+	if n.NiddNotifier, err = NewNiddNotifier(); err != nil {
+		return nil, err
+	}
+	if n.NefEeNotifier, err = NewNefEeNotifier(); err != nil {
+		return nil, err
+	}
+	if n.AnaExpoNotifier, err = NewAnalyticsExposureNotifier(); err != nil {
 		return nil, err
 	}
 	return n, nil
 }
+
+// Stop stops the notifiers running in the background
+func (n *Notifier) Stop() {
+	n.PfdChangeNotifier.StopDelivery()
+}
@@ -0,0 +1,314 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/nef/internal/util"
+	"github.com/free5gc/openapi/models"
+)
+
+const (
+	pfdDeliveryNumWorkers = 8
+	// Pending notifications kept for each subscriber, the oldest one is dropped when it is full
+	pfdDeliveryQueueSize   = 64
+	pfdDeliveryTimeout     = 10 * time.Second
+	pfdDeliveryMaxAttempts = 5
+	pfdDeliveryInitBackoff = 1 * time.Second
+	pfdDeliveryMaxBackoff  = 30 * time.Second
+	// Consecutive undelivered notifications after which the subscription is expired
+	pfdSubMaxFailures = 3
+)
+
+// PfdSubStatus is the delivery status of the notifications of a PFD subscription
+type PfdSubStatus struct {
+	NumSuccess    uint64
+	NumFailure    uint64
+	NumConsecFail int
+	LastDelivery  time.Time
+	LastError     string
+}
+
+// PfdChangeReportHandler handles the PFDs failed to be applied by the subscriber of subID
+type PfdChangeReportHandler func(subID string, reports []models.PfdChangeReport)
+
+type pfdDelivery struct {
+	notifs  []models.PfdChangeNotification
+	attempt int
+}
+
+// pfdSubQueue keeps the notifications of a subscriber in order, of which
+// only the head is being delivered at a time
+type pfdSubQueue struct {
+	pending []*pfdDelivery
+	// The subscriber is waiting for or being served by a worker, or backing off
+	scheduled bool
+}
+
+type pfdDeliveryOutcome int
+
+const (
+	pfdDelivered pfdDeliveryOutcome = iota
+	pfdRetry
+	pfdFailed
+	pfdSubGone
+)
+
+func (n *PfdChangeNotifier) startDeliveryWorkers() {
+	n.workerWg.Add(pfdDeliveryNumWorkers)
+	for i := 0; i < pfdDeliveryNumWorkers; i++ {
+		go n.runDeliveryWorker()
+	}
+}
+
+// StopDelivery stops the delivery workers once the notifications being delivered are
+// cancelled. The pending notifications are dropped, and nothing is queued afterwards.
+func (n *PfdChangeNotifier) StopDelivery() {
+	n.stopDelivery()
+	n.workerWg.Wait()
+
+	n.queueMu.Lock()
+	numPending := 0
+	for subID, q := range n.subQueues {
+		numPending += len(q.pending)
+		delete(n.subQueues, subID)
+	}
+	n.queueMu.Unlock()
+	if numPending > 0 {
+		logger.PFDManageLog.Warnf("%d pending PFD notifications are dropped", numPending)
+	}
+	logger.PFDManageLog.Infoln("PFD notification delivery is stopped")
+}
+
+func (n *PfdChangeNotifier) runDeliveryWorker() {
+	defer func() {
+		if p := recover(); p != nil {
+			// Print stack for panic to log. Fatalf() will let program exit.
+			logger.PFDManageLog.Fatalf("panic: %v\n%s", p, string(debug.Stack()))
+		}
+		n.workerWg.Done()
+	}()
+
+	for {
+		select {
+		case <-n.deliveryCtx.Done():
+			return
+		case subID := <-n.readyCh:
+			n.serveSub(subID)
+		}
+	}
+}
+
+// enqueue appends the notifications to the queue of the subscriber.
+// The queue is only created for an existing subscription, so it's not revived
+// by the notifications flushed after the subscription is deleted.
+func (n *PfdChangeNotifier) enqueue(subID string, notifs []models.PfdChangeNotification) {
+	if n.deliveryCtx.Err() != nil {
+		logger.PFDManageLog.Warnf("PFD notification delivery is stopped, drop the notification to subscription[%s]",
+			subID)
+		return
+	}
+
+	n.mu.RLock()
+	if _, ok := n.subIdToURI[subID]; !ok {
+		n.mu.RUnlock()
+		return
+	}
+	n.queueMu.Lock()
+	q, ok := n.subQueues[subID]
+	if !ok {
+		q = &pfdSubQueue{}
+		n.subQueues[subID] = q
+	}
+	if len(q.pending) >= pfdDeliveryQueueSize {
+		// The head may be in flight, so the next oldest one is dropped
+		logger.PFDManageLog.Warnf("Notification queue of PFD subscription[%s] is full, drop the oldest one", subID)
+		q.pending = append(q.pending[:1], q.pending[2:]...)
+	}
+	q.pending = append(q.pending, &pfdDelivery{notifs: notifs})
+	schedule := !q.scheduled
+	q.scheduled = true
+	n.queueMu.Unlock()
+	n.mu.RUnlock()
+
+	if schedule {
+		n.schedule(subID)
+	}
+}
+
+// schedule hands the subscriber to a worker without blocking the caller
+func (n *PfdChangeNotifier) schedule(subID string) {
+	select {
+	case n.readyCh <- subID:
+	case <-n.deliveryCtx.Done():
+	default:
+		go func() {
+			select {
+			case n.readyCh <- subID:
+			case <-n.deliveryCtx.Done():
+			}
+		}()
+	}
+}
+
+// serveSub delivers the pending notifications of the subscriber until the queue
+// is drained or a delivery has to be retried later
+func (n *PfdChangeNotifier) serveSub(subID string) {
+	for {
+		if n.deliveryCtx.Err() != nil {
+			return
+		}
+		n.queueMu.Lock()
+		q, ok := n.subQueues[subID]
+		if !ok {
+			n.queueMu.Unlock()
+			return
+		}
+		if len(q.pending) == 0 {
+			q.scheduled = false
+			n.queueMu.Unlock()
+			return
+		}
+		d := q.pending[0]
+		n.queueMu.Unlock()
+
+		d.attempt++
+		outcome, err := n.deliver(subID, d.notifs)
+		if n.deliveryCtx.Err() != nil {
+			// The delivery is cancelled by StopDelivery
+			return
+		}
+		switch outcome {
+		case pfdSubGone:
+			// The subscriber no longer knows the subscription
+			logger.PFDManageLog.Warnf("PFD subscription[%s] is not found by the subscriber, remove it", subID)
+			n.removeExpiredPfdSub(subID)
+			return
+		case pfdRetry:
+			if d.attempt < pfdDeliveryMaxAttempts {
+				backoff := pfdDeliveryBackoff(d.attempt)
+				logger.PFDManageLog.Infof("PFD notification to subscription[%s] failed(%d/%d), retry in %s: %+v",
+					subID, d.attempt, pfdDeliveryMaxAttempts, backoff, err)
+				time.AfterFunc(backoff, func() {
+					n.schedule(subID)
+				})
+				return
+			}
+		}
+
+		n.queueMu.Lock()
+		if q, ok = n.subQueues[subID]; ok && len(q.pending) > 0 && q.pending[0] == d {
+			q.pending = q.pending[1:]
+		}
+		n.queueMu.Unlock()
+
+		if n.recordDelivery(subID, err) {
+			logger.PFDManageLog.Warnf("PFD subscription[%s] is expired after %d undelivered notifications",
+				subID, pfdSubMaxFailures)
+			n.removeExpiredPfdSub(subID)
+			return
+		}
+	}
+}
+
+// deliver sends the notifications to the subscriber and classifies the result
+func (n *PfdChangeNotifier) deliver(
+	subID string,
+	notifs []models.PfdChangeNotification,
+) (pfdDeliveryOutcome, error) {
+	uri := n.getSubURI(subID)
+	if uri == "" {
+		return pfdSubGone, nil
+	}
+
+	ctx, cancel := context.WithTimeout(n.deliveryCtx, pfdDeliveryTimeout)
+	defer cancel()
+
+	// The Nnef_PFDManagement_Notify request is sent to {notifyUri}/notify
+	var reports []models.PfdChangeReport
+	status, err := postCallback(ctx, n.cfg, uri+"/notify", notifs, &reports)
+	if err == nil {
+		if len(reports) > 0 {
+			n.handlePfdChangeReports(subID, reports)
+		}
+		return pfdDelivered, nil
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		return pfdSubGone, err
+	case status == 0, status == http.StatusTooManyRequests, status >= http.StatusInternalServerError:
+		return pfdRetry, err
+	default:
+		// The other errors, e.g. 400 Bad Request, are not recovered by retrying
+		return pfdFailed, err
+	}
+}
+
+// handlePfdChangeReports processes the PFDs that the subscriber fails to apply,
+// which are only reported if the partial failure feature is negotiated
+func (n *PfdChangeNotifier) handlePfdChangeReports(subID string, reports []models.PfdChangeReport) {
+	n.mu.RLock()
+	suppFeat := n.subIdToSuppFeat[subID]
+	handler := n.reportHandler
+	n.mu.RUnlock()
+
+	if !util.IsSuppFeatEnabled(suppFeat, util.PfdMngFeaturePartialFailure) {
+		logger.PFDManageLog.Debugf("Ignore PfdChangeReport of PFD subscription[%s] without the feature", subID)
+		return
+	}
+	for _, report := range reports {
+		cause := ""
+		if report.PfdError != nil {
+			cause = report.PfdError.Cause
+		}
+		logger.PFDManageLog.Warnf("PFDs of %v failed to be applied by PFD subscription[%s]: %s",
+			report.ApplicationId, subID, cause)
+	}
+	if handler != nil {
+		// The handler may notify the AFs, which should not hold up the delivery to the subscribers
+		go handler(subID, reports)
+	}
+}
+
+// recordDelivery updates the delivery status of the subscription,
+// and returns true if the subscription should be expired
+func (n *PfdChangeNotifier) recordDelivery(subID string, err error) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	status, ok := n.subIdToStatus[subID]
+	if !ok {
+		return false
+	}
+	status.LastDelivery = time.Now()
+	if err == nil {
+		status.NumSuccess++
+		status.NumConsecFail = 0
+		status.LastError = ""
+		return false
+	}
+	status.NumFailure++
+	status.NumConsecFail++
+	status.LastError = err.Error()
+	logger.PFDManageLog.Warnf("PFD notification to subscription[%s] failed(%d/%d): %+v",
+		subID, status.NumConsecFail, pfdSubMaxFailures, err)
+	return status.NumConsecFail >= pfdSubMaxFailures
+}
+
+func (n *PfdChangeNotifier) removeExpiredPfdSub(subID string) {
+	if err := n.DeletePfdSub(subID); err != nil {
+		logger.PFDManageLog.Debugf("PFD subscription[%s] is already removed", subID)
+	}
+}
+
+func pfdDeliveryBackoff(attempt int) time.Duration {
+	backoff := pfdDeliveryInitBackoff << (attempt - 1)
+	if backoff > pfdDeliveryMaxBackoff || backoff <= 0 {
+		return pfdDeliveryMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/free5gc/openapi/models"
+)
+
+type PfdMngNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewPfdMngNotifier() (*PfdMngNotifier, error) {
+	return &PfdMngNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyPfdReports sends the PFDs failed to be applied in the network to the
+// notificationDestination of the PFD management transaction (TS 29.122).
+func (n *PfdMngNotifier) NotifyPfdReports(uri string, pfdReports []models.PfdReport) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, pfdReports, nil)
+	return err
+}
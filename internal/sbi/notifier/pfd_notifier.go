@@ -2,23 +2,45 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"runtime/debug"
+	"fmt"
 	"strconv"
 	"sync"
 
 	"github.com/free5gc/nef/internal/logger"
-	"github.com/free5gc/openapi/Nnef_PFDmanagement"
+	"github.com/free5gc/nef/internal/store"
 	"github.com/free5gc/openapi/models"
 )
 
-type PfdChangeNotifier struct {
-	clientPfdManagement *Nnef_PFDmanagement.APIClient
-	mu                  sync.RWMutex
+const (
+	bucketPfdNotifier string = "pfdNotifier"
+	bucketPfdSub      string = "pfdSub"
+
+	keyNumPfdSubID string = "numPfdSubID"
+)
 
-	numPfdSubID   uint64
-	appIdToSubIDs map[string]map[string]bool
-	subIdToURI    map[string]string
+type PfdChangeNotifier struct {
+	cfg   *callbackConfiguration
+	store store.Store
+	mu    sync.RWMutex
+
+	numPfdSubID     uint64
+	appIdToSubIDs   map[string]map[string]bool
+	subIdToURI      map[string]string
+	subIdToSuppFeat map[string]string
+	subIdToStatus   map[string]*PfdSubStatus
+	// The subscriptions without applicationIds, which monitor all applications
+	wildcardSubIDs map[string]bool
+	reportHandler  PfdChangeReportHandler
+
+	// The notifications are delivered by a pool of workers, which take the subscribers from readyCh
+	queueMu      sync.Mutex
+	subQueues    map[string]*pfdSubQueue
+	readyCh      chan string
+	deliveryCtx  context.Context
+	stopDelivery context.CancelFunc
+	workerWg     sync.WaitGroup
 }
 
 type PfdNotifyContext struct {
@@ -27,56 +49,119 @@ type PfdNotifyContext struct {
 	subIdToChangedAppIDs map[string][]string
 }
 
-func NewPfdChangeNotifier() (*PfdChangeNotifier, error) {
-	return &PfdChangeNotifier{
-		appIdToSubIDs: make(map[string]map[string]bool),
-		subIdToURI:    make(map[string]string),
-	}, nil
+func NewPfdChangeNotifier(s store.Store) (*PfdChangeNotifier, error) {
+	n := &PfdChangeNotifier{
+		cfg:             newCallbackConfiguration(),
+		store:           s,
+		appIdToSubIDs:   make(map[string]map[string]bool),
+		subIdToURI:      make(map[string]string),
+		subIdToSuppFeat: make(map[string]string),
+		subIdToStatus:   make(map[string]*PfdSubStatus),
+		wildcardSubIDs:  make(map[string]bool),
+		subQueues:       make(map[string]*pfdSubQueue),
+		readyCh:         make(chan string, pfdDeliveryNumWorkers),
+	}
+	n.deliveryCtx, n.stopDelivery = context.WithCancel(context.Background())
+	if err := n.restore(); err != nil {
+		return nil, fmt.Errorf("restore PFD subscriptions error: %w", err)
+	}
+	n.startDeliveryWorkers()
+	return n, nil
 }
 
-func (n *PfdChangeNotifier) initPfdManagementApiClient() {
+// restore loads the PFD subscriptions kept in the store
+func (n *PfdChangeNotifier) restore() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
-	if n.clientPfdManagement != nil {
-		return
+	err := n.store.ForEach(bucketPfdNotifier, func(key string, value []byte) error {
+		if key == keyNumPfdSubID {
+			return json.Unmarshal(value, &n.numPfdSubID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	config := Nnef_PFDmanagement.NewConfiguration()
-	n.clientPfdManagement = Nnef_PFDmanagement.NewAPIClient(config)
+	err = n.store.ForEach(bucketPfdSub, func(key string, value []byte) error {
+		var pfdSub models.PfdSubscription
+		if err = json.Unmarshal(value, &pfdSub); err != nil {
+			return fmt.Errorf("PFD subscription[%s]: %w", key, err)
+		}
+		n.addPfdSub(key, &pfdSub)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.PFDFLog.Infof("%d PFD subscriptions are restored", len(n.subIdToURI))
+	return nil
 }
 
-func (n *PfdChangeNotifier) AddPfdSub(pfdSub *models.PfdSubscription) string {
-	n.initPfdManagementApiClient()
+// SetPfdChangeReportHandler sets the handler of the PfdChangeReports returned by the subscribers
+func (n *PfdChangeNotifier) SetPfdChangeReportHandler(handler PfdChangeReportHandler) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reportHandler = handler
+}
 
+func (n *PfdChangeNotifier) AddPfdSub(pfdSub *models.PfdSubscription) string {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
 	n.numPfdSubID++
 	subID := strconv.FormatUint(n.numPfdSubID, 10)
+	n.addPfdSub(subID, pfdSub)
+
+	if err := n.store.Put(bucketPfdNotifier, keyNumPfdSubID, n.numPfdSubID); err != nil {
+		logger.PFDFLog.Errorf("Store numPfdSubID error: %+v", err)
+	}
+	if err := n.store.Put(bucketPfdSub, subID, pfdSub); err != nil {
+		logger.PFDFLog.Errorf("Store PFD subscription[%s] error: %+v", subID, err)
+	}
+	return subID
+}
+
+func (n *PfdChangeNotifier) addPfdSub(subID string, pfdSub *models.PfdSubscription) {
 	n.subIdToURI[subID] = pfdSub.NotifyUri
-	// TODO: If pfdSub.ApplicationIds is empty, it may means monitoring all appIDs
+	n.subIdToSuppFeat[subID] = pfdSub.SupportedFeatures
+	n.subIdToStatus[subID] = &PfdSubStatus{}
+	if len(pfdSub.ApplicationIds) == 0 {
+		n.wildcardSubIDs[subID] = true
+		return
+	}
 	for _, appID := range pfdSub.ApplicationIds {
 		if _, exist := n.appIdToSubIDs[appID]; !exist {
 			n.appIdToSubIDs[appID] = make(map[string]bool)
 		}
 		n.appIdToSubIDs[appID][subID] = true
 	}
-
-	return subID
 }
 
 func (n *PfdChangeNotifier) DeletePfdSub(subID string) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	// The pending notifications are dropped, even if the subscription is already removed
+	n.queueMu.Lock()
+	delete(n.subQueues, subID)
+	n.queueMu.Unlock()
+
 	if _, exist := n.subIdToURI[subID]; !exist {
 		return errors.New("subscription not found")
 	}
 	delete(n.subIdToURI, subID)
+	delete(n.subIdToSuppFeat, subID)
+	delete(n.subIdToStatus, subID)
+	delete(n.wildcardSubIDs, subID)
 	for _, subIDs := range n.appIdToSubIDs {
 		delete(subIDs, subID)
 	}
+
+	if err := n.store.Delete(bucketPfdSub, subID); err != nil {
+		logger.PFDFLog.Errorf("Delete PFD subscription[%s] from store error: %+v", subID, err)
+	}
 	return nil
 }
 
@@ -84,10 +169,13 @@ func (n *PfdChangeNotifier) getSubIDs(appID string) []string {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
-	subIDs := make([]string, 0, len(n.appIdToSubIDs[appID]))
+	subIDs := make([]string, 0, len(n.appIdToSubIDs[appID])+len(n.wildcardSubIDs))
 	for subID := range n.appIdToSubIDs[appID] {
 		subIDs = append(subIDs, subID)
 	}
+	for subID := range n.wildcardSubIDs {
+		subIDs = append(subIDs, subID)
+	}
 	return subIDs
 }
 
@@ -97,6 +185,18 @@ func (n *PfdChangeNotifier) getSubURI(subID string) string {
 	return n.subIdToURI[subID]
 }
 
+// PfdSubStatus returns a copy of the delivery status of the PFD subscription
+func (n *PfdChangeNotifier) PfdSubStatus(subID string) (PfdSubStatus, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	status, ok := n.subIdToStatus[subID]
+	if !ok {
+		return PfdSubStatus{}, false
+	}
+	return *status, true
+}
+
 func (n *PfdChangeNotifier) NewPfdNotifyContext() *PfdNotifyContext {
 	return &PfdNotifyContext{
 		notifier:             n,
@@ -112,27 +212,14 @@ func (nc *PfdNotifyContext) AddNotification(appID string, notif *models.PfdChang
 	}
 }
 
+// FlushNotifications queues the notifications of the changed applications for each subscriber,
+// which are delivered in the background
 func (nc *PfdNotifyContext) FlushNotifications() {
 	for subID, appIDs := range nc.subIdToChangedAppIDs {
 		pfdChangeNotifications := make([]models.PfdChangeNotification, 0, len(appIDs))
 		for _, appID := range appIDs {
 			pfdChangeNotifications = append(pfdChangeNotifications, nc.appIdToNotification[appID])
 		}
-
-		go func(id string) {
-			defer func() {
-				if p := recover(); p != nil {
-					// Print stack for panic to log. Fatalf() will let program exit.
-					logger.PFDManageLog.Fatalf("panic: %v\n%s", p, string(debug.Stack()))
-				}
-			}()
-
-			_, _, err := nc.notifier.clientPfdManagement.NotificationApi.NotificationPost(
-				context.TODO(), nc.notifier.getSubURI(id), pfdChangeNotifications)
-			if err != nil {
-				logger.PFDManageLog.Fatal(err)
-			}
-		}(subID)
-		// TODO: Handle the response of notification properly
+		nc.notifier.enqueue(subID, pfdChangeNotifications)
 	}
 }
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+)
+
+type AsSessionQosNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewAsSessionQosNotifier() (*AsSessionQosNotifier, error) {
+	return &AsSessionQosNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyUserPlaneEvent sends the user plane events to the notificationDestination
+// of the AS session with QoS subscription (TS 29.122 clause 5.14.3.3.2).
+func (n *AsSessionQosNotifier) NotifyUserPlaneEvent(
+	uri string,
+	upNotif *nef_models.UserPlaneNotificationData,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, upNotif, nil)
+	return err
+}
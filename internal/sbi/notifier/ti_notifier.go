@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models_nef"
+)
+
+type TrafficInfluNotifier struct {
+	cfg *callbackConfiguration
+}
+
+func NewTrafficInfluNotifier() (*TrafficInfluNotifier, error) {
+	return &TrafficInfluNotifier{
+		cfg: newCallbackConfiguration(),
+	}, nil
+}
+
+// NotifyUpPathChg sends the UP path change event to the notificationDestination of
+// the traffic influence subscription (TS 29.522 clause 5.4.3.3.3).
+// If the AF acknowledges the notification synchronously, the AfAckInfo is returned.
+func (n *TrafficInfluNotifier) NotifyUpPathChg(
+	ctx context.Context,
+	uri string,
+	eventNotif *models_nef.EventNotification,
+) (*models_nef.AfAckInfo, error) {
+	var afAckInfo models_nef.AfAckInfo
+	rspCode, err := postCallback(ctx, n.cfg, uri, eventNotif, &afAckInfo)
+	if err != nil {
+		return nil, err
+	}
+	if rspCode != http.StatusOK || afAckInfo.AckResult == nil {
+		return nil, nil
+	}
+	return &afAckInfo, nil
+}
+
+// SendAckOfNotify relays the AF acknowledgements to the ackUri provided by the SMF
+// (TS 29.508 clause 5.2.2.5).
+func (n *TrafficInfluNotifier) SendAckOfNotify(
+	ackUri string,
+	acks []nef_models.AckOfNotify,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, ackUri, acks, nil)
+	return err
+}
+
+// NotifyTrafficInfluSubChange informs the AF that the traffic influence data of its
+// subscription is modified or removed in the UDR by another NF or the operator.
+func (n *TrafficInfluNotifier) NotifyTrafficInfluSubChange(
+	uri string,
+	notif *nef_models.TrafficInfluSubNotification,
+) error {
+	_, err := postCallback(context.TODO(), n.cfg, uri, notif, nil)
+	return err
+}
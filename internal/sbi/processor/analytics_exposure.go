@@ -0,0 +1,432 @@
+package processor
+
+import (
+	"net/http"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+var analyticsEventToNwdafEvent = map[nef_models.AnalyticsEvent]nef_models.NwdafEvent{
+	nef_models.AnalyticsEvent_UE_MOBILITY:         nef_models.NwdafEvent_UE_MOBILITY,
+	nef_models.AnalyticsEvent_UE_COMM:             nef_models.NwdafEvent_UE_COMM,
+	nef_models.AnalyticsEvent_ABNORMAL_BEHAVIOR:   nef_models.NwdafEvent_ABNORMAL_BEHAVIOUR,
+	nef_models.AnalyticsEvent_CONGESTION:          nef_models.NwdafEvent_USER_DATA_CONGESTION,
+	nef_models.AnalyticsEvent_NETWORK_PERFORMANCE: nef_models.NwdafEvent_NETWORK_PERFORMANCE,
+	nef_models.AnalyticsEvent_QOS_SUSTAINABILITY:  nef_models.NwdafEvent_QOS_SUSTAINABILITY,
+	nef_models.AnalyticsEvent_SERVICE_EXPERIENCE:  nef_models.NwdafEvent_SERVICE_EXPERIENCE,
+}
+
+func (p *Processor) GetAnalyticsExposureSubscriptions(
+	c *gin.Context,
+	afID string,
+) {
+	logger.AnaExpoLog.Infof("GetAnalyticsExposureSubscriptions - afID[%s]", afID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	anaSubs := []nef_models.AnalyticsExposureSubsc{}
+	for _, sub := range af.AnaSubs {
+		anaSubs = append(anaSubs, *sub.AnaSub)
+	}
+	c.JSON(http.StatusOK, &anaSubs)
+}
+
+func (p *Processor) PostAnalyticsExposureSubscription(
+	c *gin.Context,
+	afID string,
+	anaSub *nef_models.AnalyticsExposureSubsc,
+) {
+	logger.AnaExpoLog.Infof("PostAnalyticsExposureSubscription - afID[%s]", afID)
+
+	if rsp := validateAnalyticsExposureSubsc(anaSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	// The analytics are only returned in the response
+	anaSub.EventNotifs = nil
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(afID)
+	if af == nil {
+		af = nefCtx.NewAf(afID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub := af.NewAnaSub(nefCtx.NewCorreID(), anaSub)
+	eventsSub := p.convertAnalyticsExposureSubscToNnwdafEventsSubscription(anaSub, afSub.NotifCorreID)
+	rspStatus, rspBody, nwdafSubID := p.Consumer().CreateNwdafEventsSubscription(eventsSub)
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	afSub.NwdafSubID = nwdafSubID
+	anaSub.Self = p.genAnalyticsExposureSubURI(afID, afSub.SubID)
+
+	af.AnaSubs[afSub.SubID] = afSub
+	af.Log.Infoln("Analytics exposure subscription is added")
+
+	nefCtx.AddAf(af)
+
+	rspAnaSub := *anaSub
+	if createdSub, ok := rspBody.(*nef_models.NnwdafEventsSubscription); ok {
+		rspAnaSub.EventNotifs = convertNwdafEventNotificationsToAnalyticsEventNotifs(createdSub.EventNotifications)
+	}
+
+	c.Header("Location", anaSub.Self)
+	c.JSON(http.StatusCreated, &rspAnaSub)
+}
+
+func (p *Processor) GetIndividualAnalyticsExposureSubscription(
+	c *gin.Context,
+	afID, subID string,
+) {
+	logger.AnaExpoLog.Infof("GetIndividualAnalyticsExposureSubscription - afID[%s], subID[%s]",
+		afID, subID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	afSub, ok := af.AnaSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, afSub.AnaSub)
+}
+
+func (p *Processor) PutIndividualAnalyticsExposureSubscription(
+	c *gin.Context,
+	afID, subID string,
+	anaSub *nef_models.AnalyticsExposureSubsc,
+) {
+	logger.AnaExpoLog.Infof("PutIndividualAnalyticsExposureSubscription - afID[%s], subID[%s]",
+		afID, subID)
+
+	if rsp := validateAnalyticsExposureSubsc(anaSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	anaSub.EventNotifs = nil
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.AnaSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	eventsSub := p.convertAnalyticsExposureSubscToNnwdafEventsSubscription(anaSub, afSub.NotifCorreID)
+	rspStatus, rspBody := p.Consumer().UpdateNwdafEventsSubscription(afSub.NwdafSubID, eventsSub)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	anaSub.Self = afSub.AnaSub.Self
+	afSub.AnaSub = anaSub
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, afSub.AnaSub)
+}
+
+func (p *Processor) DeleteIndividualAnalyticsExposureSubscription(
+	c *gin.Context,
+	afID, subID string,
+) {
+	logger.AnaExpoLog.Infof("DeleteIndividualAnalyticsExposureSubscription - afID[%s], subID[%s]",
+		afID, subID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.AnaSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().DeleteNwdafEventsSubscription(afSub.NwdafSubID)
+	if rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.AnaSubs, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FetchAnalyticsExposure fetches the analytics information from the NWDAF for the
+// AF, without a subscription
+func (p *Processor) FetchAnalyticsExposure(
+	c *gin.Context,
+	afID string,
+	anaReq *nef_models.AnalyticsRequest,
+) {
+	logger.AnaExpoLog.Infof("FetchAnalyticsExposure - afID[%s], analyEvent[%s]", afID, anaReq.AnalyEvent)
+
+	nwdafEvent, ok := analyticsEventToNwdafEvent[anaReq.AnalyEvent]
+	if !ok {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Unsupported analyEvent: " + string(anaReq.AnalyEvent))
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	if rsp := validateTargetUeId(anaReq.TgtUe); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	var eventFilter *nef_models.NwdafEventFilter
+	if anaReq.AnalyEventFilter != nil {
+		eventFilter = convertAnalyticsEventFilterToNwdafEventFilter(anaReq.AnalyEventFilter)
+	}
+
+	rspStatus, rspBody := p.Consumer().GetNwdafAnalytics(nwdafEvent, eventFilter,
+		convertTargetUeIdToNwdafTargetUeInformation(anaReq.TgtUe), anaReq.SuppFeat)
+	switch rspStatus {
+	case http.StatusOK:
+		anaData := rspBody.(*nef_models.NwdafAnalyticsData)
+		c.JSON(http.StatusOK, &nef_models.AnalyticsData{
+			Start:          anaData.Start,
+			Expiry:         anaData.Expiry,
+			TimeStamp:      anaData.TimeStampGen,
+			AnalyticsInfos: convertNwdafAnalyticsInfos(&anaData.NwdafAnalyticsInfos),
+			SuppFeat:       anaData.SuppFeat,
+		})
+	case http.StatusNoContent:
+		c.JSON(http.StatusNoContent, nil)
+	default:
+		c.JSON(rspStatus, rspBody)
+	}
+}
+
+// NwdafEventsNotification relays the analytics notified by the NWDAF (TS 29.520)
+// to the AF of the analytics exposure subscription.
+func (p *Processor) NwdafEventsNotification(
+	c *gin.Context,
+	correID string,
+	nwdafNotifs []nef_models.NnwdafEventsSubscriptionNotification,
+) {
+	logger.AnaExpoLog.Infof("NwdafEventsNotification - correID[%s]", correID)
+
+	af, sub := p.Context().FindAfAnaSub(correID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	notifUri := sub.AnaSub.NotifUri
+	anaNotif := &nef_models.AnalyticsEventNotification{
+		NotifId: sub.AnaSub.NotifId,
+	}
+	af.Mu.RUnlock()
+
+	for i := range nwdafNotifs {
+		anaNotif.AnalyEventNotifs = append(anaNotif.AnalyEventNotifs,
+			convertNwdafEventNotificationsToAnalyticsEventNotifs(nwdafNotifs[i].EventNotifications)...)
+	}
+	if len(anaNotif.AnalyEventNotifs) > 0 {
+		go p.notifyAnalyticsEvents(sub, notifUri, anaNotif)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (p *Processor) notifyAnalyticsEvents(
+	sub *nef_context.AfAnalyticsSubscription,
+	notifUri string,
+	anaNotif *nef_models.AnalyticsEventNotification,
+) {
+	if err := p.Notifier().AnaExpoNotifier.NotifyAnalyticsEvent(notifUri, anaNotif); err != nil {
+		sub.Log.Errorf("Notify analytics events failed: %+v", err)
+		return
+	}
+	sub.Log.Infof("Analytics events are notified")
+}
+
+func validateAnalyticsExposureSubsc(anaSub *nef_models.AnalyticsExposureSubsc) *HandlerResponse {
+	if anaSub.NotifUri == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notifUri")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if anaSub.NotifId == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notifId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if len(anaSub.AnalyEventsSubs) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of analyEventsSubs")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	for _, eventSubsc := range anaSub.AnalyEventsSubs {
+		if _, ok := analyticsEventToNwdafEvent[eventSubsc.AnalyEvent]; !ok {
+			pd := openapi.ProblemDetailsMalformedReqSyntax(
+				"Unsupported analyEvent: " + string(eventSubsc.AnalyEvent))
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+		if rsp := validateTargetUeId(eventSubsc.TgtUe); rsp != nil {
+			return rsp
+		}
+	}
+	return nil
+}
+
+func validateTargetUeId(tgtUe *nef_models.TargetUeId) *HandlerResponse {
+	if tgtUe == nil {
+		return nil
+	}
+	if tgtUe.ExterGroupId != "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("exterGroupId is not supported")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if tgtUe.AnyUeInd && tgtUe.Gpsi != "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("One of anyUeInd or gpsi shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genAnalyticsExposureSubURI(afID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-analyticsexposure/v1/{afId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceAnaExpo) + "/" + afID + "/subscriptions/" + subID
+}
+
+// genNwdafNotificationUri returns the notificationURI of the NWDAF events subscription
+func (p *Processor) genNwdafNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/nwdaf-es/" + notifCorreID
+}
+
+func (p *Processor) convertAnalyticsExposureSubscToNnwdafEventsSubscription(
+	anaSub *nef_models.AnalyticsExposureSubsc,
+	notifCorreID string,
+) *nef_models.NnwdafEventsSubscription {
+	eventsSub := &nef_models.NnwdafEventsSubscription{
+		EvtReq:            anaSub.AnalyRepInfo,
+		NotificationURI:   p.genNwdafNotificationUri(notifCorreID),
+		NotifCorrId:       notifCorreID,
+		SupportedFeatures: anaSub.SuppFeat,
+	}
+	for _, eventSubsc := range anaSub.AnalyEventsSubs {
+		eventSub := nef_models.NwdafEventSubscription{
+			Event: analyticsEventToNwdafEvent[eventSubsc.AnalyEvent],
+			TgtUe: convertTargetUeIdToNwdafTargetUeInformation(eventSubsc.TgtUe),
+		}
+		if eventSubsc.AnalyEventFilter != nil {
+			eventSub.NwdafEventFilter = *convertAnalyticsEventFilterToNwdafEventFilter(eventSubsc.AnalyEventFilter)
+		}
+		eventsSub.EventSubscriptions = append(eventsSub.EventSubscriptions, eventSub)
+	}
+	return eventsSub
+}
+
+func convertAnalyticsEventFilterToNwdafEventFilter(
+	filter *nef_models.AnalyticsEventFilter,
+) *nef_models.NwdafEventFilter {
+	eventFilter := &nef_models.NwdafEventFilter{
+		AppIds: filter.AppIds,
+	}
+	if filter.Dnn != "" {
+		eventFilter.Dnns = []string{filter.Dnn}
+	}
+	if filter.Snssai != nil {
+		eventFilter.Snssais = []models.Snssai{*filter.Snssai}
+	}
+	return eventFilter
+}
+
+// convertTargetUeIdToNwdafTargetUeInformation returns the target UE of the NWDAF,
+// which is any UE if the AF doesn't identify the UE
+func convertTargetUeIdToNwdafTargetUeInformation(
+	tgtUe *nef_models.TargetUeId,
+) *nef_models.NwdafTargetUeInformation {
+	if tgtUe == nil || tgtUe.Gpsi == "" {
+		return &nef_models.NwdafTargetUeInformation{AnyUe: true}
+	}
+	return &nef_models.NwdafTargetUeInformation{
+		Gpsis: []string{tgtUe.Gpsi},
+	}
+}
+
+func convertNwdafEventNotificationsToAnalyticsEventNotifs(
+	nwdafNotifs []nef_models.NwdafEventNotification,
+) []nef_models.AnalyticsEventNotif {
+	var anaNotifs []nef_models.AnalyticsEventNotif
+	for i := range nwdafNotifs {
+		anaEvent := nwdafEventToAnalyticsEvent(nwdafNotifs[i].Event)
+		if anaEvent == "" {
+			logger.AnaExpoLog.Debugf("Ignore NWDAF event[%s]", nwdafNotifs[i].Event)
+			continue
+		}
+		anaNotifs = append(anaNotifs, nef_models.AnalyticsEventNotif{
+			AnalyEvent:     anaEvent,
+			Expiry:         nwdafNotifs[i].Expiry,
+			TimeStamp:      nwdafNotifs[i].TimeStampGen,
+			AnalyticsInfos: convertNwdafAnalyticsInfos(&nwdafNotifs[i].NwdafAnalyticsInfos),
+		})
+	}
+	return anaNotifs
+}
+
+func nwdafEventToAnalyticsEvent(nwdafEvent nef_models.NwdafEvent) nef_models.AnalyticsEvent {
+	for anaEvent, event := range analyticsEventToNwdafEvent {
+		if event == nwdafEvent {
+			return anaEvent
+		}
+	}
+	return ""
+}
+
+func convertNwdafAnalyticsInfos(nwdafInfos *nef_models.NwdafAnalyticsInfos) nef_models.AnalyticsInfos {
+	return nef_models.AnalyticsInfos{
+		UeMobilityInfos: nwdafInfos.UeMobs,
+		UeCommInfos:     nwdafInfos.UeComms,
+		AbnormalInfos:   nwdafInfos.AbnorBehavrs,
+		CongestInfos:    nwdafInfos.UserDataCongInfos,
+		NwPerfInfos:     nwdafInfos.NwPerfs,
+		QosSustainInfos: nwdafInfos.QosSustainInfos,
+		SvcExpInfos:     nwdafInfos.SvcExps,
+	}
+}
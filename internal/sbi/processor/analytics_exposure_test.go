@@ -0,0 +1,306 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var anaSub1ForAf1 = nef_models.AnalyticsExposureSubsc{
+	AnalyEventsSubs: []nef_models.AnalyticsEventSubsc{
+		{
+			AnalyEvent: nef_models.AnalyticsEvent_UE_MOBILITY,
+			TgtUe: &nef_models.TargetUeId{
+				Gpsi: "msisdn-0900000000",
+			},
+		},
+	},
+	NotifUri: "http://127.0.0.100:8000/analytics/notify",
+	NotifId:  "notif1",
+}
+
+func TestPostAnalyticsExposureSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_NWDAF, models.ServiceName_NNWDAF_EVENTSSUBSCRIPTION, "127.0.0.6")
+	ueMobs := json.RawMessage(`[{"ts":"2024-01-01T00:00:00Z","duration":60}]`)
+	nwdafStub := initNWDAFEsCreateStub([]nef_models.NwdafEventNotification{
+		{
+			Event:               nef_models.NwdafEvent_UE_MOBILITY,
+			NwdafAnalyticsInfos: nef_models.NwdafAnalyticsInfos{UeMobs: ueMobs},
+		},
+	})
+	defer gock.Remove(nwdafStub)
+
+	rspAnaSub1 := anaSub1ForAf1
+	rspAnaSub1.Self = nefApp.Processor().genAnalyticsExposureSubURI("af1", "1")
+	rspAnaSub1.EventNotifs = []nef_models.AnalyticsEventNotif{
+		{
+			AnalyEvent:     nef_models.AnalyticsEvent_UE_MOBILITY,
+			AnalyticsInfos: nef_models.AnalyticsInfos{UeMobilityInfos: ueMobs},
+		},
+	}
+
+	anaSubNoNotifUri := anaSub1ForAf1
+	anaSubNoNotifUri.NotifUri = ""
+
+	anaSubUnsupportedEvent := anaSub1ForAf1
+	anaSubUnsupportedEvent.AnalyEventsSubs = []nef_models.AnalyticsEventSubsc{
+		{
+			AnalyEvent: "UE_LOCATION",
+		},
+	}
+
+	anaSubExterGroupId := anaSub1ForAf1
+	anaSubExterGroupId.AnalyEventsSubs = []nef_models.AnalyticsEventSubsc{
+		{
+			AnalyEvent: nef_models.AnalyticsEvent_UE_MOBILITY,
+			TgtUe: &nef_models.TargetUeId{
+				ExterGroupId: "group1@free5gc.org",
+			},
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		anaSub           nef_models.AnalyticsExposureSubsc
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription, should create events subscription in NWDAF",
+			anaSub:      anaSub1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspAnaSub1.Self},
+				},
+				Body: &rspAnaSub1,
+			},
+		},
+		{
+			description: "TC2: Absent of notifUri",
+			anaSub:      anaSubNoNotifUri,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of notifUri",
+				},
+			},
+		},
+		{
+			description: "TC3: Unsupported analyEvent",
+			anaSub:      anaSubUnsupportedEvent,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unsupported analyEvent: UE_LOCATION",
+				},
+			},
+		},
+		{
+			description: "TC4: exterGroupId is not supported",
+			anaSub:      anaSubExterGroupId,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "exterGroupId is not supported",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			anaSub := tc.anaSub
+			nefApp.Processor().PostAnalyticsExposureSubscription(c, "af1", &anaSub)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "nwdaf1", af.AnaSubs["1"].NwdafSubID)
+	require.Empty(t, af.AnaSubs["1"].AnaSub.EventNotifs)
+	af.Mu.RUnlock()
+}
+
+func TestFetchAnalyticsExposure(t *testing.T) {
+	initNRFDiscStub(models.NfType_NWDAF, models.ServiceName_NNWDAF_ANALYTICSINFO, "127.0.0.6")
+
+	ueComms := json.RawMessage(`[{"commDur":60,"ts":"2024-01-01T00:00:00Z"}]`)
+	anaReq := nef_models.AnalyticsRequest{
+		AnalyEvent: nef_models.AnalyticsEvent_UE_COMM,
+		TgtUe: &nef_models.TargetUeId{
+			Gpsi: "msisdn-0900000000",
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		nwdafStatus      int
+		nwdafData        *nef_models.NwdafAnalyticsData
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Analytics are available in NWDAF",
+			nwdafStatus: http.StatusOK,
+			nwdafData: &nef_models.NwdafAnalyticsData{
+				NwdafAnalyticsInfos: nef_models.NwdafAnalyticsInfos{UeComms: ueComms},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body: &nef_models.AnalyticsData{
+					AnalyticsInfos: nef_models.AnalyticsInfos{UeCommInfos: ueComms},
+				},
+			},
+		},
+		{
+			description: "TC2: No analytics in NWDAF",
+			nwdafStatus: http.StatusNoContent,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nwdafStub := initNWDAFAnalyticsStub(nef_models.NwdafEvent_UE_COMM, tc.nwdafStatus, tc.nwdafData)
+			defer gock.Remove(nwdafStub)
+
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			req := anaReq
+			nefApp.Processor().FetchAnalyticsExposure(c, "af1", &req)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			if tc.expectedResponse.Body != nil {
+				assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestNwdafEventsNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/analytics/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/analytics/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	anaSub := anaSub1ForAf1
+	afSub1 := af1.NewAnaSub(nefCtx.NewCorreID(), &anaSub)
+	afSub1.NwdafSubID = "nwdaf1"
+	af1.AnaSubs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	timeStamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ueMobs := json.RawMessage(`[{"ts":"2024-01-01T00:00:00Z","duration":60}]`)
+	nwdafNotifs := []nef_models.NnwdafEventsSubscriptionNotification{
+		{
+			SubscriptionId: "nwdaf1",
+			EventNotifications: []nef_models.NwdafEventNotification{
+				{
+					Event:               nef_models.NwdafEvent_UE_MOBILITY,
+					TimeStampGen:        &timeStamp,
+					NwdafAnalyticsInfos: nef_models.NwdafAnalyticsInfos{UeMobs: ueMobs},
+				},
+			},
+		},
+	}
+
+	t.Run("TC1: Unknown notification correlation ID", func(t *testing.T) {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+
+		nefApp.Processor().NwdafEventsNotification(c, "100", nwdafNotifs)
+		require.Equal(t, http.StatusNotFound, httpRecorder.Code)
+	})
+
+	t.Run("TC2: Analytics are notified to AF", func(t *testing.T) {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+
+		nefApp.Processor().NwdafEventsNotification(c, afSub1.NotifCorreID, nwdafNotifs)
+		require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+		select {
+		case req := <-afNotifChan:
+			var anaNotif nef_models.AnalyticsEventNotification
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&anaNotif))
+			require.Equal(t, "notif1", anaNotif.NotifId)
+			require.Len(t, anaNotif.AnalyEventNotifs, 1)
+			require.Equal(t, nef_models.AnalyticsEvent_UE_MOBILITY, anaNotif.AnalyEventNotifs[0].AnalyEvent)
+			require.True(t, timeStamp.Equal(*anaNotif.AnalyEventNotifs[0].TimeStamp))
+			require.JSONEq(t, string(ueMobs), string(anaNotif.AnalyEventNotifs[0].UeMobilityInfos))
+		case <-time.After(time.Second):
+			t.Fatal("AF is not notified")
+		}
+	})
+}
+
+func initNWDAFEsCreateStub(eventNotifs []nef_models.NwdafEventNotification) gock.Mock {
+	req := gock.New("http://127.0.0.6:8000/nnwdaf-eventssubscription/v1")
+	req.Post("/subscriptions").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.6:8000/nnwdaf-eventssubscription/v1/subscriptions/nwdaf1").
+		JSON(nef_models.NnwdafEventsSubscription{
+			EventNotifications: eventNotifs,
+		})
+	return req.Mock
+}
+
+func initNWDAFAnalyticsStub(
+	event nef_models.NwdafEvent,
+	status int,
+	anaData *nef_models.NwdafAnalyticsData,
+) gock.Mock {
+	req := gock.New("http://127.0.0.6:8000/nnwdaf-analyticsinfo/v1")
+	rsp := req.Get("/analytics").
+		MatchParam("event-id", string(event)).
+		Reply(status)
+	if anaData != nil {
+		rsp.JSON(anaData)
+	}
+	return req.Mock
+}
@@ -0,0 +1,629 @@
+package processor
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+// The AS session with QoS subscription is mapped to a single media component
+const qosMedCompN int32 = 1
+
+func (p *Processor) GetAsSessionWithQosSubscriptions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.AsSessQosLog.Infof("GetAsSessionWithQosSubscriptions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	qosSubs := []nef_models.AsSessionWithQoSSubscription{}
+	for _, sub := range af.QosSubs {
+		qosSubs = append(qosSubs, *sub.QosSub)
+	}
+	c.JSON(http.StatusOK, &qosSubs)
+}
+
+func (p *Processor) PostAsSessionWithQosSubscription(
+	c *gin.Context,
+	scsAsID string,
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) {
+	logger.AsSessQosLog.Infof("PostAsSessionWithQosSubscription - scsAsID[%s]", scsAsID)
+
+	if rsp := p.validateAsSessionWithQoSSubscription(qosSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub := af.NewQosSub(nefCtx.NewCorreID(), qosSub)
+	asc := p.convertAsSessionWithQoSSubToAppSessionContext(qosSub, afSub.NotifCorreID)
+	rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions("", asc)
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	afSub.AppSessID = appSessID
+	qosSub.Self = p.genAsSessionWithQosSubURI(scsAsID, afSub.SubID)
+
+	af.QosSubs[afSub.SubID] = afSub
+	af.Log.Infoln("AS session with QoS subscription is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", qosSub.Self)
+	c.JSON(http.StatusCreated, qosSub)
+}
+
+func (p *Processor) GetIndividualAsSessionWithQosSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.AsSessQosLog.Infof("GetIndividualAsSessionWithQosSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	afSub, ok := af.QosSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, afSub.QosSub)
+}
+
+func (p *Processor) PutIndividualAsSessionWithQosSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) {
+	logger.AsSessQosLog.Infof("PutIndividualAsSessionWithQosSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	if rsp := p.validateAsSessionWithQoSSubscription(qosSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.QosSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	// The app session in PCF is bound to the UE address
+	if qosSub.UeIpv4Addr != afSub.QosSub.UeIpv4Addr ||
+		qosSub.UeIpv6Addr != afSub.QosSub.UeIpv6Addr ||
+		qosSub.MacAddr != afSub.QosSub.MacAddr {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("UE address can't be changed")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	ascUpdateData := p.convertAsSessionWithQoSSubToAppSessionContextUpdateData(qosSub, afSub.NotifCorreID)
+	rspStatus, rspBody := p.Consumer().PatchAppSession("", afSub.AppSessID, ascUpdateData)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	qosSub.Self = afSub.QosSub.Self
+	afSub.QosSub = qosSub
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, afSub.QosSub)
+}
+
+func (p *Processor) PatchIndividualAsSessionWithQosSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+	qosSubPatch *nef_models.AsSessionWithQoSSubscriptionPatch,
+) {
+	logger.AsSessQosLog.Infof("PatchIndividualAsSessionWithQosSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.QosSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	// Validate the patched subscription before it's applied
+	qosSub := *afSub.QosSub
+	patchedSub := &nef_context.AfQosSubscription{QosSub: &qosSub}
+	patchedSub.PatchQosSubData(qosSubPatch)
+	if rsp := p.validateAsSessionWithQoSSubscription(patchedSub.QosSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	ascUpdateData := p.convertAsSessionWithQoSSubToAppSessionContextUpdateData(
+		patchedSub.QosSub, afSub.NotifCorreID)
+	rspStatus, rspBody := p.Consumer().PatchAppSession("", afSub.AppSessID, ascUpdateData)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	afSub.QosSub = patchedSub.QosSub
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, afSub.QosSub)
+}
+
+func (p *Processor) DeleteIndividualAsSessionWithQosSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.AsSessQosLog.Infof("DeleteIndividualAsSessionWithQosSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.QosSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().DeleteAppSession("", afSub.AppSessID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.QosSubs, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PcfEventNotification forwards the events of the app session reported by the
+// PCF to the AF as user plane events (TS 29.122 clause 5.14.3.3.2).
+func (p *Processor) PcfEventNotification(
+	c *gin.Context,
+	correID string,
+	evsNotif *models.EventsNotification,
+) {
+	logger.AsSessQosLog.Infof("PcfEventNotification - correID[%s]", correID)
+
+	af, sub := p.Context().FindAfQosSub(correID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if eventNotifs := convertEventsNotificationToNefEventNotifications(evsNotif); len(eventNotifs) > 0 {
+		go p.notifyNefEvents(eventNotifs)
+	}
+
+	af.Mu.RLock()
+	notifDest := sub.QosSub.NotificationDestination
+	upNotif := &nef_models.UserPlaneNotificationData{
+		Transaction:  sub.QosSub.Self,
+		EventReports: convertEventsNotificationToUserPlaneEventReports(evsNotif, sub.QosSub.QosReference),
+	}
+	af.Mu.RUnlock()
+
+	if len(upNotif.EventReports) == 0 {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	go func() {
+		if err := p.Notifier().AsSessionQosNotifier.NotifyUserPlaneEvent(notifDest, upNotif); err != nil {
+			sub.Log.Errorf("Notify user plane events failed: %+v", err)
+			return
+		}
+		sub.Log.Infof("User plane events are notified")
+	}()
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PcfTerminationNotification handles the termination of the app session requested
+// by the PCF. The AF is notified of the SESSION_TERMINATION, and then the app
+// session and the subscription are deleted.
+func (p *Processor) PcfTerminationNotification(
+	c *gin.Context,
+	correID string,
+	termInfo *models.TerminationInfo,
+) {
+	logger.AsSessQosLog.Infof("PcfTerminationNotification - correID[%s], cause[%s]",
+		correID, termInfo.TermCause)
+
+	af, sub := p.Context().FindAfQosSub(correID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	go p.terminateAsSessionWithQos(af, sub)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (p *Processor) terminateAsSessionWithQos(
+	af *nef_context.AfData,
+	sub *nef_context.AfQosSubscription,
+) {
+	// The AF lock is not held while the AF and the PCF are requested
+	af.Mu.Lock()
+	if af.QosSubs[sub.SubID] != sub {
+		af.Mu.Unlock()
+		return
+	}
+	notifDest := sub.QosSub.NotificationDestination
+	upNotif := &nef_models.UserPlaneNotificationData{
+		Transaction: sub.QosSub.Self,
+		EventReports: []nef_models.UserPlaneEventReport{
+			{
+				Event: nef_models.UserPlaneEvent_SESSION_TERMINATION,
+			},
+		},
+	}
+	appSessID := sub.AppSessID
+	af.Mu.Unlock()
+
+	err := p.Notifier().AsSessionQosNotifier.NotifyUserPlaneEvent(notifDest, upNotif)
+	if err != nil {
+		sub.Log.Errorf("Notify session termination failed: %+v", err)
+	}
+
+	rspStatus, _ := p.Consumer().DeleteAppSession("", appSessID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		sub.Log.Warnf("Delete app session[%s] failed: rspCode[%d]", appSessID, rspStatus)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+	if af.QosSubs[sub.SubID] == sub {
+		delete(af.QosSubs, sub.SubID)
+		p.Context().StoreAf(af)
+	}
+	sub.Log.Infoln("AS session with QoS subscription is terminated")
+}
+
+func (p *Processor) validateAsSessionWithQoSSubscription(
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) *HandlerResponse {
+	if qosSub.NotificationDestination == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notificationDestination")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	// TS 29.122: One of "ueIpv4Addr", "ueIpv6Addr" or "macAddr" shall be included.
+	numUeAddr := 0
+	for _, addr := range []string{qosSub.UeIpv4Addr, qosSub.UeIpv6Addr, qosSub.MacAddr} {
+		if addr != "" {
+			numUeAddr++
+		}
+	}
+	if numUeAddr != 1 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"One of ueIpv4Addr, ueIpv6Addr or macAddr shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	// TS 29.122: "flowInfo" is applicable for the UE IP address,
+	// and "ethFlowInfo" is applicable for the UE MAC address.
+	if qosSub.MacAddr != "" && len(qosSub.EthFlowInfo) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of ethFlowInfo for macAddr")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if qosSub.MacAddr == "" && len(qosSub.FlowInfo) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of flowInfo for UE IP address")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	for _, qosRef := range append([]string{qosSub.QosReference}, qosSub.AltQoSReferences...) {
+		if qosRef != "" && p.Config().QosReference(qosRef) == nil {
+			pd := openapi.ProblemDetailsMalformedReqSyntax(fmt.Sprintf("Unknown QoS reference: %s", qosRef))
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+	}
+	return nil
+}
+
+func (p *Processor) genAsSessionWithQosSubURI(scsAsID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-as-session-with-qos/v1/{scsAsId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceAsSessQos) + "/" + scsAsID + "/subscriptions/" + subID
+}
+
+// genPcfNotificationUri returns the notifUri of the app session, the PCF sends
+// the event notifications to "{notifUri}/notify" and the termination to "{notifUri}/terminate".
+func (p *Processor) genPcfNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/pcf/" + notifCorreID
+}
+
+func (p *Processor) convertAsSessionWithQoSSubToAppSessionContext(
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+	notifCorreID string,
+) *models.AppSessionContext {
+	medComp := p.convertAsSessionWithQoSSubToMediaComponent(qosSub)
+	asc := &models.AppSessionContext{
+		AscReqData: &models.AppSessionContextReqData{
+			AfAppId: qosSub.ExterAppId,
+			Dnn:     qosSub.Dnn,
+			EvSubsc: &models.EventsSubscReqData{
+				Events:   genQosAfEventSubscriptions(qosSub),
+				NotifUri: p.genPcfNotificationUri(notifCorreID),
+				UsgThres: qosSub.UsageThreshold,
+			},
+			MedComponents: map[string]models.MediaComponent{
+				strconv.Itoa(int(medComp.MedCompN)): *medComp,
+			},
+			NotifUri:  p.genPcfNotificationUri(notifCorreID),
+			SliceInfo: qosSub.Snssai,
+			SuppFeat:  qosSub.SupportedFeatures,
+			UeIpv4:    qosSub.UeIpv4Addr,
+			UeIpv6:    qosSub.UeIpv6Addr,
+			UeMac:     qosSub.MacAddr,
+		},
+	}
+	return asc
+}
+
+func (p *Processor) convertAsSessionWithQoSSubToAppSessionContextUpdateData(
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+	notifCorreID string,
+) *models.AppSessionContextUpdateData {
+	medComp := p.convertAsSessionWithQoSSubToMediaComponent(qosSub)
+	ascUpdateData := &models.AppSessionContextUpdateData{
+		AfAppId: qosSub.ExterAppId,
+		EvSubsc: &models.EventsSubscReqDataRm{
+			Events:   genQosAfEventSubscriptions(qosSub),
+			NotifUri: p.genPcfNotificationUri(notifCorreID),
+			UsgThres: convertUsageThresholdToUsageThresholdRm(qosSub.UsageThreshold),
+		},
+		MedComponents: map[string]models.MediaComponentRm{
+			strconv.Itoa(int(medComp.MedCompN)): *convertMediaComponentToMediaComponentRm(medComp),
+		},
+	}
+	return ascUpdateData
+}
+
+// convertAsSessionWithQoSSubToMediaComponent maps the flows to the media subcomponents.
+// The bitrates are resolved from the QoS reference, and overridden by the tscQosReq if present.
+func (p *Processor) convertAsSessionWithQoSSubToMediaComponent(
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) *models.MediaComponent {
+	medComp := &models.MediaComponent{
+		AfAppId:     qosSub.ExterAppId,
+		FStatus:     models.FlowStatus_ENABLED,
+		MedCompN:    qosMedCompN,
+		MedSubComps: genMediaSubComponents(qosSub.FlowInfo, qosSub.EthFlowInfo),
+	}
+
+	if qosRef := p.Config().QosReference(qosSub.QosReference); qosRef != nil {
+		medComp.MarBwDl = qosRef.MarBwDl
+		medComp.MarBwUl = qosRef.MarBwUl
+		medComp.MirBwDl = qosRef.MirBwDl
+		medComp.MirBwUl = qosRef.MirBwUl
+	}
+	if tscQosReq := qosSub.TscQosReq; tscQosReq != nil {
+		if tscQosReq.ReqMbrDl != "" {
+			medComp.MarBwDl = tscQosReq.ReqMbrDl
+		}
+		if tscQosReq.ReqMbrUl != "" {
+			medComp.MarBwUl = tscQosReq.ReqMbrUl
+		}
+		if tscQosReq.ReqGbrDl != "" {
+			medComp.MirBwDl = tscQosReq.ReqGbrDl
+		}
+		if tscQosReq.ReqGbrUl != "" {
+			medComp.MirBwUl = tscQosReq.ReqGbrUl
+		}
+	}
+
+	return medComp
+}
+
+// genMediaSubComponents maps the IP flows or the Ethernet flows of the application
+// to the media subcomponents of the app session.
+func genMediaSubComponents(
+	flowInfos []models.FlowInfo,
+	ethFlowDescs []models.EthFlowDescription,
+) map[string]models.MediaSubComponent {
+	medSubComps := make(map[string]models.MediaSubComponent)
+	for _, flowInfo := range flowInfos {
+		medSubComps[strconv.Itoa(int(flowInfo.FlowId))] = models.MediaSubComponent{
+			FNum:    flowInfo.FlowId,
+			FDescs:  flowInfo.FlowDescriptions,
+			FStatus: models.FlowStatus_ENABLED,
+		}
+	}
+	for i, ethFlowDesc := range ethFlowDescs {
+		fNum := int32(i + 1)
+		medSubComps[strconv.Itoa(int(fNum))] = models.MediaSubComponent{
+			FNum:      fNum,
+			EthfDescs: []models.EthFlowDescription{ethFlowDesc},
+			FStatus:   models.FlowStatus_ENABLED,
+		}
+	}
+	return medSubComps
+}
+
+func convertMediaComponentToMediaComponentRm(medComp *models.MediaComponent) *models.MediaComponentRm {
+	medCompRm := &models.MediaComponentRm{
+		AfAppId:     medComp.AfAppId,
+		FStatus:     medComp.FStatus,
+		MarBwDl:     medComp.MarBwDl,
+		MarBwUl:     medComp.MarBwUl,
+		MedCompN:    medComp.MedCompN,
+		MedSubComps: make(map[string]models.MediaSubComponentRm),
+		MirBwDl:     medComp.MirBwDl,
+		MirBwUl:     medComp.MirBwUl,
+	}
+	for key, medSubComp := range medComp.MedSubComps {
+		medCompRm.MedSubComps[key] = models.MediaSubComponentRm{
+			EthfDescs: medSubComp.EthfDescs,
+			FNum:      medSubComp.FNum,
+			FDescs:    medSubComp.FDescs,
+			FStatus:   medSubComp.FStatus,
+		}
+	}
+	return medCompRm
+}
+
+func convertUsageThresholdToUsageThresholdRm(usgThres *models.UsageThreshold) *models.UsageThresholdRm {
+	if usgThres == nil {
+		return nil
+	}
+	return &models.UsageThresholdRm{
+		Duration:       usgThres.Duration,
+		TotalVolume:    usgThres.TotalVolume,
+		DownlinkVolume: usgThres.DownlinkVolume,
+		UplinkVolume:   usgThres.UplinkVolume,
+	}
+}
+
+func genQosAfEventSubscriptions(
+	qosSub *nef_models.AsSessionWithQoSSubscription,
+) []models.AfEventSubscription {
+	events := []models.AfEventSubscription{
+		{
+			Event:       models.AfEvent_QOS_NOTIF,
+			NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+		},
+		{
+			Event:       models.AfEvent_SUCCESSFUL_RESOURCES_ALLOCATION,
+			NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+		},
+		{
+			Event:       models.AfEvent_FAILED_RESOURCES_ALLOCATION,
+			NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+		},
+	}
+	if qosSub.UsageThreshold != nil {
+		events = append(events, models.AfEventSubscription{
+			Event:       models.AfEvent_USAGE_REPORT,
+			NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+		})
+	}
+	return events
+}
+
+func convertEventsNotificationToUserPlaneEventReports(
+	evsNotif *models.EventsNotification,
+	qosRef string,
+) []nef_models.UserPlaneEventReport {
+	var reports []nef_models.UserPlaneEventReport
+	for _, evNotif := range evsNotif.EvNotifs {
+		var flowIds []int32
+		for _, flows := range evNotif.Flows {
+			flowIds = append(flowIds, flows.FNums...)
+		}
+
+		switch evNotif.Event {
+		case models.AfEvent_QOS_NOTIF:
+			for _, qncReport := range evsNotif.QncReports {
+				report := nef_models.UserPlaneEventReport{
+					Event:         nef_models.UserPlaneEvent_QOS_NOT_GUARANTEED,
+					FlowIds:       flowIds,
+					AppliedQosRef: qosRef,
+				}
+				if qncReport.NotifType == models.QosNotifType_GUARANTEED {
+					report.Event = nef_models.UserPlaneEvent_QOS_GUARANTEED
+				}
+				reports = append(reports, report)
+			}
+		case models.AfEvent_SUCCESSFUL_RESOURCES_ALLOCATION:
+			reports = append(reports, nef_models.UserPlaneEventReport{
+				Event:         nef_models.UserPlaneEvent_SUCCESSFUL_RESOURCES_ALLOCATION,
+				FlowIds:       flowIds,
+				AppliedQosRef: qosRef,
+			})
+		case models.AfEvent_FAILED_RESOURCES_ALLOCATION:
+			reports = append(reports, nef_models.UserPlaneEventReport{
+				Event:   nef_models.UserPlaneEvent_FAILED_RESOURCES_ALLOCATION,
+				FlowIds: flowIds,
+			})
+		case models.AfEvent_USAGE_REPORT:
+			reports = append(reports, nef_models.UserPlaneEventReport{
+				Event:            nef_models.UserPlaneEvent_USAGE_REPORT,
+				AccumulatedUsage: evsNotif.UsgRep,
+			})
+		case models.AfEvent_ACCESS_TYPE_CHANGE:
+			reports = append(reports, nef_models.UserPlaneEventReport{
+				Event:      nef_models.UserPlaneEvent_ACCESS_TYPE_CHANGE,
+				AccessType: evsNotif.AccessType,
+			})
+		case models.AfEvent_PLMN_CHG:
+			reports = append(reports, nef_models.UserPlaneEventReport{
+				Event:  nef_models.UserPlaneEvent_PLMN_CHG,
+				PlmnId: evsNotif.PlmnId,
+			})
+		default:
+			logger.AsSessQosLog.Debugf("Ignore PCF event[%s]", evNotif.Event)
+		}
+	}
+	return reports
+}
@@ -0,0 +1,359 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var qosSub1ForAf1 = nef_models.AsSessionWithQoSSubscription{
+	Dnn: "internet",
+	Snssai: &models.Snssai{
+		Sst: 1,
+		Sd:  "010203",
+	},
+	NotificationDestination: "http://127.0.0.100:8000/qos/notify",
+	FlowInfo: []models.FlowInfo{
+		{
+			FlowId: 1,
+			FlowDescriptions: []string{
+				"permit out ip from 10.60.0.1 to 10.60.0.0/16",
+			},
+		},
+	},
+	QosReference: "qos-video",
+	UeIpv4Addr:   "10.60.0.1",
+}
+
+func TestPostAsSessionWithQosSubscription(t *testing.T) {
+	initNRFDiscPCFStub()
+	// Only remove the stubs of this test, the NRF stubs set in TestMain are still needed by the others.
+	pcfStub := initPCFPaAppSessionStub(http.MethodPost, "/app-sessions", http.StatusCreated)
+	defer gock.Remove(pcfStub)
+
+	rspQosSub1 := qosSub1ForAf1
+	rspQosSub1.Self = nefApp.Processor().genAsSessionWithQosSubURI("af1", "1")
+
+	qosSubNoNotifDest := qosSub1ForAf1
+	qosSubNoNotifDest.NotificationDestination = ""
+
+	qosSubTwoUeAddrs := qosSub1ForAf1
+	qosSubTwoUeAddrs.MacAddr = "00-1A-2B-3C-4D-5E"
+
+	qosSubUnknownQosRef := qosSub1ForAf1
+	qosSubUnknownQosRef.QosReference = "qos-unknown"
+
+	testCases := []struct {
+		description      string
+		qosSub           nef_models.AsSessionWithQoSSubscription
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription, should post AppSession to PCF",
+			qosSub:      qosSub1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspQosSub1.Self},
+				},
+				Body: &rspQosSub1,
+			},
+		},
+		{
+			description: "TC2: Absent of notificationDestination",
+			qosSub:      qosSubNoNotifDest,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of notificationDestination",
+				},
+			},
+		},
+		{
+			description: "TC3: More than one UE address",
+			qosSub:      qosSubTwoUeAddrs,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "One of ueIpv4Addr, ueIpv6Addr or macAddr shall be included",
+				},
+			},
+		},
+		{
+			description: "TC4: Unknown QoS reference",
+			qosSub:      qosSubUnknownQosRef,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unknown QoS reference: qos-unknown",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			qosSub := tc.qosSub
+			nefApp.Processor().PostAsSessionWithQosSubscription(c, "af1", &qosSub)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "12345", af.QosSubs["1"].AppSessID)
+	af.Mu.RUnlock()
+}
+
+func TestPatchAndDeleteAsSessionWithQosSubscription(t *testing.T) {
+	initNRFDiscPCFStub()
+	patchStub := initPCFPaAppSessionStub(http.MethodPatch, "/app-sessions/12345", http.StatusOK)
+	defer gock.Remove(patchStub)
+	deleteStub := initPCFPaAppSessionStub(http.MethodPost, "/app-sessions/12345/delete", http.StatusNoContent)
+	defer gock.Remove(deleteStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	qosSub := qosSub1ForAf1
+	afSub1 := af1.NewQosSub(nefCtx.NewCorreID(), &qosSub)
+	afSub1.AppSessID = "12345"
+	qosSub.Self = nefApp.Processor().genAsSessionWithQosSubURI("af1", afSub1.SubID)
+	af1.QosSubs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	rspQosSub := qosSub
+	rspQosSub.QosReference = ""
+	rspQosSub.TscQosReq = &nef_models.TscQosRequirement{
+		ReqGbrDl: "2 Mbps",
+	}
+
+	testCases := []struct {
+		description      string
+		subID            string
+		qosSubPatch      *nef_models.AsSessionWithQoSSubscriptionPatch
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Subscription is not found",
+			subID:       "99",
+			qosSubPatch: &nef_models.AsSessionWithQoSSubscriptionPatch{},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Unknown alternative QoS reference",
+			subID:       afSub1.SubID,
+			qosSubPatch: &nef_models.AsSessionWithQoSSubscriptionPatch{
+				AltQoSReferences: []string{"qos-unknown"},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unknown QoS reference: qos-unknown",
+				},
+			},
+		},
+		{
+			description: "TC3: Successful patch, should patch AppSession in PCF",
+			subID:       afSub1.SubID,
+			qosSubPatch: &nef_models.AsSessionWithQoSSubscriptionPatch{
+				TscQosReq: rspQosSub.TscQosReq,
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body: &nef_models.AsSessionWithQoSSubscription{
+					Self:                    qosSub.Self,
+					Dnn:                     qosSub.Dnn,
+					Snssai:                  qosSub.Snssai,
+					NotificationDestination: qosSub.NotificationDestination,
+					FlowInfo:                qosSub.FlowInfo,
+					QosReference:            qosSub.QosReference,
+					UeIpv4Addr:              qosSub.UeIpv4Addr,
+					TscQosReq:               rspQosSub.TscQosReq,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().PatchIndividualAsSessionWithQosSubscription(c, "af1", tc.subID, tc.qosSubPatch)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().DeleteIndividualAsSessionWithQosSubscription(c, "af1", afSub1.SubID)
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	af1.Mu.RLock()
+	require.NotContains(t, af1.QosSubs, afSub1.SubID)
+	af1.Mu.RUnlock()
+}
+
+func TestPcfEventNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/qos/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/qos/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	qosSub := qosSub1ForAf1
+	qosSub.Self = "http://127.0.0.5:8000/3gpp-as-session-with-qos/v1/af1/subscriptions/1"
+	afSub1 := af1.NewQosSub(nefCtx.NewCorreID(), &qosSub)
+	afSub1.AppSessID = "12345"
+	af1.QosSubs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	testCases := []struct {
+		description      string
+		correID          string
+		evsNotif         *models.EventsNotification
+		expectedResponse *HandlerResponse
+		expectedNotif    *nef_models.UserPlaneNotificationData
+	}{
+		{
+			description: "TC1: Subscription is not found",
+			correID:     "99",
+			evsNotif:    &models.EventsNotification{},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: QoS not guaranteed, should notify AF",
+			correID:     afSub1.NotifCorreID,
+			evsNotif: &models.EventsNotification{
+				EvNotifs: []models.AfEventNotification{
+					{
+						Event: models.AfEvent_QOS_NOTIF,
+						Flows: []models.Flows{
+							{
+								FNums: []int32{1},
+							},
+						},
+					},
+				},
+				QncReports: []models.QosNotificationControlInfo{
+					{
+						NotifType: models.QosNotifType_NOT_GUARANTEED,
+					},
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+			expectedNotif: &nef_models.UserPlaneNotificationData{
+				Transaction: qosSub.Self,
+				EventReports: []nef_models.UserPlaneEventReport{
+					{
+						Event:         nef_models.UserPlaneEvent_QOS_NOT_GUARANTEED,
+						FlowIds:       []int32{1},
+						AppliedQosRef: "qos-video",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().PcfEventNotification(c, tc.correID, tc.evsNotif)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedNotif == nil {
+				return
+			}
+			select {
+			case req := <-afNotifChan:
+				var upNotif nef_models.UserPlaneNotificationData
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&upNotif))
+				require.Equal(t, *tc.expectedNotif, upNotif)
+			case <-time.After(time.Second):
+				t.Fatal("AF is not notified")
+			}
+		})
+	}
+}
+
+func initPCFPaAppSessionStub(method, path string, statusCode int) gock.Mock {
+	req := gock.New("http://127.0.0.7:8000/npcf-policyauthorization/v1")
+	req.Method = method
+	req.Path(path).
+		Persist().
+		Reply(statusCode)
+	if method == http.MethodPost && path == "/app-sessions" {
+		req.Response.SetHeader("Location",
+			"http://127.0.0.7:8000/npcf-policyauthorization/v1/app-sessions/12345")
+	}
+	return req.Mock
+}
@@ -0,0 +1,240 @@
+package processor
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (p *Processor) GetBdtSubscriptions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.BdtLog.Infof("GetBdtSubscriptions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	bdts := []nef_models.Bdt{}
+	for _, sub := range af.BdtSubs {
+		bdts = append(bdts, *sub.Bdt)
+	}
+	c.JSON(http.StatusOK, &bdts)
+}
+
+// PostBdtSubscription negotiates the transfer policies of the background data
+// transfer with the PCF, and returns the offered transfer policies to the AF.
+func (p *Processor) PostBdtSubscription(
+	c *gin.Context,
+	scsAsID string,
+	bdt *nef_models.Bdt,
+) {
+	logger.BdtLog.Infof("PostBdtSubscription - scsAsID[%s]", scsAsID)
+
+	if rsp := validateBdt(bdt); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	rspStatus, rspBody, bdtPolicyID := p.Consumer().CreateBDTPolicy(convertBdtToBdtReqData(scsAsID, bdt))
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	bdtPolicy, ok := rspBody.(*models.BdtPolicy)
+	if !ok || bdtPolicy.BdtPolData == nil {
+		pd := openapi.ProblemDetailsSystemFailure("No transfer policy is offered")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub := af.NewBdtSub(bdt)
+	sub.BdtPolicyID = bdtPolicyID
+	bdt.Self = p.genBdtSubURI(scsAsID, sub.SubID)
+	bdt.ReferenceId = bdtPolicy.BdtPolData.BdtRefId
+	bdt.TransferPolicies = convertTransferPolicies(bdtPolicy.BdtPolData.TransfPolicies)
+	// The PCF may have selected the transfer policy, e.g. only one is offered
+	bdt.SelectedPolicy = bdtPolicy.BdtPolData.SelTransPolicyId
+
+	af.BdtSubs[sub.SubID] = sub
+	af.Log.Infoln("BDT subscription is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", bdt.Self)
+	c.JSON(http.StatusCreated, bdt)
+}
+
+func (p *Processor) GetIndividualBdtSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.BdtLog.Infof("GetIndividualBdtSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	sub, ok := af.BdtSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub.Bdt)
+}
+
+// PatchIndividualBdtSubscription selects one of the offered transfer policies,
+// and informs the PCF of the selection.
+func (p *Processor) PatchIndividualBdtSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+	bdtPatch *nef_models.BdtPatch,
+) {
+	logger.BdtLog.Infof("PatchIndividualBdtSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.BdtSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if sub.TransferPolicy(bdtPatch.SelectedPolicy) == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			fmt.Sprintf("Unknown selectedPolicy: %d", bdtPatch.SelectedPolicy))
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().UpdateBDTPolicy(sub.BdtPolicyID, &models.BdtPolicyDataPatch{
+		SelTransPolicyId: bdtPatch.SelectedPolicy,
+	})
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	sub.Bdt.SelectedPolicy = bdtPatch.SelectedPolicy
+	sub.Log.Infof("Transfer policy[%d] is selected", bdtPatch.SelectedPolicy)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, sub.Bdt)
+}
+
+// DeleteIndividualBdtSubscription deletes the subscription only, the BDT policy
+// is kept in the PCF since Npcf_BDTPolicyControl doesn't support the deletion.
+func (p *Processor) DeleteIndividualBdtSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.BdtLog.Infof("DeleteIndividualBdtSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	if _, ok := af.BdtSubs[subID]; !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	delete(af.BdtSubs, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func validateBdt(bdt *nef_models.Bdt) *HandlerResponse {
+	if bdt.VolumePerUE == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of volumePerUE")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if bdt.NumberOfUEs <= 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid numberOfUEs")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if tw := bdt.DesiredTimeWindow; tw == nil || tw.StartTime == "" || tw.StopTime == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of desiredTimeWindow")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genBdtSubURI(scsAsID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-bdt/v1/{scsAsId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceBdt) + "/" + scsAsID + "/subscriptions/" + subID
+}
+
+func convertBdtToBdtReqData(scsAsID string, bdt *nef_models.Bdt) *models.BdtReqData {
+	bdtReqData := &models.BdtReqData{
+		AspId:      scsAsID,
+		DesTimeInt: bdt.DesiredTimeWindow,
+		NumOfUes:   bdt.NumberOfUEs,
+		VolPerUe:   bdt.VolumePerUE,
+		SuppFeat:   bdt.SupportedFeatures,
+	}
+	if bdt.LocationArea5G != nil {
+		bdtReqData.NwAreaInfo = bdt.LocationArea5G.NwAreaInfo
+	}
+	return bdtReqData
+}
+
+func convertTransferPolicies(transfPolicies []models.TransferPolicy) []nef_models.TransferPolicy {
+	var policies []nef_models.TransferPolicy
+	for _, transfPolicy := range transfPolicies {
+		policies = append(policies, nef_models.TransferPolicy{
+			BdtPolicyId:  transfPolicy.TransPolicyId,
+			MaxBitRateDl: transfPolicy.MaxBitRateDl,
+			MaxBitRateUl: transfPolicy.MaxBitRateUl,
+			RatingGroup:  transfPolicy.RatingGroup,
+			TimeWindow:   transfPolicy.RecTimeInt,
+		})
+	}
+	return policies
+}
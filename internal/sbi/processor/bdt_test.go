@@ -0,0 +1,235 @@
+package processor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var bdt1ForAf1 = nef_models.Bdt{
+	VolumePerUE: &models.UsageThreshold{
+		TotalVolume: 500000000,
+	},
+	NumberOfUEs: 100,
+	DesiredTimeWindow: &models.TimeWindow{
+		StartTime: "2024-01-01T01:00:00Z",
+		StopTime:  "2024-01-01T05:00:00Z",
+	},
+}
+
+var transfPolicies = []models.TransferPolicy{
+	{
+		TransPolicyId: 1,
+		MaxBitRateDl:  "100 Mbps",
+		RatingGroup:   10,
+		RecTimeInt: &models.TimeWindow{
+			StartTime: "2024-01-01T01:00:00Z",
+			StopTime:  "2024-01-01T03:00:00Z",
+		},
+	},
+	{
+		TransPolicyId: 2,
+		MaxBitRateDl:  "50 Mbps",
+		RatingGroup:   20,
+		RecTimeInt: &models.TimeWindow{
+			StartTime: "2024-01-01T03:00:00Z",
+			StopTime:  "2024-01-01T05:00:00Z",
+		},
+	},
+}
+
+func TestPostBdtSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_PCF, models.ServiceName_NPCF_BDTPOLICYCONTROL, "127.0.0.7")
+	// Only remove the stubs of this test, the NRF stubs set in TestMain are still needed by the others.
+	pcfStub := initPCFBdtCreateStub(&models.BdtPolicyData{
+		BdtRefId:       "bdtref1",
+		TransfPolicies: transfPolicies,
+	})
+	defer gock.Remove(pcfStub)
+
+	rspBdt1 := bdt1ForAf1
+	rspBdt1.Self = nefApp.Processor().genBdtSubURI("af1", "1")
+	rspBdt1.ReferenceId = "bdtref1"
+	rspBdt1.TransferPolicies = convertTransferPolicies(transfPolicies)
+
+	bdtNoVolume := bdt1ForAf1
+	bdtNoVolume.VolumePerUE = nil
+
+	bdtNoTimeWindow := bdt1ForAf1
+	bdtNoTimeWindow.DesiredTimeWindow = nil
+
+	testCases := []struct {
+		description      string
+		bdt              nef_models.Bdt
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription, should return transfer policies offered by PCF",
+			bdt:         bdt1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspBdt1.Self},
+				},
+				Body: &rspBdt1,
+			},
+		},
+		{
+			description: "TC2: Absent of volumePerUE",
+			bdt:         bdtNoVolume,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of volumePerUE",
+				},
+			},
+		},
+		{
+			description: "TC3: Absent of desiredTimeWindow",
+			bdt:         bdtNoTimeWindow,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of desiredTimeWindow",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			bdt := tc.bdt
+			nefApp.Processor().PostBdtSubscription(c, "af1", &bdt)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "bdtpolicy1", af.BdtSubs["1"].BdtPolicyID)
+	af.Mu.RUnlock()
+}
+
+func TestPatchBdtSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_PCF, models.ServiceName_NPCF_BDTPOLICYCONTROL, "127.0.0.7")
+	pcfStub := initPCFBdtUpdateStub("bdtpolicy1")
+	defer gock.Remove(pcfStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	bdt := bdt1ForAf1
+	bdt.ReferenceId = "bdtref1"
+	bdt.TransferPolicies = convertTransferPolicies(transfPolicies)
+	sub := af1.NewBdtSub(&bdt)
+	sub.BdtPolicyID = "bdtpolicy1"
+	bdt.Self = nefApp.Processor().genBdtSubURI("af1", sub.SubID)
+	af1.BdtSubs[sub.SubID] = sub
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	rspBdt := bdt
+	rspBdt.SelectedPolicy = 2
+
+	testCases := []struct {
+		description      string
+		subID            string
+		bdtPatch         *nef_models.BdtPatch
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Subscription is not found",
+			subID:       "99",
+			bdtPatch:    &nef_models.BdtPatch{SelectedPolicy: 1},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Transfer policy is not offered",
+			subID:       sub.SubID,
+			bdtPatch:    &nef_models.BdtPatch{SelectedPolicy: 3},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unknown selectedPolicy: 3",
+				},
+			},
+		},
+		{
+			description: "TC3: Successful selection, should update BDT policy in PCF",
+			subID:       sub.SubID,
+			bdtPatch:    &nef_models.BdtPatch{SelectedPolicy: 2},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &rspBdt,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().PatchIndividualBdtSubscription(c, "af1", tc.subID, tc.bdtPatch)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+}
+
+func initPCFBdtCreateStub(bdtPolData *models.BdtPolicyData) gock.Mock {
+	req := gock.New("http://127.0.0.7:8000/npcf-bdtpolicycontrol/v1")
+	req.Post("/bdtpolicies").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.7:8000/npcf-bdtpolicycontrol/v1/bdtpolicies/bdtpolicy1").
+		JSON(models.BdtPolicy{
+			BdtPolData: bdtPolData,
+		})
+	return req.Mock
+}
+
+func initPCFBdtUpdateStub(bdtPolicyID string) gock.Mock {
+	req := gock.New("http://127.0.0.7:8000/npcf-bdtpolicycontrol/v1")
+	req.Patch("/bdtpolicies/" + bdtPolicyID).
+		Persist().
+		Reply(http.StatusNoContent)
+	return req.Mock
+}
@@ -1,17 +1,29 @@
 package processor
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	nef_context "github.com/free5gc/nef/internal/context"
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/openapi/models_nef"
 	"github.com/gin-gonic/gin"
 )
 
+// maxAfAckWaitInSmfNotif bounds the wait for the AF acknowledgements returned in the
+// response to the SMF, which is kept below the timeout of the SMF's SBI client.
+const maxAfAckWaitInSmfNotif = 3 * time.Second
+
 func (p *Processor) SmfNotification(
 	c *gin.Context,
-	eeNotif *models.NsmfEventExposureNotification,
+	eeNotif *nef_models.NsmfEventExposureNotification,
 ) {
 	logger.TrafInfluLog.Infof("SmfNotification - NotifId[%s]", eeNotif.NotifId)
 
@@ -23,9 +35,269 @@ func (p *Processor) SmfNotification(
 	}
 
 	af.Mu.RLock()
-	defer af.Mu.RUnlock()
+	notifDest := sub.TiSub.NotificationDestination
+	afAckInd := sub.TiSub.AfAckInd
+	var eventNotifs []models_nef.EventNotification
+	for i := range eeNotif.EventNotifs {
+		if eeNotif.EventNotifs[i].Event != models.SmfEvent_UP_PATH_CH {
+			sub.Log.Debugf("Ignore SMF event[%s]", eeNotif.EventNotifs[i].Event)
+			continue
+		}
+		eventNotifs = append(eventNotifs,
+			*convertSmfEventNotificationToEventNotification(sub.TiSub, &eeNotif.EventNotifs[i]))
+	}
+	af.Mu.RUnlock()
 
-	// TODO: Notify AF
+	if notifDest == "" {
+		sub.Log.Warnln("No notificationDestination, UP path change is not notified")
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+	if len(eventNotifs) == 0 {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if !afAckInd {
+		go p.sendUpPathChgNotifications(af, sub, notifDest, eventNotifs)
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
 
-	c.JSON(http.StatusOK, nil)
+	// TS 29.508 clause 5.2.2.5: The AF acknowledgement is sent to the ackUri if the SMF
+	// provides one, otherwise it is included in the response of the notification.
+	if eeNotif.AckUri != "" {
+		go func() {
+			acks := p.sendUpPathChgNotificationsWithAck(af, sub, eeNotif.NotifId, notifDest, eventNotifs,
+				p.Config().AfAckTimeout())
+			if len(acks) == 0 {
+				return
+			}
+			if err := p.Notifier().TrafficInfluNotifier.SendAckOfNotify(eeNotif.AckUri, acks); err != nil {
+				sub.Log.Errorf("Relay AF acknowledgement to SMF failed: %+v", err)
+				return
+			}
+			sub.Log.Infof("AF acknowledgement is relayed to SMF")
+		}()
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	// The SMF is waiting for the response, which shall be sent before its request times out
+	timeout := min(p.Config().AfAckTimeout(), maxAfAckWaitInSmfNotif)
+	acks := p.sendUpPathChgNotificationsWithAck(af, sub, eeNotif.NotifId, notifDest, eventNotifs, timeout)
+	if len(acks) == 0 {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+	c.JSON(http.StatusOK, acks)
+}
+
+func (p *Processor) PostAfAck(
+	c *gin.Context,
+	ackID string,
+	afAckInfo *models_nef.AfAckInfo,
+) {
+	logger.TrafInfluLog.Infof("PostAfAck - ackID[%s]", ackID)
+
+	if afAckInfo.AckResult == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of AfAckInfo.AckResult")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	if !p.Context().DeliverAfAck(ackID, afAckInfo) {
+		pd := openapi.ProblemDetailsDataNotFound("AF acknowledgement is not expected")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// InfluenceDataUpdateNotification handles the changes of the influence data in the UDR.
+// The subscription is updated and the AF is informed if the data is modified by others,
+// or the subscription is cancelled if the data is removed.
+func (p *Processor) InfluenceDataUpdateNotification(
+	c *gin.Context,
+	correID string,
+	influDataNotifs []models.TrafficInfluDataNotif,
+) {
+	logger.TrafInfluLog.Infof("InfluenceDataUpdateNotification - correID[%s]", correID)
+
+	af, sub := p.Context().FindAfSub(correID)
+	if sub == nil || sub.InfluID == "" {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	var afNotif *nef_models.TrafficInfluSubNotification
+	for i := range influDataNotifs {
+		// The resUri is {apiRoot}/nudr-dr/v1/application-data/influenceData/{influenceId}
+		resUri := influDataNotifs[i].ResUri
+		if resUri[strings.LastIndex(resUri, "/")+1:] != sub.InfluID {
+			continue
+		}
+		if influDataNotifs[i].TrafficInfluData == nil {
+			sub.Log.Infoln("Influence data is removed in UDR, subscription is cancelled")
+			delete(af.Subs, sub.SubID)
+			afNotif = &nef_models.TrafficInfluSubNotification{
+				Subscription: sub.TiSub.Self,
+				CancelInd:    true,
+			}
+			break
+		}
+		if sub.UpdateTiSubData(influDataNotifs[i].TrafficInfluData) {
+			sub.Log.Infoln("Influence data is modified in UDR")
+			tiSub := *sub.TiSub
+			afNotif = &nef_models.TrafficInfluSubNotification{
+				Subscription:    sub.TiSub.Self,
+				TrafficInfluSub: &tiSub,
+			}
+		}
+	}
+	notifDest := sub.TiSub.NotificationDestination
+	if afNotif != nil {
+		p.Context().StoreAf(af)
+	}
+	af.Mu.Unlock()
+
+	c.JSON(http.StatusNoContent, nil)
+
+	if afNotif == nil {
+		return
+	}
+	go func() {
+		if afNotif.CancelInd {
+			p.unsubscribeInfluenceDataChange(sub)
+		}
+		if notifDest == "" {
+			sub.Log.Warnln("No notificationDestination, influence data change is not notified")
+			return
+		}
+		err := p.Notifier().TrafficInfluNotifier.NotifyTrafficInfluSubChange(notifDest, afNotif)
+		af.Mu.Lock()
+		sub.RecordNotifDelivery(err)
+		af.Mu.Unlock()
+	}()
+}
+
+func (p *Processor) sendUpPathChgNotifications(
+	af *nef_context.AfData,
+	sub *nef_context.AfSubscription,
+	notifDest string,
+	eventNotifs []models_nef.EventNotification,
+) {
+	for i := range eventNotifs {
+		_, err := p.Notifier().TrafficInfluNotifier.NotifyUpPathChg(context.Background(), notifDest, &eventNotifs[i])
+
+		af.Mu.Lock()
+		sub.RecordNotifDelivery(err)
+		af.Mu.Unlock()
+	}
+}
+
+// sendUpPathChgNotificationsWithAck notifies the AF and waits for its acknowledgements,
+// which are returned either in the response of the notification or to the afAckUri.
+// The events are notified in parallel, and the ones not acknowledged before the
+// deadline are left out of the result.
+func (p *Processor) sendUpPathChgNotificationsWithAck(
+	af *nef_context.AfData,
+	sub *nef_context.AfSubscription,
+	notifID string,
+	notifDest string,
+	eventNotifs []models_nef.EventNotification,
+	timeout time.Duration,
+) []nef_models.AckOfNotify {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	results := make([]*nef_models.AckOfNotify, len(eventNotifs))
+	var wg sync.WaitGroup
+	for i := range eventNotifs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.sendUpPathChgNotificationWithAck(ctx, af, sub, notifID, notifDest, &eventNotifs[i])
+		}()
+	}
+	wg.Wait()
+
+	var acks []nef_models.AckOfNotify
+	for _, ack := range results {
+		if ack != nil {
+			acks = append(acks, *ack)
+		}
+	}
+	return acks
+}
+
+func (p *Processor) sendUpPathChgNotificationWithAck(
+	ctx context.Context,
+	af *nef_context.AfData,
+	sub *nef_context.AfSubscription,
+	notifID string,
+	notifDest string,
+	eventNotif *models_nef.EventNotification,
+) *nef_models.AckOfNotify {
+	nefCtx := p.Context()
+	ackID, ackCh := nefCtx.NewAfAckWaiter()
+	defer nefCtx.DeleteAfAckWaiter(ackID)
+	eventNotif.AfAckUri = p.genAfAckUri(ackID)
+
+	afAckInfo, err := p.Notifier().TrafficInfluNotifier.NotifyUpPathChg(ctx, notifDest, eventNotif)
+
+	af.Mu.Lock()
+	sub.RecordNotifDelivery(err)
+	af.Mu.Unlock()
+
+	if err == nil && afAckInfo == nil {
+		select {
+		case afAckInfo = <-ackCh:
+		case <-ctx.Done():
+			sub.Log.Warnln("AF acknowledgement is not received before the deadline")
+		}
+	}
+	if afAckInfo == nil {
+		return nil
+	}
+	sub.Log.Infof("AF acknowledgement: %s", afAckInfo.AckResult.AfStatus)
+	return &nef_models.AckOfNotify{
+		NotifId:   notifID,
+		AckResult: afAckInfo.AckResult,
+		Gpsi:      afAckInfo.Gpsi,
+	}
+}
+
+func (p *Processor) genAfAckUri(ackID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/af-ack/" + ackID
+}
+
+// TS 29.522 clause 5.4.3.3.3: The NEF maps the UP path change event received
+// from the SMF into the EventNotification towards the AF.
+func convertSmfEventNotificationToEventNotification(
+	tiSub *models_nef.TrafficInfluSub,
+	smfNotif *models.EventNotification,
+) *models_nef.EventNotification {
+	eventNotif := &models_nef.EventNotification{
+		AfTransId:          tiSub.AfTransId,
+		DnaiChgType:        smfNotif.DnaiChgType,
+		SourceTrafficRoute: smfNotif.SourceTraRouting,
+		SubscribedEvent:    models.SubscribedEvent_UP_PATH_CHANGE,
+		TargetTrafficRoute: smfNotif.TargetTraRouting,
+		SourceDnai:         smfNotif.SourceDnai,
+		TargetDnai:         smfNotif.TargetDnai,
+		Gpsi:               smfNotif.Gpsi,
+		SrcUeIpv4Addr:      smfNotif.SourceUeIpv4Addr,
+		SrcUeIpv6Prefix:    smfNotif.SourceUeIpv6Prefix,
+		TgtUeIpv4Addr:      smfNotif.TargetUeIpv4Addr,
+		TgtUeIpv6Prefix:    smfNotif.TargetUeIpv6Prefix,
+		UeMac:              smfNotif.UeMac,
+	}
+	if eventNotif.Gpsi == "" {
+		eventNotif.Gpsi = tiSub.Gpsi
+	}
+	return eventNotif
 }
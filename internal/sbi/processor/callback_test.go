@@ -0,0 +1,517 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/openapi/models_nef"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSmfNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	// Only remove the stub of this test, the NRF stubs set in TestMain are still needed by the others.
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/ti/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/ti/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	tiSub := tiSub3ForAf1
+	tiSub.AfTransId = "trans1"
+	tiSub.NotificationDestination = "http://127.0.0.100:8000/ti/notify"
+	tiSub.DnaiChgType = models.DnaiChangeType_EARLY
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub)
+	afSub1.AppSessID = "12345"
+	af1.Subs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	smfNotif := models.EventNotification{
+		Event:            models.SmfEvent_UP_PATH_CH,
+		DnaiChgType:      models.DnaiChangeType_EARLY,
+		SourceDnai:       "mec1",
+		TargetDnai:       "mec2",
+		SourceUeIpv4Addr: "10.60.0.10",
+		TargetUeIpv4Addr: "10.60.0.10",
+		TargetTraRouting: &models.RouteToLocation{
+			Dnai: "mec2",
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		eeNotif          *nef_models.NsmfEventExposureNotification
+		expectedResponse *HandlerResponse
+		expectedAfNotif  *models_nef.EventNotification
+	}{
+		{
+			description: "TC1: Subscription found, should forward the UP path change to AF",
+			eeNotif: &nef_models.NsmfEventExposureNotification{
+				NotifId: afSub1.NotifCorreID,
+				EventNotifs: []models.EventNotification{
+					smfNotif,
+					{
+						Event: models.SmfEvent_PDU_SES_REL,
+					},
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+			expectedAfNotif: &models_nef.EventNotification{
+				AfTransId:          "trans1",
+				DnaiChgType:        models.DnaiChangeType_EARLY,
+				SubscribedEvent:    models.SubscribedEvent_UP_PATH_CHANGE,
+				TargetTrafficRoute: smfNotif.TargetTraRouting,
+				SourceDnai:         "mec1",
+				TargetDnai:         "mec2",
+				SrcUeIpv4Addr:      "10.60.0.10",
+				TgtUeIpv4Addr:      "10.60.0.10",
+			},
+		},
+		{
+			description: "TC2: Subscription not found, should return ProblemDetails",
+			eeNotif: &nef_models.NsmfEventExposureNotification{
+				NotifId: "999",
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscrption is not found",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().SmfNotification(c, tc.eeNotif)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedAfNotif == nil {
+				return
+			}
+			select {
+			case r := <-afNotifChan:
+				var afNotif models_nef.EventNotification
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&afNotif))
+				require.Equal(t, *tc.expectedAfNotif, afNotif)
+			case <-time.After(time.Second):
+				t.Fatal("AF notification is not received")
+			}
+			require.Eventually(t, func() bool {
+				af1.Mu.RLock()
+				defer af1.Mu.RUnlock()
+				return afSub1.NotifStatus.NumSuccess == 1
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestSmfNotificationWithAfAck(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afAckInfo := models_nef.AfAckInfo{
+		AckResult: &models_nef.AfResultInfo{
+			AfStatus: models_nef.AfResultStatus_SUCCESS,
+		},
+	}
+	syncAckStub := gock.New("http://127.0.0.100:8000")
+	syncAckStub.Post("/ti/notify-sync").
+		Persist().
+		Reply(http.StatusOK).
+		JSON(afAckInfo)
+	defer gock.Remove(syncAckStub.Mock)
+	asyncAckStub := initAFNotificationStub("http://127.0.0.100:8000", "/ti/notify-async", http.StatusNoContent)
+	defer gock.Remove(asyncAckStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/ti/notify-async") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	tiSub1 := tiSub3ForAf1
+	tiSub1.AfAckInd = true
+	tiSub1.NotificationDestination = "http://127.0.0.100:8000/ti/notify-sync"
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub1)
+	af1.Subs[afSub1.SubID] = afSub1
+
+	tiSub2 := tiSub3ForAf1
+	tiSub2.AfAckInd = true
+	tiSub2.NotificationDestination = "http://127.0.0.100:8000/ti/notify-async"
+	afSub2 := af1.NewSub(nefCtx.NewCorreID(), &tiSub2)
+	af1.Subs[afSub2.SubID] = afSub2
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	testCases := []struct {
+		description      string
+		notifID          string
+		asyncAck         bool
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: AF acknowledges in the response, should return AckOfNotify to SMF",
+			notifID:     afSub1.NotifCorreID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body: &[]nef_models.AckOfNotify{
+					{
+						NotifId:   afSub1.NotifCorreID,
+						AckResult: afAckInfo.AckResult,
+					},
+				},
+			},
+		},
+		{
+			description: "TC2: AF acknowledges to the afAckUri, should return AckOfNotify to SMF",
+			notifID:     afSub2.NotifCorreID,
+			asyncAck:    true,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body: &[]nef_models.AckOfNotify{
+					{
+						NotifId:   afSub2.NotifCorreID,
+						AckResult: afAckInfo.AckResult,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if tc.asyncAck {
+				go func() {
+					r := <-afNotifChan
+					var afNotif models_nef.EventNotification
+					if err := json.NewDecoder(r.Body).Decode(&afNotif); err != nil {
+						t.Error(err)
+						return
+					}
+					ackID := afNotif.AfAckUri[strings.LastIndex(afNotif.AfAckUri, "/")+1:]
+
+					ackRecorder := httptest.NewRecorder()
+					ackCtx, _ := gin.CreateTestContext(ackRecorder)
+					nefApp.Processor().PostAfAck(ackCtx, ackID, &afAckInfo)
+					if ackRecorder.Code != http.StatusNoContent {
+						t.Errorf("PostAfAck returns %d", ackRecorder.Code)
+					}
+				}()
+			}
+
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().SmfNotification(c, &nef_models.NsmfEventExposureNotification{
+				NotifId: tc.notifID,
+				EventNotifs: []models.EventNotification{
+					{
+						Event:       models.SmfEvent_UP_PATH_CH,
+						DnaiChgType: models.DnaiChangeType_EARLY,
+						TargetDnai:  "mec2",
+					},
+				},
+			})
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+}
+
+func TestSmfNotificationWithAfAckDeadline(t *testing.T) {
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/ti/notify-noack", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+
+	cfg := nefApp.Config()
+	cfg.Configuration.AfAckTimeout = 200 * time.Millisecond
+	defer func() {
+		cfg.Configuration.AfAckTimeout = 0
+	}()
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	tiSub := tiSub3ForAf1
+	tiSub.AfAckInd = true
+	tiSub.NotificationDestination = "http://127.0.0.100:8000/ti/notify-noack"
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub)
+	af1.Subs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	eventNotifs := make([]models.EventNotification, 5)
+	for i := range eventNotifs {
+		eventNotifs[i] = models.EventNotification{
+			Event:       models.SmfEvent_UP_PATH_CH,
+			DnaiChgType: models.DnaiChangeType_EARLY,
+			TargetDnai:  "mec2",
+		}
+	}
+
+	// The AF never acknowledges, the SMF is answered after a single deadline
+	// rather than the timeout of every event in turn
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	start := time.Now()
+	nefApp.Processor().SmfNotification(c, &nef_models.NsmfEventExposureNotification{
+		NotifId:     afSub1.NotifCorreID,
+		EventNotifs: eventNotifs,
+	})
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+	require.Less(t, time.Since(start), 2*cfg.AfAckTimeout())
+
+	af1.Mu.RLock()
+	require.Equal(t, uint64(len(eventNotifs)), afSub1.NotifStatus.NumSuccess)
+	af1.Mu.RUnlock()
+}
+
+func TestPostAfAck(t *testing.T) {
+	testCases := []struct {
+		description      string
+		ackID            string
+		afAckInfo        *models_nef.AfAckInfo
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Absent of AckResult, should return ProblemDetails",
+			ackID:       "1",
+			afAckInfo:   &models_nef.AfAckInfo{},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of AfAckInfo.AckResult",
+				},
+			},
+		},
+		{
+			description: "TC2: Acknowledgement is not expected, should return ProblemDetails",
+			ackID:       "1",
+			afAckInfo: &models_nef.AfAckInfo{
+				AckResult: &models_nef.AfResultInfo{
+					AfStatus: models_nef.AfResultStatus_SUCCESS,
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "AF acknowledgement is not expected",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().PostAfAck(c, tc.ackID, tc.afAckInfo)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+}
+
+func TestInfluenceDataUpdateNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/ti/change", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	udrStub := initUDRDrDeleteInfluSubsStub("subs1")
+	defer gock.Remove(udrStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/ti/change") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	tiSub := tiSub1ForAf1
+	tiSub.NotificationDestination = "http://127.0.0.100:8000/ti/change"
+	tiSub.Self = nefApp.Processor().genTrafficInfluSubURI("af1", "1")
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub)
+	afSub1.InfluID = "influ1"
+	afSub1.InfluSubsID = "subs1"
+	af1.Subs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	resUri := "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/influ1"
+	tiData := nefApp.Processor().convertTrafficInfluSubToTrafficInfluData(&tiSub, afSub1.NotifCorreID, "", "")
+	modifiedTiData := *tiData
+	modifiedTiData.TrafficRoutes = []models.RouteToLocation{
+		{
+			Dnai: "mec2",
+		},
+	}
+	modifiedTiSub := tiSub
+	modifiedTiSub.TrafficRoutes = modifiedTiData.TrafficRoutes
+
+	testCases := []struct {
+		description      string
+		correID          string
+		influDataNotifs  []models.TrafficInfluDataNotif
+		expectedResponse *HandlerResponse
+		expectedAfNotif  *nef_models.TrafficInfluSubNotification
+	}{
+		{
+			description: "TC1: Subscription not found, should return ProblemDetails",
+			correID:     "999",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Influence data is written by NEF, should not notify AF",
+			correID:     afSub1.NotifCorreID,
+			influDataNotifs: []models.TrafficInfluDataNotif{
+				{
+					ResUri:           resUri,
+					TrafficInfluData: tiData,
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+		},
+		{
+			description: "TC3: Influence data is modified, should notify AF with the updated subscription",
+			correID:     afSub1.NotifCorreID,
+			influDataNotifs: []models.TrafficInfluDataNotif{
+				{
+					ResUri: "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/influ2",
+				},
+				{
+					ResUri:           resUri,
+					TrafficInfluData: &modifiedTiData,
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+			expectedAfNotif: &nef_models.TrafficInfluSubNotification{
+				Subscription:    tiSub.Self,
+				TrafficInfluSub: &modifiedTiSub,
+			},
+		},
+		{
+			description: "TC4: Influence data is removed, should cancel the subscription",
+			correID:     afSub1.NotifCorreID,
+			influDataNotifs: []models.TrafficInfluDataNotif{
+				{
+					ResUri: resUri,
+				},
+			},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+			expectedAfNotif: &nef_models.TrafficInfluSubNotification{
+				Subscription: tiSub.Self,
+				CancelInd:    true,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().InfluenceDataUpdateNotification(c, tc.correID, tc.influDataNotifs)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedAfNotif == nil {
+				select {
+				case <-afNotifChan:
+					t.Fatal("AF notification is not expected")
+				case <-time.After(100 * time.Millisecond):
+				}
+				return
+			}
+			select {
+			case r := <-afNotifChan:
+				var afNotif nef_models.TrafficInfluSubNotification
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&afNotif))
+				require.Equal(t, *tc.expectedAfNotif, afNotif)
+			case <-time.After(time.Second):
+				t.Fatal("AF notification is not received")
+			}
+		})
+	}
+
+	af1.Mu.RLock()
+	require.NotContains(t, af1.Subs, afSub1.SubID)
+	af1.Mu.RUnlock()
+	// The subscription to the changes is removed with the cancelled subscription
+	require.Eventually(t, udrStub.Done, time.Second, 10*time.Millisecond)
+}
+
+func initUDRDrDeleteInfluSubsStub(subsID string) gock.Mock {
+	req := gock.New("http://127.0.0.4:8000/nudr-dr/v1")
+	req.Delete("/application-data/influenceData/subs-to-notify/" + subsID).
+		Reply(http.StatusNoContent)
+	return req.Mock
+}
+
+func initAFNotificationStub(afUri, path string, statusCode int) gock.Mock {
+	req := gock.New(afUri)
+	req.Post(path).
+		Persist().
+		Reply(statusCode)
+	return req.Mock
+}
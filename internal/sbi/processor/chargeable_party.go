@@ -0,0 +1,477 @@
+package processor
+
+import (
+	"net/http"
+	"strconv"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+// The chargeable party transaction is mapped to a single media component
+const chgPartyMedCompN int32 = 1
+
+func (p *Processor) GetChargeablePartyTransactions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.ChgPartyLog.Infof("GetChargeablePartyTransactions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	chgParties := []nef_models.ChargeableParty{}
+	for _, cp := range af.ChgParties {
+		chgParties = append(chgParties, *cp.ChgParty)
+	}
+	c.JSON(http.StatusOK, &chgParties)
+}
+
+func (p *Processor) PostChargeablePartyTransaction(
+	c *gin.Context,
+	scsAsID string,
+	chgParty *nef_models.ChargeableParty,
+) {
+	logger.ChgPartyLog.Infof("PostChargeablePartyTransaction - scsAsID[%s]", scsAsID)
+
+	if rsp := validateChargeableParty(chgParty); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cp := af.NewChgParty(nefCtx.NewCorreID(), chgParty)
+	asc := p.convertChargeablePartyToAppSessionContext(chgParty, cp.NotifCorreID)
+	rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions("", asc)
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	cp.AppSessID = appSessID
+	chgParty.Self = p.genChargeablePartyURI(scsAsID, cp.TransID)
+
+	af.ChgParties[cp.TransID] = cp
+	af.Log.Infoln("Chargeable party transaction is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", chgParty.Self)
+	c.JSON(http.StatusCreated, chgParty)
+}
+
+func (p *Processor) GetIndividualChargeablePartyTransaction(
+	c *gin.Context,
+	scsAsID, transID string,
+) {
+	logger.ChgPartyLog.Infof("GetIndividualChargeablePartyTransaction - scsAsID[%s], transID[%s]",
+		scsAsID, transID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	cp, ok := af.ChgParties[transID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, cp.ChgParty)
+}
+
+func (p *Processor) PatchIndividualChargeablePartyTransaction(
+	c *gin.Context,
+	scsAsID, transID string,
+	chgPartyPatch *nef_models.ChargeablePartyPatch,
+) {
+	logger.ChgPartyLog.Infof("PatchIndividualChargeablePartyTransaction - scsAsID[%s], transID[%s]",
+		scsAsID, transID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cp, ok := af.ChgParties[transID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	// Validate the patched transaction before it's applied
+	chgParty := *cp.ChgParty
+	patchedCp := &nef_context.AfChargeableParty{ChgParty: &chgParty}
+	patchedCp.PatchChgPartyData(chgPartyPatch)
+	if rsp := validateChargeableParty(patchedCp.ChgParty); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	ascUpdateData := p.convertChargeablePartyToAppSessionContextUpdateData(
+		patchedCp.ChgParty, cp.NotifCorreID)
+	rspStatus, rspBody := p.Consumer().PatchAppSession("", cp.AppSessID, ascUpdateData)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	cp.ChgParty = patchedCp.ChgParty
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, cp.ChgParty)
+}
+
+func (p *Processor) DeleteIndividualChargeablePartyTransaction(
+	c *gin.Context,
+	scsAsID, transID string,
+) {
+	logger.ChgPartyLog.Infof("DeleteIndividualChargeablePartyTransaction - scsAsID[%s], transID[%s]",
+		scsAsID, transID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cp, ok := af.ChgParties[transID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().DeleteAppSession("", cp.AppSessID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.ChgParties, transID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PcfChgPartyEventNotification forwards the events of the sponsored app session
+// reported by the PCF, e.g. the usage report when the usage threshold is reached,
+// to the AF as user plane events (TS 29.122 clause 5.6.3.3.2).
+func (p *Processor) PcfChgPartyEventNotification(
+	c *gin.Context,
+	correID string,
+	evsNotif *models.EventsNotification,
+) {
+	logger.ChgPartyLog.Infof("PcfChgPartyEventNotification - correID[%s]", correID)
+
+	af, cp := p.Context().FindAfChgParty(correID)
+	if cp == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	notifDest := cp.ChgParty.NotificationDestination
+	upNotif := &nef_models.UserPlaneNotificationData{
+		Transaction:  cp.ChgParty.Self,
+		EventReports: convertEventsNotificationToUserPlaneEventReports(evsNotif, ""),
+	}
+	af.Mu.RUnlock()
+
+	if len(upNotif.EventReports) == 0 {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	go func() {
+		if err := p.Notifier().ChgPartyNotifier.NotifyUserPlaneEvent(notifDest, upNotif); err != nil {
+			cp.Log.Errorf("Notify user plane events failed: %+v", err)
+			return
+		}
+		cp.Log.Infof("User plane events are notified")
+	}()
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PcfChgPartyTerminationNotification handles the termination of the sponsored app
+// session requested by the PCF. The AF is notified of the SESSION_TERMINATION,
+// and then the app session and the transaction are deleted.
+func (p *Processor) PcfChgPartyTerminationNotification(
+	c *gin.Context,
+	correID string,
+	termInfo *models.TerminationInfo,
+) {
+	logger.ChgPartyLog.Infof("PcfChgPartyTerminationNotification - correID[%s], cause[%s]",
+		correID, termInfo.TermCause)
+
+	af, cp := p.Context().FindAfChgParty(correID)
+	if cp == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	go p.terminateChargeableParty(af, cp)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (p *Processor) terminateChargeableParty(
+	af *nef_context.AfData,
+	cp *nef_context.AfChargeableParty,
+) {
+	af.Mu.Lock()
+	if af.ChgParties[cp.TransID] != cp {
+		af.Mu.Unlock()
+		return
+	}
+	notifDest := cp.ChgParty.NotificationDestination
+	upNotif := &nef_models.UserPlaneNotificationData{
+		Transaction: cp.ChgParty.Self,
+		EventReports: []nef_models.UserPlaneEventReport{
+			{
+				Event: nef_models.UserPlaneEvent_SESSION_TERMINATION,
+			},
+		},
+	}
+	appSessID := cp.AppSessID
+	af.Mu.Unlock()
+
+	err := p.Notifier().ChgPartyNotifier.NotifyUserPlaneEvent(notifDest, upNotif)
+	if err != nil {
+		cp.Log.Errorf("Notify session termination failed: %+v", err)
+	}
+
+	rspStatus, _ := p.Consumer().DeleteAppSession("", appSessID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		cp.Log.Warnf("Delete app session[%s] failed: rspCode[%d]", appSessID, rspStatus)
+	}
+
+	// The transaction may be deleted by the AF in the meantime
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+	if af.ChgParties[cp.TransID] == cp {
+		delete(af.ChgParties, cp.TransID)
+		p.Context().StoreAf(af)
+	}
+	cp.Log.Infoln("Chargeable party transaction is terminated")
+}
+
+func validateChargeableParty(chgParty *nef_models.ChargeableParty) *HandlerResponse {
+	if chgParty.NotificationDestination == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notificationDestination")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	sponsorInfo := chgParty.SponsorInformation
+	if sponsorInfo == nil || sponsorInfo.SponsorId == "" || sponsorInfo.AspId == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of sponsorInformation")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	// TS 29.122: One of "ipv4Addr", "ipv6Addr" or "macAddr" shall be included.
+	numUeAddr := 0
+	for _, addr := range []string{chgParty.Ipv4Addr, chgParty.Ipv6Addr, chgParty.MacAddr} {
+		if addr != "" {
+			numUeAddr++
+		}
+	}
+	if numUeAddr != 1 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"One of ipv4Addr, ipv6Addr or macAddr shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	if chgParty.MacAddr != "" && len(chgParty.EthFlowInfo) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of ethFlowInfo for macAddr")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if chgParty.MacAddr == "" && len(chgParty.FlowInfo) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of flowInfo for UE IP address")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	for _, event := range chgParty.Events {
+		if _, ok := convertUserPlaneEventToAfEvent(event); !ok {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("Unsupported event: " + string(event))
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+	}
+	return nil
+}
+
+func (p *Processor) genChargeablePartyURI(scsAsID, transID string) string {
+	// E.g. https://localhost:29505/3gpp-chargeable-party/v1/{scsAsId}/transactions/{transactionId}
+	return p.Config().ServiceUri(factory.ServiceChgParty) + "/" + scsAsID + "/transactions/" + transID
+}
+
+// genPcfChgPartyNotificationUri returns the notifUri of the sponsored app session, the PCF sends
+// the event notifications to "{notifUri}/notify" and the termination to "{notifUri}/terminate".
+func (p *Processor) genPcfChgPartyNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/pcf-chg-party/" + notifCorreID
+}
+
+func (p *Processor) convertChargeablePartyToAppSessionContext(
+	chgParty *nef_models.ChargeableParty,
+	notifCorreID string,
+) *models.AppSessionContext {
+	medComp := convertChargeablePartyToMediaComponent(chgParty)
+	asc := &models.AppSessionContext{
+		AscReqData: &models.AppSessionContextReqData{
+			AfAppId:  chgParty.ExterAppId,
+			AspId:    chgParty.SponsorInformation.AspId,
+			BdtRefId: chgParty.ReferenceId,
+			Dnn:      chgParty.Dnn,
+			EvSubsc: &models.EventsSubscReqData{
+				Events:   genChgPartyAfEventSubscriptions(chgParty),
+				NotifUri: p.genPcfChgPartyNotificationUri(notifCorreID),
+				UsgThres: chgParty.UsageThreshold,
+			},
+			MedComponents: map[string]models.MediaComponent{
+				strconv.Itoa(int(medComp.MedCompN)): *medComp,
+			},
+			NotifUri:   p.genPcfChgPartyNotificationUri(notifCorreID),
+			SliceInfo:  chgParty.Snssai,
+			SponId:     chgParty.SponsorInformation.SponsorId,
+			SponStatus: convertSponsoringEnabledToSponsoringStatus(chgParty.SponsoringEnabled),
+			SuppFeat:   chgParty.SupportedFeatures,
+			UeIpv4:     chgParty.Ipv4Addr,
+			UeIpv6:     chgParty.Ipv6Addr,
+			UeMac:      chgParty.MacAddr,
+		},
+	}
+	return asc
+}
+
+func (p *Processor) convertChargeablePartyToAppSessionContextUpdateData(
+	chgParty *nef_models.ChargeableParty,
+	notifCorreID string,
+) *models.AppSessionContextUpdateData {
+	medComp := convertChargeablePartyToMediaComponent(chgParty)
+	ascUpdateData := &models.AppSessionContextUpdateData{
+		AfAppId:  chgParty.ExterAppId,
+		AspId:    chgParty.SponsorInformation.AspId,
+		BdtRefId: chgParty.ReferenceId,
+		EvSubsc: &models.EventsSubscReqDataRm{
+			Events:   genChgPartyAfEventSubscriptions(chgParty),
+			NotifUri: p.genPcfChgPartyNotificationUri(notifCorreID),
+			UsgThres: convertUsageThresholdToUsageThresholdRm(chgParty.UsageThreshold),
+		},
+		MedComponents: map[string]models.MediaComponentRm{
+			strconv.Itoa(int(medComp.MedCompN)): *convertMediaComponentToMediaComponentRm(medComp),
+		},
+		SponId:     chgParty.SponsorInformation.SponsorId,
+		SponStatus: convertSponsoringEnabledToSponsoringStatus(chgParty.SponsoringEnabled),
+	}
+	return ascUpdateData
+}
+
+func convertChargeablePartyToMediaComponent(
+	chgParty *nef_models.ChargeableParty,
+) *models.MediaComponent {
+	return &models.MediaComponent{
+		AfAppId:     chgParty.ExterAppId,
+		FStatus:     models.FlowStatus_ENABLED,
+		MedCompN:    chgPartyMedCompN,
+		MedSubComps: genMediaSubComponents(chgParty.FlowInfo, chgParty.EthFlowInfo),
+	}
+}
+
+func convertSponsoringEnabledToSponsoringStatus(enabled bool) models.SponsoringStatus {
+	if enabled {
+		return models.SponsoringStatus_ENABLED
+	}
+	return models.SponsoringStatus_DISABLED
+}
+
+// genChgPartyAfEventSubscriptions subscribes to the PCF events of the user plane
+// events requested by the AF. The usage report is always subscribed if the usage
+// threshold is provided, since the sponsor is charged by the usage.
+func genChgPartyAfEventSubscriptions(
+	chgParty *nef_models.ChargeableParty,
+) []models.AfEventSubscription {
+	var events []models.AfEventSubscription
+	subscribed := make(map[models.AfEvent]bool)
+	addEvent := func(afEvent models.AfEvent) {
+		if subscribed[afEvent] {
+			return
+		}
+		subscribed[afEvent] = true
+		events = append(events, models.AfEventSubscription{
+			Event:       afEvent,
+			NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+		})
+	}
+
+	for _, event := range chgParty.Events {
+		// The SESSION_TERMINATION is notified by the PCF without subscription
+		if afEvent, ok := convertUserPlaneEventToAfEvent(event); ok && afEvent != "" {
+			addEvent(afEvent)
+		}
+	}
+	if chgParty.UsageThreshold != nil {
+		addEvent(models.AfEvent_USAGE_REPORT)
+	}
+	return events
+}
+
+// convertUserPlaneEventToAfEvent returns the PCF event of the user plane event,
+// the AfEvent is empty for the SESSION_TERMINATION.
+func convertUserPlaneEventToAfEvent(event nef_models.UserPlaneEvent) (models.AfEvent, bool) {
+	switch event {
+	case nef_models.UserPlaneEvent_SESSION_TERMINATION:
+		return "", true
+	case nef_models.UserPlaneEvent_USAGE_REPORT:
+		return models.AfEvent_USAGE_REPORT, true
+	case nef_models.UserPlaneEvent_QOS_GUARANTEED,
+		nef_models.UserPlaneEvent_QOS_NOT_GUARANTEED:
+		return models.AfEvent_QOS_NOTIF, true
+	case nef_models.UserPlaneEvent_SUCCESSFUL_RESOURCES_ALLOCATION:
+		return models.AfEvent_SUCCESSFUL_RESOURCES_ALLOCATION, true
+	case nef_models.UserPlaneEvent_FAILED_RESOURCES_ALLOCATION:
+		return models.AfEvent_FAILED_RESOURCES_ALLOCATION, true
+	case nef_models.UserPlaneEvent_ACCESS_TYPE_CHANGE:
+		return models.AfEvent_ACCESS_TYPE_CHANGE, true
+	case nef_models.UserPlaneEvent_PLMN_CHG:
+		return models.AfEvent_PLMN_CHG, true
+	default:
+		return "", false
+	}
+}
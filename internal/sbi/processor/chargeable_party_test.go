@@ -0,0 +1,289 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var chgParty1ForAf1 = nef_models.ChargeableParty{
+	Dnn:                     "internet",
+	NotificationDestination: "http://127.0.0.100:8000/cp/notify",
+	FlowInfo: []models.FlowInfo{
+		{
+			FlowId: 1,
+			FlowDescriptions: []string{
+				"permit out ip from 10.60.0.1 to 10.60.0.0/16",
+			},
+		},
+	},
+	Ipv4Addr: "10.60.0.1",
+	SponsorInformation: &nef_models.SponsorInformation{
+		SponsorId: "sponsor1",
+		AspId:     "asp1",
+	},
+	SponsoringEnabled: true,
+	UsageThreshold: &models.UsageThreshold{
+		TotalVolume: 1000000,
+	},
+}
+
+func TestPostChargeablePartyTransaction(t *testing.T) {
+	pcfReqChan := make(chan *http.Request, 1)
+	initNRFDiscPCFStub()
+	// Only remove the stubs of this test, the NRF stubs set in TestMain are still needed by the others.
+	pcfStub := initPCFPaAppSessionStub(http.MethodPost, "/app-sessions", http.StatusCreated)
+	defer gock.Remove(pcfStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.HasSuffix(request.URL.Path, "/app-sessions") {
+			pcfReqChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	rspChgParty1 := chgParty1ForAf1
+	rspChgParty1.Self = nefApp.Processor().genChargeablePartyURI("af1", "1")
+
+	chgPartyNoSponsor := chgParty1ForAf1
+	chgPartyNoSponsor.SponsorInformation = &nef_models.SponsorInformation{
+		SponsorId: "sponsor1",
+	}
+
+	chgPartyNoFlow := chgParty1ForAf1
+	chgPartyNoFlow.FlowInfo = nil
+
+	chgPartyUnknownEvent := chgParty1ForAf1
+	chgPartyUnknownEvent.Events = []nef_models.UserPlaneEvent{"UNKNOWN_EVENT"}
+
+	testCases := []struct {
+		description      string
+		chgParty         nef_models.ChargeableParty
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful transaction, should post sponsored AppSession to PCF",
+			chgParty:    chgParty1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspChgParty1.Self},
+				},
+				Body: &rspChgParty1,
+			},
+		},
+		{
+			description: "TC2: Absent of aspId",
+			chgParty:    chgPartyNoSponsor,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of sponsorInformation",
+				},
+			},
+		},
+		{
+			description: "TC3: Absent of flowInfo",
+			chgParty:    chgPartyNoFlow,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of flowInfo for UE IP address",
+				},
+			},
+		},
+		{
+			description: "TC4: Unsupported event",
+			chgParty:    chgPartyUnknownEvent,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unsupported event: UNKNOWN_EVENT",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			chgParty := tc.chgParty
+			nefApp.Processor().PostChargeablePartyTransaction(c, "af1", &chgParty)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	select {
+	case req := <-pcfReqChan:
+		var asc models.AppSessionContext
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&asc))
+		require.Equal(t, "sponsor1", asc.AscReqData.SponId)
+		require.Equal(t, "asp1", asc.AscReqData.AspId)
+		require.Equal(t, models.SponsoringStatus_ENABLED, asc.AscReqData.SponStatus)
+		require.Equal(t, []models.AfEventSubscription{
+			{
+				Event:       models.AfEvent_USAGE_REPORT,
+				NotifMethod: models.AfNotifMethod_EVENT_DETECTION,
+			},
+		}, asc.AscReqData.EvSubsc.Events)
+	case <-time.After(time.Second):
+		t.Fatal("AppSession is not posted to PCF")
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "12345", af.ChgParties["1"].AppSessID)
+	af.Mu.RUnlock()
+}
+
+func TestPatchAndDeleteChargeablePartyTransaction(t *testing.T) {
+	pcfReqChan := make(chan *http.Request, 1)
+	initNRFDiscPCFStub()
+	patchStub := initPCFPaAppSessionStub(http.MethodPatch, "/app-sessions/12345", http.StatusOK)
+	defer gock.Remove(patchStub)
+	deleteStub := initPCFPaAppSessionStub(http.MethodPost, "/app-sessions/12345/delete", http.StatusNoContent)
+	defer gock.Remove(deleteStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.Method == http.MethodPatch {
+			pcfReqChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	chgParty := chgParty1ForAf1
+	cp := af1.NewChgParty(nefCtx.NewCorreID(), &chgParty)
+	cp.AppSessID = "12345"
+	chgParty.Self = nefApp.Processor().genChargeablePartyURI("af1", cp.TransID)
+	af1.ChgParties[cp.TransID] = cp
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	sponsoringDisabled := false
+	rspChgParty := chgParty
+	rspChgParty.SponsoringEnabled = false
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().PatchIndividualChargeablePartyTransaction(c, "af1", cp.TransID,
+		&nef_models.ChargeablePartyPatch{
+			SponsoringEnabled: &sponsoringDisabled,
+		})
+	require.Equal(t, http.StatusOK, httpRecorder.Code)
+	assertJSONBodyEqual(t, &rspChgParty, httpRecorder.Body.Bytes())
+
+	select {
+	case req := <-pcfReqChan:
+		var ascUpdateData models.AppSessionContextUpdateData
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&ascUpdateData))
+		require.Equal(t, models.SponsoringStatus_DISABLED, ascUpdateData.SponStatus)
+	case <-time.After(time.Second):
+		t.Fatal("AppSession is not patched in PCF")
+	}
+
+	httpRecorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().DeleteIndividualChargeablePartyTransaction(c, "af1", cp.TransID)
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	af1.Mu.RLock()
+	require.NotContains(t, af1.ChgParties, cp.TransID)
+	af1.Mu.RUnlock()
+}
+
+func TestPcfChgPartyEventNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/cp/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/cp/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	chgParty := chgParty1ForAf1
+	chgParty.Self = "http://127.0.0.5:8000/3gpp-chargeable-party/v1/af1/transactions/1"
+	cp := af1.NewChgParty(nefCtx.NewCorreID(), &chgParty)
+	cp.AppSessID = "12345"
+	af1.ChgParties[cp.TransID] = cp
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	usgRep := &models.AccumulatedUsage{
+		TotalVolume: 1000000,
+	}
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().PcfChgPartyEventNotification(c, cp.NotifCorreID, &models.EventsNotification{
+		EvNotifs: []models.AfEventNotification{
+			{
+				Event: models.AfEvent_USAGE_REPORT,
+			},
+		},
+		UsgRep: usgRep,
+	})
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	select {
+	case req := <-afNotifChan:
+		var upNotif nef_models.UserPlaneNotificationData
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&upNotif))
+		require.Equal(t, nef_models.UserPlaneNotificationData{
+			Transaction: chgParty.Self,
+			EventReports: []nef_models.UserPlaneEventReport{
+				{
+					Event:            nef_models.UserPlaneEvent_USAGE_REPORT,
+					AccumulatedUsage: usgRep,
+				},
+			},
+		}, upNotif)
+	case <-time.After(time.Second):
+		t.Fatal("AF is not notified")
+	}
+
+	httpRecorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().PcfChgPartyEventNotification(c, "99", &models.EventsNotification{})
+	require.Equal(t, http.StatusNotFound, httpRecorder.Code)
+}
@@ -0,0 +1,262 @@
+package processor
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/internal/smsc"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func (p *Processor) GetDeviceTriggeringTransactions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.DevTrigLog.Infof("GetDeviceTriggeringTransactions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	devTrigs := []nef_models.DeviceTriggering{}
+	for _, dt := range af.DevTrigs {
+		devTrigs = append(devTrigs, *dt.DevTrig)
+	}
+	c.JSON(http.StatusOK, &devTrigs)
+}
+
+func (p *Processor) PostDeviceTriggeringTransaction(
+	c *gin.Context,
+	scsAsID string,
+	devTrig *nef_models.DeviceTriggering,
+) {
+	logger.DevTrigLog.Infof("PostDeviceTriggeringTransaction - scsAsID[%s]", scsAsID)
+
+	if rsp := validateDeviceTriggering(devTrig); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	devTrig.DeliveryResult = ""
+
+	gpsi := genGpsi(devTrig.ExternalId, devTrig.Msisdn)
+	supi, rsp := p.translateGpsiToSupi(gpsi)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	dt := af.NewDevTrig(nefCtx.NewCorreID(), devTrig)
+	dt.Supi = supi
+	devTrig.Self = p.genDeviceTriggeringURI(scsAsID, dt.TransID)
+
+	// The transaction is added before the message is submitted, so that
+	// the delivery report can find it however soon it comes
+	af.DevTrigs[dt.TransID] = dt
+	nefCtx.AddAf(af)
+
+	msg := &smsc.TriggerMessage{
+		ID:             dt.MsgID,
+		Supi:           dt.Supi,
+		Gpsi:           gpsi,
+		ValidityPeriod: time.Duration(devTrig.ValidityPeriod) * time.Second,
+		Priority:       devTrig.Priority == nef_models.Priority_PRIORITY,
+		AppPortID:      devTrig.ApplicationPortId,
+		AppSrcPortID:   devTrig.AppSrcPortId,
+		Payload:        devTrig.TriggerPayload,
+	}
+	rspDevTrig := *devTrig
+	af.Mu.Unlock()
+
+	if err := p.Smsc().Submit(msg, p.SmscDeliveryReport); err != nil {
+		dt.Log.Errorf("Submit trigger message failed: %+v", err)
+		af.Mu.Lock()
+		delete(af.DevTrigs, dt.TransID)
+		nefCtx.StoreAf(af)
+		af.Mu.Unlock()
+		pd := openapi.ProblemDetailsSystemFailure(err.Error())
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	af.Log.Infoln("Device triggering transaction is added")
+
+	c.Header("Location", rspDevTrig.Self)
+	c.JSON(http.StatusCreated, &rspDevTrig)
+}
+
+func (p *Processor) GetIndividualDeviceTriggeringTransaction(
+	c *gin.Context,
+	scsAsID, transID string,
+) {
+	logger.DevTrigLog.Infof("GetIndividualDeviceTriggeringTransaction - scsAsID[%s], transID[%s]",
+		scsAsID, transID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	dt, ok := af.DevTrigs[transID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, dt.DevTrig)
+}
+
+// DeleteIndividualDeviceTriggeringTransaction recalls the trigger message if it's not
+// delivered yet, and deletes the transaction.
+func (p *Processor) DeleteIndividualDeviceTriggeringTransaction(
+	c *gin.Context,
+	scsAsID, transID string,
+) {
+	logger.DevTrigLog.Infof("DeleteIndividualDeviceTriggeringTransaction - scsAsID[%s], transID[%s]",
+		scsAsID, transID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	dt, ok := af.DevTrigs[transID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Transaction is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if dt.DevTrig.DeliveryResult == "" {
+		err := p.Smsc().Recall(dt.MsgID)
+		switch {
+		case err == nil:
+			dt.Log.Infoln("Trigger message is recalled")
+		case errors.Is(err, smsc.ErrMessageNotFound):
+			// The delivery report is on the way
+		default:
+			dt.Log.Errorf("Recall trigger message failed: %+v", err)
+			pd := openapi.ProblemDetailsSystemFailure(err.Error())
+			c.JSON(int(pd.Status), pd)
+			return
+		}
+	}
+
+	delete(af.DevTrigs, transID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// SmscDeliveryReport updates the transaction with the delivery result reported
+// by the SMS-SC, and notifies the AF of it.
+func (p *Processor) SmscDeliveryReport(report *smsc.DeliveryReport) {
+	logger.DevTrigLog.Infof("SmscDeliveryReport - msgID[%s], result[%s]", report.ID, report.Result)
+
+	af, dt := p.Context().FindAfDevTrig(report.ID)
+	if dt == nil {
+		logger.DevTrigLog.Warnf("Transaction of trigger message[%s] is not found", report.ID)
+		return
+	}
+
+	af.Mu.Lock()
+	if _, ok := af.DevTrigs[dt.TransID]; !ok {
+		af.Mu.Unlock()
+		return
+	}
+	dt.DevTrig.DeliveryResult = convertSmscDeliveryResult(report.Result)
+	notifDest := dt.DevTrig.NotificationDestination
+	drNotif := &nef_models.DeviceTriggeringDeliveryReportNotification{
+		Transaction: dt.DevTrig.Self,
+		Result:      dt.DevTrig.DeliveryResult,
+	}
+	p.Context().StoreAf(af)
+	af.Mu.Unlock()
+
+	if err := p.Notifier().DevTrigNotifier.NotifyDeliveryReport(notifDest, drNotif); err != nil {
+		dt.Log.Errorf("Notify delivery report failed: %+v", err)
+		return
+	}
+	dt.Log.Infof("Delivery report[%s] is notified", drNotif.Result)
+}
+
+func validateDeviceTriggering(devTrig *nef_models.DeviceTriggering) *HandlerResponse {
+	if devTrig.NotificationDestination == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notificationDestination")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if (devTrig.ExternalId == "") == (devTrig.Msisdn == "") {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("One of externalId or msisdn shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if len(devTrig.TriggerPayload) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of triggerPayload")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if devTrig.ValidityPeriod <= 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid validityPeriod")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if devTrig.Priority != nef_models.Priority_NO_PRIORITY &&
+		devTrig.Priority != nef_models.Priority_PRIORITY {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid priority")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if devTrig.ApplicationPortId <= 0 || devTrig.ApplicationPortId > 65535 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid applicationPortId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+// genGpsi returns the GPSI of the UE identified by the externalId or msisdn (TS 29.571 clause 5.3.2)
+func genGpsi(externalID, msisdn string) string {
+	if externalID != "" {
+		return "extid-" + externalID
+	}
+	return "msisdn-" + msisdn
+}
+
+func (p *Processor) genDeviceTriggeringURI(scsAsID, transID string) string {
+	// E.g. https://localhost:29505/3gpp-device-triggering/v1/{scsAsId}/transactions/{transactionId}
+	return p.Config().ServiceUri(factory.ServiceDevTrig) + "/" + scsAsID + "/transactions/" + transID
+}
+
+func convertSmscDeliveryResult(result smsc.DeliveryResult) nef_models.DeliveryResult {
+	switch result {
+	case smsc.DeliveryResultSuccess:
+		return nef_models.DeliveryResult_SUCCESS
+	case smsc.DeliveryResultFailure:
+		return nef_models.DeliveryResult_FAILURE
+	case smsc.DeliveryResultExpired:
+		return nef_models.DeliveryResult_EXPIRED
+	default:
+		return nef_models.DeliveryResult_UNKNOWN
+	}
+}
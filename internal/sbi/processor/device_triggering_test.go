@@ -0,0 +1,209 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/internal/smsc"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var devTrig1ForAf1 = nef_models.DeviceTriggering{
+	ExternalId:              "ue1@free5gc.org",
+	ValidityPeriod:          60,
+	Priority:                nef_models.Priority_NO_PRIORITY,
+	ApplicationPortId:       5000,
+	TriggerPayload:          []byte("wake up"),
+	NotificationDestination: "http://127.0.0.100:8000/dt/notify",
+}
+
+func TestPostDeviceTriggeringTransaction(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	// Only remove the stubs of this test, the NRF stubs set in TestMain are still needed by the others.
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/dt/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/dt/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_SDM, "127.0.0.3")
+	udmStub := initUDMSdmIdTranslationStub("extid-ue1@free5gc.org", "imsi-208930000000001")
+	defer gock.Remove(udmStub)
+	udmUnknownStub := initUDMSdmIdTranslationStub("msisdn-886900000000", "")
+	defer gock.Remove(udmUnknownStub)
+	// The delivery report may come before the response of the transaction
+	nefApp.smsc.SetDelivery(smsc.DeliveryResultSuccess, 0)
+
+	rspDevTrig1 := devTrig1ForAf1
+	rspDevTrig1.Self = nefApp.Processor().genDeviceTriggeringURI("af1", "1")
+
+	devTrigUnknownUe := devTrig1ForAf1
+	devTrigUnknownUe.ExternalId = ""
+	devTrigUnknownUe.Msisdn = "886900000000"
+
+	devTrigNoPayload := devTrig1ForAf1
+	devTrigNoPayload.TriggerPayload = nil
+
+	devTrigInvalidPort := devTrig1ForAf1
+	devTrigInvalidPort.ApplicationPortId = 70000
+
+	testCases := []struct {
+		description      string
+		devTrig          nef_models.DeviceTriggering
+		expectedResponse *HandlerResponse
+		expectedNotif    *nef_models.DeviceTriggeringDeliveryReportNotification
+	}{
+		{
+			description: "TC1: Successful transaction, should submit the trigger to SMS-SC",
+			devTrig:     devTrig1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspDevTrig1.Self},
+				},
+				Body: &rspDevTrig1,
+			},
+			expectedNotif: &nef_models.DeviceTriggeringDeliveryReportNotification{
+				Transaction: rspDevTrig1.Self,
+				Result:      nef_models.DeliveryResult_SUCCESS,
+			},
+		},
+		{
+			description: "TC2: Unknown UE",
+			devTrig:     devTrigUnknownUe,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Title:  "Data not found",
+					Status: http.StatusNotFound,
+					Detail: "GPSI is not found",
+					Cause:  "USER_NOT_FOUND",
+				},
+			},
+		},
+		{
+			description: "TC3: Absent of triggerPayload",
+			devTrig:     devTrigNoPayload,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of triggerPayload",
+				},
+			},
+		},
+		{
+			description: "TC4: Invalid applicationPortId",
+			devTrig:     devTrigInvalidPort,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Invalid applicationPortId",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			devTrig := tc.devTrig
+			nefApp.Processor().PostDeviceTriggeringTransaction(c, "af1", &devTrig)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedNotif == nil {
+				return
+			}
+			select {
+			case req := <-afNotifChan:
+				var drNotif nef_models.DeviceTriggeringDeliveryReportNotification
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&drNotif))
+				require.Equal(t, *tc.expectedNotif, drNotif)
+			case <-time.After(time.Second):
+				t.Fatal("AF is not notified")
+			}
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "imsi-208930000000001", af.DevTrigs["1"].Supi)
+	require.Equal(t, nef_models.DeliveryResult_SUCCESS, af.DevTrigs["1"].DevTrig.DeliveryResult)
+	af.Mu.RUnlock()
+}
+
+func TestDeleteDeviceTriggeringTransaction(t *testing.T) {
+	// The trigger message is pending in SMS-SC until it's recalled
+	nefApp.smsc.SetDelivery(smsc.DeliveryResultSuccess, time.Hour)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	devTrig := devTrig1ForAf1
+	dt := af1.NewDevTrig(nefCtx.NewCorreID(), &devTrig)
+	af1.DevTrigs[dt.TransID] = dt
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+	require.NoError(t, nefApp.Smsc().Submit(&smsc.TriggerMessage{ID: dt.MsgID}, nefApp.Processor().SmscDeliveryReport))
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().DeleteIndividualDeviceTriggeringTransaction(c, "af1", dt.TransID)
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	af1.Mu.RLock()
+	require.NotContains(t, af1.DevTrigs, dt.TransID)
+	af1.Mu.RUnlock()
+	require.ErrorIs(t, nefApp.Smsc().Recall(dt.MsgID), smsc.ErrMessageNotFound)
+}
+
+// initUDMSdmIdTranslationStub replies USER_NOT_FOUND if supi is empty
+func initUDMSdmIdTranslationStub(gpsi, supi string) gock.Mock {
+	req := gock.New("http://127.0.0.3:8000/nudm-sdm/v1")
+	rsp := req.Get("/" + gpsi + "/id-translation-result").
+		Persist()
+	if supi == "" {
+		rsp.Reply(http.StatusNotFound).
+			JSON(models.ProblemDetails{
+				Status: http.StatusNotFound,
+				Cause:  "USER_NOT_FOUND",
+			})
+	} else {
+		rsp.Reply(http.StatusOK).
+			JSON(models.IdTranslationResult{
+				Supi: supi,
+				Gpsi: gpsi,
+			})
+	}
+	return req.Mock
+}
@@ -0,0 +1,261 @@
+package processor
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (p *Processor) Get5GLanParametersProvisions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.PpLog.Infof("Get5GLanParametersProvisions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	lanParamsList := []nef_models.FiveGLanParametersProvision{}
+	for _, sub := range af.LanSubs {
+		lanParamsList = append(lanParamsList, *sub.LanParams)
+	}
+	c.JSON(http.StatusOK, &lanParamsList)
+}
+
+// Post5GLanParametersProvision provisions the 5G LAN group as a 5G VN group in the UDM
+func (p *Processor) Post5GLanParametersProvision(
+	c *gin.Context,
+	scsAsID string,
+	lanParams *nef_models.FiveGLanParametersProvision,
+) {
+	logger.PpLog.Infof("Post5GLanParametersProvision - scsAsID[%s]", scsAsID)
+
+	if rsp := validate5GLanParametersProvision(lanParams); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub := af.NewLanSub(lanParams)
+	// The 5G VN group in the UDM would be replaced by another subscription
+	if !nefCtx.ClaimUdmData(sub.UdmDataKey(), scsAsID, sub.SubID) {
+		pd := problemDetailsForbidden("5G LAN group is provisioned by another subscription")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	rspStatus, rspBody := p.Consumer().Put5GVnGroup(lanParams.FiveGLanParams.ExterGroupId,
+		convert5GLanParamsTo5GVnGroupConfiguration(scsAsID, sub.SubID, lanParams.FiveGLanParams))
+	if rspStatus != http.StatusCreated &&
+		rspStatus != http.StatusNoContent {
+		nefCtx.ReleaseUdmData(sub.UdmDataKey(), scsAsID, sub.SubID)
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	lanParams.Self = p.gen5GLanParametersProvisionSubURI(scsAsID, sub.SubID)
+
+	af.LanSubs[sub.SubID] = sub
+	af.Log.Infoln("5G LAN parameters provision subscription is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", lanParams.Self)
+	c.JSON(http.StatusCreated, lanParams)
+}
+
+func (p *Processor) GetIndividual5GLanParametersProvision(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.PpLog.Infof("GetIndividual5GLanParametersProvision - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	sub, ok := af.LanSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub.LanParams)
+}
+
+func (p *Processor) PutIndividual5GLanParametersProvision(
+	c *gin.Context,
+	scsAsID, subID string,
+	lanParams *nef_models.FiveGLanParametersProvision,
+) {
+	logger.PpLog.Infof("PutIndividual5GLanParametersProvision - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	if rsp := validate5GLanParametersProvision(lanParams); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.LanSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if lanParams.FiveGLanParams.ExterGroupId != sub.LanParams.FiveGLanParams.ExterGroupId {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("exterGroupId can't be changed")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().Put5GVnGroup(lanParams.FiveGLanParams.ExterGroupId,
+		convert5GLanParamsTo5GVnGroupConfiguration(scsAsID, sub.SubID, lanParams.FiveGLanParams))
+	if rspStatus != http.StatusCreated &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	lanParams.Self = sub.LanParams.Self
+	sub.LanParams = lanParams
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, sub.LanParams)
+}
+
+func (p *Processor) DeleteIndividual5GLanParametersProvision(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.PpLog.Infof("DeleteIndividual5GLanParametersProvision - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.LanSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().Delete5GVnGroup(sub.LanParams.FiveGLanParams.ExterGroupId)
+	if rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.LanSubs, subID)
+	p.Context().ReleaseUdmData(sub.UdmDataKey(), scsAsID, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func validate5GLanParametersProvision(lanParams *nef_models.FiveGLanParametersProvision) *HandlerResponse {
+	params := lanParams.FiveGLanParams
+	if params == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of 5gLanParams")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if params.ExterGroupId == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of exterGroupId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if len(params.Gpsis) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of gpsis")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if params.Dnn == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of dnn")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if params.Snssai == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of snssai")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	switch params.SessionType {
+	case "", models.PduSessionType_IPV4, models.PduSessionType_IPV6,
+		models.PduSessionType_IPV4_V6, models.PduSessionType_UNSTRUCTURED,
+		models.PduSessionType_ETHERNET:
+	default:
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid sessionType")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) gen5GLanParametersProvisionSubURI(scsAsID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-5glan-pp/v1/{scsAsId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.Service5GLanPp) + "/" + scsAsID + "/subscriptions/" + subID
+}
+
+func convert5GLanParamsTo5GVnGroupConfiguration(
+	scsAsID, subID string,
+	params *nef_models.FiveGLanParameters,
+) *nef_models.FiveGVnGroupConfiguration {
+	refID, err := strconv.ParseInt(subID, 10, 32)
+	if err != nil {
+		logger.PpLog.Warnf("Invalid reference ID[%s]: %+v", subID, err)
+	}
+
+	vnGroupCfg := &nef_models.FiveGVnGroupConfiguration{
+		FiveGVnGroupData: &nef_models.FiveGVnGroupData{
+			Dnn:    params.Dnn,
+			SNssai: params.Snssai,
+		},
+		ReferenceId:  int32(refID),
+		AfInstanceId: scsAsID,
+	}
+	if params.SessionType != "" {
+		vnGroupCfg.FiveGVnGroupData.PduSessionTypes = []models.PduSessionType{params.SessionType}
+	}
+	for _, gpsi := range params.Gpsis {
+		vnGroupCfg.Members = append(vnGroupCfg.Members, gpsi)
+	}
+	// Keep the members in order since the GPSIs are provided in a map
+	sort.Strings(vnGroupCfg.Members)
+	return vnGroupCfg
+}
@@ -0,0 +1,226 @@
+package processor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var lanParams1ForAf1 = nef_models.FiveGLanParametersProvision{
+	FiveGLanParams: &nef_models.FiveGLanParameters{
+		ExterGroupId: "lan1@free5gc.org",
+		Gpsis: map[string]string{
+			"ue1": "msisdn-0900000001",
+			"ue2": "msisdn-0900000002",
+		},
+		Dnn: "internet",
+		Snssai: &models.Snssai{
+			Sst: 1,
+			Sd:  "010203",
+		},
+		SessionType: models.PduSessionType_ETHERNET,
+	},
+}
+
+func TestPost5GLanParametersProvision(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_PP, "127.0.0.3")
+	udmStub := initUDM5GVnGroupPutStub("lan1@free5gc.org", http.StatusCreated)
+	defer gock.Remove(udmStub)
+	udmReqChan := make(chan *http.Request, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/5g-vn-groups/") {
+			udmReqChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	rspLanParams1 := lanParams1ForAf1
+	rspLanParams1.Self = nefApp.Processor().gen5GLanParametersProvisionSubURI("af1", "1")
+
+	lanParamsNoGpsis := lanParams1ForAf1
+	lanParamsNoGpsis.FiveGLanParams = &nef_models.FiveGLanParameters{
+		ExterGroupId: "lan1@free5gc.org",
+		Dnn:          "internet",
+	}
+
+	testCases := []struct {
+		description        string
+		lanParams          nef_models.FiveGLanParametersProvision
+		expectedResponse   *HandlerResponse
+		expectedVnGroupCfg *nef_models.FiveGVnGroupConfiguration
+	}{
+		{
+			description: "TC1: Successful provision, should create 5G VN group in UDM",
+			lanParams:   lanParams1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspLanParams1.Self},
+				},
+				Body: &rspLanParams1,
+			},
+			expectedVnGroupCfg: &nef_models.FiveGVnGroupConfiguration{
+				FiveGVnGroupData: &nef_models.FiveGVnGroupData{
+					Dnn:             "internet",
+					SNssai:          lanParams1ForAf1.FiveGLanParams.Snssai,
+					PduSessionTypes: []models.PduSessionType{models.PduSessionType_ETHERNET},
+				},
+				Members:      []string{"msisdn-0900000001", "msisdn-0900000002"},
+				ReferenceId:  1,
+				AfInstanceId: "af1",
+			},
+		},
+		{
+			description: "TC2: Absent of gpsis",
+			lanParams:   lanParamsNoGpsis,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of gpsis",
+				},
+			},
+		},
+		{
+			description: "TC3: Group provisioned by another subscription",
+			lanParams:   lanParams1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusForbidden,
+				Body: &models.ProblemDetails{
+					Title:  "Forbidden",
+					Status: http.StatusForbidden,
+					Detail: "5G LAN group is provisioned by another subscription",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			lanParams := tc.lanParams
+			nefApp.Processor().Post5GLanParametersProvision(c, "af1", &lanParams)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedVnGroupCfg != nil {
+				select {
+				case req := <-udmReqChan:
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					assertJSONBodyEqual(t, tc.expectedVnGroupCfg, body)
+				case <-time.After(time.Second):
+					t.Fatal("UDM is not requested")
+				}
+			}
+		})
+	}
+}
+
+func TestPutIndividual5GLanParametersProvision(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_PP, "127.0.0.3")
+	udmStub := initUDM5GVnGroupPutStub("lan1@free5gc.org", http.StatusNoContent)
+	defer gock.Remove(udmStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	lanParams := lanParams1ForAf1
+	sub := af1.NewLanSub(&lanParams)
+	lanParams.Self = nefApp.Processor().gen5GLanParametersProvisionSubURI("af1", sub.SubID)
+	af1.LanSubs[sub.SubID] = sub
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	lanParamsNewGroup := lanParams1ForAf1
+	lanParamsNewGroup.FiveGLanParams = &nef_models.FiveGLanParameters{
+		ExterGroupId: "lan2@free5gc.org",
+		Gpsis:        lanParams1ForAf1.FiveGLanParams.Gpsis,
+		Dnn:          "internet",
+		Snssai:       lanParams1ForAf1.FiveGLanParams.Snssai,
+	}
+
+	lanParamsOneMember := lanParams1ForAf1
+	lanParamsOneMember.FiveGLanParams = &nef_models.FiveGLanParameters{
+		ExterGroupId: "lan1@free5gc.org",
+		Gpsis: map[string]string{
+			"ue1": "msisdn-0900000001",
+		},
+		Dnn:    "internet",
+		Snssai: lanParams1ForAf1.FiveGLanParams.Snssai,
+	}
+	rspLanParams := lanParamsOneMember
+	rspLanParams.Self = lanParams.Self
+
+	testCases := []struct {
+		description      string
+		lanParams        nef_models.FiveGLanParametersProvision
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: External group ID is changed",
+			lanParams:   lanParamsNewGroup,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "exterGroupId can't be changed",
+				},
+			},
+		},
+		{
+			description: "TC2: Successful update, should update 5G VN group in UDM",
+			lanParams:   lanParamsOneMember,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &rspLanParams,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			lanParams := tc.lanParams
+			nefApp.Processor().PutIndividual5GLanParametersProvision(c, "af1", sub.SubID, &lanParams)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+}
+
+func initUDM5GVnGroupPutStub(extGroupID string, statusCode int) gock.Mock {
+	req := gock.New("http://127.0.0.3:8000/nudm-pp/v1")
+	req.Put("/5g-vn-groups/" + extGroupID).
+		Persist().
+		Reply(statusCode)
+	return req.Mock
+}
@@ -0,0 +1,556 @@
+package processor
+
+import (
+	"net/http"
+	"strings"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+// The monitoring event subscription is mapped to a single monitoring configuration
+const monReferenceID = "1"
+
+// UE_REACHABILITY is mapped by the reachabilityType, see convertMonitoringEventSubToEeSubscription()
+var monitoringTypeToEeEventType = map[nef_models.MonitoringType]models.EventType{
+	nef_models.MonitoringType_LOSS_OF_CONNECTIVITY:            models.EventType_LOSS_OF_CONNECTIVITY,
+	nef_models.MonitoringType_UE_REACHABILITY:                 models.EventType_UE_REACHABILITY_FOR_DATA,
+	nef_models.MonitoringType_LOCATION_REPORTING:              models.EventType_LOCATION_REPORTING,
+	nef_models.MonitoringType_CHANGE_OF_IMSI_IMEI_ASSOCIATION: models.EventType_CHANGE_OF_SUPI_PEI_ASSOCIATION,
+	nef_models.MonitoringType_ROAMING_STATUS:                  models.EventType_ROAMING_STATUS,
+	nef_models.MonitoringType_COMMUNICATION_FAILURE:           models.EventType_COMMUNICATION_FAILURE,
+	nef_models.MonitoringType_AVAILABILITY_AFTER_DDN_FAILURE:  models.EventType_AVAILABILITY_AFTER_DNN_FAILURE,
+}
+
+func (p *Processor) GetMonitoringEventSubscriptions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.MonEvtLog.Infof("GetMonitoringEventSubscriptions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	monSubs := []nef_models.MonitoringEventSubscription{}
+	for _, sub := range af.MonSubs {
+		monSubs = append(monSubs, *sub.MonSub)
+	}
+	c.JSON(http.StatusOK, &monSubs)
+}
+
+func (p *Processor) PostMonitoringEventSubscription(
+	c *gin.Context,
+	scsAsID string,
+	monSub *nef_models.MonitoringEventSubscription,
+) {
+	logger.MonEvtLog.Infof("PostMonitoringEventSubscription - scsAsID[%s]", scsAsID)
+
+	if rsp := validateMonitoringEventSubscription(monSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	// The reports are only returned in the response
+	monSub.MonitoringEventReports = nil
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub := af.NewMonSub(nefCtx.NewCorreID(), monSub)
+	afSub.UeIdentity = genUdmEeUeIdentity(monSub)
+	eeSub := p.convertMonitoringEventSubToEeSubscription(monSub, afSub.NotifCorreID)
+	rspStatus, rspBody, eeSubID := p.Consumer().CreateEeSubscription(afSub.UeIdentity, eeSub)
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	afSub.EeSubID = eeSubID
+	monSub.Self = p.genMonitoringEventSubURI(scsAsID, afSub.SubID)
+
+	af.MonSubs[afSub.SubID] = afSub
+	af.Log.Infoln("Monitoring event subscription is added")
+
+	nefCtx.AddAf(af)
+
+	rspMonSub := *monSub
+	if createdEeSub, ok := rspBody.(*models.CreatedEeSubscription); ok && monSub.ImmediateRep {
+		for i := range createdEeSub.EventReports {
+			report := convertMonitoringReportToMonitoringEventReport(&createdEeSub.EventReports[i], monSub)
+			if report != nil {
+				rspMonSub.MonitoringEventReports = append(rspMonSub.MonitoringEventReports, *report)
+			}
+		}
+	}
+
+	c.Header("Location", monSub.Self)
+	c.JSON(http.StatusCreated, &rspMonSub)
+}
+
+func (p *Processor) GetIndividualMonitoringEventSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.MonEvtLog.Infof("GetIndividualMonitoringEventSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	afSub, ok := af.MonSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, afSub.MonSub)
+}
+
+// PutIndividualMonitoringEventSubscription replaces the subscription. The UDM EE
+// subscription is replaced by a new one, which is created before the old one is
+// deleted, so the subscription is kept if the new one can't be created.
+func (p *Processor) PutIndividualMonitoringEventSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+	monSub *nef_models.MonitoringEventSubscription,
+) {
+	logger.MonEvtLog.Infof("PutIndividualMonitoringEventSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	if rsp := validateMonitoringEventSubscription(monSub); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	monSub.MonitoringEventReports = nil
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.MonSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	// The UDM EE subscription is bound to the UE identity
+	if genUdmEeUeIdentity(monSub) != afSub.UeIdentity {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("UE identifier can't be changed")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	eeSub := p.convertMonitoringEventSubToEeSubscription(monSub, afSub.NotifCorreID)
+	rspStatus, rspBody, eeSubID := p.Consumer().CreateEeSubscription(afSub.UeIdentity, eeSub)
+	if rspStatus != http.StatusCreated {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	rspStatus, _ = p.Consumer().DeleteEeSubscription(afSub.UeIdentity, afSub.EeSubID)
+	if rspStatus != http.StatusNoContent {
+		afSub.Log.Warnf("Delete EE subscription[%s] failed: rspCode[%d]", afSub.EeSubID, rspStatus)
+	}
+
+	monSub.Self = afSub.MonSub.Self
+	afSub.MonSub = monSub
+	afSub.EeSubID = eeSubID
+	afSub.NumReports = 0
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, afSub.MonSub)
+}
+
+func (p *Processor) DeleteIndividualMonitoringEventSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.MonEvtLog.Infof("DeleteIndividualMonitoringEventSubscription - scsAsID[%s], subID[%s]",
+		scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	afSub, ok := af.MonSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().DeleteEeSubscription(afSub.UeIdentity, afSub.EeSubID)
+	if rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.MonSubs, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// UdmEeNotification translates the event reports of the UDM (TS 29.503 clause 5.5.2.3.2)
+// into the monitoring event reports to the AF.
+func (p *Processor) UdmEeNotification(
+	c *gin.Context,
+	correID string,
+	monReports []models.MonitoringReport,
+) {
+	logger.MonEvtLog.Infof("UdmEeNotification - correID[%s]", correID)
+
+	af, sub := p.Context().FindAfMonSub(correID)
+	if sub == nil {
+		// Not a monitoring event subscription, but a southbound one of Nnef_EventExposure
+		p.nefEeNotification(c, correID, convertMonitoringReportsToNefEventNotifications(monReports))
+		return
+	}
+
+	af.Mu.RLock()
+	var reports []nef_models.MonitoringEventReport
+	for i := range monReports {
+		report := convertMonitoringReportToMonitoringEventReport(&monReports[i], sub.MonSub)
+		if report != nil {
+			reports = append(reports, *report)
+		}
+	}
+	af.Mu.RUnlock()
+
+	if len(reports) > 0 {
+		go p.notifyMonitoringEventReports(af, sub, reports)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AmfEeNotification translates the event reports of the AMF (TS 29.518 clause 5.3.2.4.1),
+// which the UDM subscribed to on behalf of the NEF, into the monitoring event reports to the AF.
+func (p *Processor) AmfEeNotification(
+	c *gin.Context,
+	correID string,
+	amfNotif *models.AmfEventNotification,
+) {
+	logger.MonEvtLog.Infof("AmfEeNotification - correID[%s]", correID)
+
+	af, sub := p.Context().FindAfMonSub(correID)
+	if sub == nil {
+		// Not a monitoring event subscription, but a southbound one of Nnef_EventExposure
+		p.nefEeNotification(c, correID, convertAmfEventReportsToNefEventNotifications(amfNotif.ReportList))
+		return
+	}
+
+	af.Mu.RLock()
+	var reports []nef_models.MonitoringEventReport
+	for i := range amfNotif.ReportList {
+		report := convertAmfEventReportToMonitoringEventReport(&amfNotif.ReportList[i], sub.MonSub)
+		if report != nil {
+			reports = append(reports, *report)
+		}
+	}
+	af.Mu.RUnlock()
+
+	if len(reports) > 0 {
+		go p.notifyMonitoringEventReports(af, sub, reports)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// notifyMonitoringEventReports sends the reports to the AF. The subscription is
+// cancelled once the maximumNumberOfReports is reached.
+func (p *Processor) notifyMonitoringEventReports(
+	af *nef_context.AfData,
+	sub *nef_context.AfMonitoringSubscription,
+	reports []nef_models.MonitoringEventReport,
+) {
+	af.Mu.Lock()
+	if _, ok := af.MonSubs[sub.SubID]; !ok {
+		af.Mu.Unlock()
+		sub.Log.Infoln("Subscription is deleted, the reports are dropped")
+		return
+	}
+	notifDest := sub.MonSub.NotificationDestination
+	monNotif := &nef_models.MonitoringNotification{
+		Subscription:           sub.MonSub.Self,
+		MonitoringEventReports: reports,
+	}
+	if sub.IsMaxReportsReached(len(reports)) {
+		monNotif.CancelInd = true
+		delete(af.MonSubs, sub.SubID)
+		sub.Log.Infoln("Maximum number of reports is reached, the subscription is cancelled")
+	}
+	ueIdentity, eeSubID := sub.UeIdentity, sub.EeSubID
+	p.Context().StoreAf(af)
+	af.Mu.Unlock()
+
+	// The subscription is already removed from the AF, so the UDM is requested without the lock
+	if monNotif.CancelInd {
+		rspStatus, _ := p.Consumer().DeleteEeSubscription(ueIdentity, eeSubID)
+		if rspStatus != http.StatusNoContent {
+			sub.Log.Warnf("Delete EE subscription[%s] failed: rspCode[%d]", eeSubID, rspStatus)
+		}
+	}
+
+	if err := p.Notifier().MonitoringNotifier.NotifyMonitoringEvent(notifDest, monNotif); err != nil {
+		sub.Log.Errorf("Notify monitoring events failed: %+v", err)
+		return
+	}
+	sub.Log.Infof("Monitoring events are notified")
+}
+
+func validateMonitoringEventSubscription(
+	monSub *nef_models.MonitoringEventSubscription,
+) *HandlerResponse {
+	if monSub.NotificationDestination == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notificationDestination")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	numUeIDs := 0
+	for _, ueID := range []string{monSub.ExternalId, monSub.Msisdn, monSub.ExternalGroupId} {
+		if ueID != "" {
+			numUeIDs++
+		}
+	}
+	if numUeIDs != 1 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"One of externalId, msisdn or externalGroupId shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	if _, ok := monitoringTypeToEeEventType[monSub.MonitoringType]; !ok {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"Unsupported monitoringType: " + string(monSub.MonitoringType))
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if monSub.MonitoringType == nef_models.MonitoringType_UE_REACHABILITY &&
+		monSub.ReachabilityType == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of reachabilityType for UE_REACHABILITY")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+// genUdmEeUeIdentity returns the ueIdentity of the UDM EE subscription (TS 29.503 clause 6.4.3.2.2)
+func genUdmEeUeIdentity(monSub *nef_models.MonitoringEventSubscription) string {
+	switch {
+	case monSub.ExternalId != "":
+		return "extid-" + monSub.ExternalId
+	case monSub.Msisdn != "":
+		return "msisdn-" + monSub.Msisdn
+	default:
+		return "extgroupid-" + monSub.ExternalGroupId
+	}
+}
+
+func (p *Processor) genMonitoringEventSubURI(scsAsID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-monitoring-event/v1/{scsAsId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceMonEvt) + "/" + scsAsID + "/subscriptions/" + subID
+}
+
+// genUdmEeNotificationUri returns the callbackReference of the UDM EE subscription
+func (p *Processor) genUdmEeNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/udm-ee/" + notifCorreID
+}
+
+func (p *Processor) convertMonitoringEventSubToEeSubscription(
+	monSub *nef_models.MonitoringEventSubscription,
+	notifCorreID string,
+) *models.EeSubscription {
+	monCfg := models.MonitoringConfiguration{
+		EventType:     monitoringTypeToEeEventType[monSub.MonitoringType],
+		ImmediateFlag: monSub.ImmediateRep,
+	}
+	switch monSub.MonitoringType {
+	case nef_models.MonitoringType_UE_REACHABILITY:
+		if monSub.ReachabilityType == nef_models.ReachabilityType_SMS {
+			monCfg.EventType = models.EventType_UE_REACHABILITY_FOR_SMS
+		}
+	case nef_models.MonitoringType_LOCATION_REPORTING:
+		monCfg.LocationReportingConfiguration = &models.LocationReportingConfiguration{
+			CurrentLocation: monSub.LocationType != nef_models.LocationType_LAST_KNOWN_LOCATION,
+			OneTime:         monSub.MaximumNumberOfReports == 1,
+			Accuracy:        models.LocationAccuracy_CELL_LEVEL,
+		}
+		if monSub.Accuracy == nef_models.Accuracy_TA_RA {
+			monCfg.LocationReportingConfiguration.Accuracy = models.LocationAccuracy_TA_LEVEL
+		}
+	}
+
+	eeSub := &models.EeSubscription{
+		CallbackReference: p.genUdmEeNotificationUri(notifCorreID),
+		MonitoringConfigurations: map[string]models.MonitoringConfiguration{
+			monReferenceID: monCfg,
+		},
+		SupportedFeatures: monSub.SupportedFeatures,
+	}
+	if monSub.MaximumNumberOfReports > 0 || monSub.MonitorExpireTime != nil {
+		eeSub.ReportingOptions = &models.ReportingOptions{
+			MaxNumOfReports: monSub.MaximumNumberOfReports,
+			Expiry:          monSub.MonitorExpireTime,
+		}
+	}
+	return eeSub
+}
+
+// setMonitoringEventReportUeID sets the UE identifier of the report from the GPSI,
+// or from the subscription of a single UE if the GPSI is absent
+func setMonitoringEventReportUeID(
+	report *nef_models.MonitoringEventReport,
+	gpsi string,
+	monSub *nef_models.MonitoringEventSubscription,
+) {
+	switch {
+	case strings.HasPrefix(gpsi, "msisdn-"):
+		report.Msisdn = strings.TrimPrefix(gpsi, "msisdn-")
+	case strings.HasPrefix(gpsi, "extid-"):
+		report.ExternalId = strings.TrimPrefix(gpsi, "extid-")
+	default:
+		report.ExternalId = monSub.ExternalId
+		report.Msisdn = monSub.Msisdn
+	}
+}
+
+func convertMonitoringReportToMonitoringEventReport(
+	monReport *models.MonitoringReport,
+	monSub *nef_models.MonitoringEventSubscription,
+) *nef_models.MonitoringEventReport {
+	report := &nef_models.MonitoringEventReport{
+		EventTime: monReport.TimeStamp,
+	}
+	switch monReport.EventType {
+	case models.EventType_LOSS_OF_CONNECTIVITY:
+		report.MonitoringType = nef_models.MonitoringType_LOSS_OF_CONNECTIVITY
+	case models.EventType_UE_REACHABILITY_FOR_DATA:
+		report.MonitoringType = nef_models.MonitoringType_UE_REACHABILITY
+		report.ReachabilityType = nef_models.ReachabilityType_DATA
+	case models.EventType_UE_REACHABILITY_FOR_SMS:
+		report.MonitoringType = nef_models.MonitoringType_UE_REACHABILITY
+		report.ReachabilityType = nef_models.ReachabilityType_SMS
+	case models.EventType_LOCATION_REPORTING:
+		report.MonitoringType = nef_models.MonitoringType_LOCATION_REPORTING
+	case models.EventType_CHANGE_OF_SUPI_PEI_ASSOCIATION:
+		report.MonitoringType = nef_models.MonitoringType_CHANGE_OF_IMSI_IMEI_ASSOCIATION
+		if monReport.Report != nil {
+			report.ImeiChange = monReport.Report.NewPei
+		}
+	case models.EventType_ROAMING_STATUS:
+		report.MonitoringType = nef_models.MonitoringType_ROAMING_STATUS
+		if monReport.Report != nil {
+			roaming := monReport.Report.Roaming
+			report.RoamingStatus = &roaming
+			report.PlmnId = monReport.Report.NewServingPlmn
+		}
+	case models.EventType_COMMUNICATION_FAILURE:
+		report.MonitoringType = nef_models.MonitoringType_COMMUNICATION_FAILURE
+	case models.EventType_AVAILABILITY_AFTER_DNN_FAILURE:
+		report.MonitoringType = nef_models.MonitoringType_AVAILABILITY_AFTER_DDN_FAILURE
+	default:
+		logger.MonEvtLog.Debugf("Ignore UDM event[%s]", monReport.EventType)
+		return nil
+	}
+	setMonitoringEventReportUeID(report, monReport.Gpsi, monSub)
+	return report
+}
+
+func convertAmfEventReportToMonitoringEventReport(
+	amfReport *models.AmfEventReport,
+	monSub *nef_models.MonitoringEventSubscription,
+) *nef_models.MonitoringEventReport {
+	report := &nef_models.MonitoringEventReport{
+		EventTime: amfReport.TimeStamp,
+	}
+	switch amfReport.Type {
+	case models.AmfEventType_LOCATION_REPORT:
+		report.MonitoringType = nef_models.MonitoringType_LOCATION_REPORTING
+		report.LocationInfo = convertUserLocationToLocationInfo(amfReport.Location)
+	case models.AmfEventType_REACHABILITY_REPORT:
+		if amfReport.Reachability != models.UeReachability_REACHABLE {
+			return nil
+		}
+		report.MonitoringType = nef_models.MonitoringType_UE_REACHABILITY
+		report.ReachabilityType = monSub.ReachabilityType
+	case models.AmfEventType_REGISTRATION_STATE_REPORT:
+		// Loss of connectivity is detected when the UE is deregistered
+		deregistered := false
+		for _, rmInfo := range amfReport.RmInfoList {
+			if rmInfo.RmState == models.RmState_DEREGISTERED {
+				deregistered = true
+			}
+		}
+		if !deregistered {
+			return nil
+		}
+		report.MonitoringType = nef_models.MonitoringType_LOSS_OF_CONNECTIVITY
+	case models.AmfEventType_COMMUNICATION_FAILURE_REPORT:
+		report.MonitoringType = nef_models.MonitoringType_COMMUNICATION_FAILURE
+	default:
+		logger.MonEvtLog.Debugf("Ignore AMF event[%s]", amfReport.Type)
+		return nil
+	}
+	setMonitoringEventReportUeID(report, amfReport.Gpsi, monSub)
+	return report
+}
+
+func convertUserLocationToLocationInfo(ueLoc *models.UserLocation) *nef_models.LocationInfo {
+	if ueLoc == nil {
+		return nil
+	}
+
+	locInfo := &nef_models.LocationInfo{}
+	switch {
+	case ueLoc.NrLocation != nil:
+		if tai := ueLoc.NrLocation.Tai; tai != nil {
+			locInfo.TrackingAreaId = tai.Tac
+			locInfo.PlmnId = tai.PlmnId
+		}
+		if ncgi := ueLoc.NrLocation.Ncgi; ncgi != nil {
+			locInfo.CellId = ncgi.NrCellId
+		}
+	case ueLoc.EutraLocation != nil:
+		if tai := ueLoc.EutraLocation.Tai; tai != nil {
+			locInfo.TrackingAreaId = tai.Tac
+			locInfo.PlmnId = tai.PlmnId
+		}
+		if ecgi := ueLoc.EutraLocation.Ecgi; ecgi != nil {
+			locInfo.CellId = ecgi.EutraCellId
+		}
+	default:
+		return nil
+	}
+	return locInfo
+}
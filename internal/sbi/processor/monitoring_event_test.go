@@ -0,0 +1,356 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var monSub1ForAf1 = nef_models.MonitoringEventSubscription{
+	ExternalId:              "ue1@free5gc.org",
+	NotificationDestination: "http://127.0.0.100:8000/mon/notify",
+	MonitoringType:          nef_models.MonitoringType_ROAMING_STATUS,
+	MaximumNumberOfReports:  2,
+	ImmediateRep:            true,
+}
+
+func TestPostMonitoringEventSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_EE, "127.0.0.3")
+	// Only remove the stubs of this test, the NRF stubs set in TestMain are still needed by the others.
+	udmStub := initUDMEeCreateStub("extid-ue1@free5gc.org", []models.MonitoringReport{
+		{
+			ReferenceId: 1,
+			EventType:   models.EventType_ROAMING_STATUS,
+			Report: &models.Report{
+				Roaming: false,
+			},
+		},
+	})
+	defer gock.Remove(udmStub)
+
+	rspMonSub1 := monSub1ForAf1
+	rspMonSub1.Self = nefApp.Processor().genMonitoringEventSubURI("af1", "1")
+	roaming := false
+	rspMonSub1.MonitoringEventReports = []nef_models.MonitoringEventReport{
+		{
+			ExternalId:     monSub1ForAf1.ExternalId,
+			MonitoringType: nef_models.MonitoringType_ROAMING_STATUS,
+			RoamingStatus:  &roaming,
+		},
+	}
+
+	monSubTwoUeIDs := monSub1ForAf1
+	monSubTwoUeIDs.Msisdn = "886912345678"
+
+	monSubUnsupportedType := monSub1ForAf1
+	monSubUnsupportedType.MonitoringType = nef_models.MonitoringType_NUMBER_OF_UES_IN_AN_AREA
+
+	monSubNoReachabilityType := monSub1ForAf1
+	monSubNoReachabilityType.MonitoringType = nef_models.MonitoringType_UE_REACHABILITY
+
+	testCases := []struct {
+		description      string
+		monSub           nef_models.MonitoringEventSubscription
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription with immediate report, should create EE subscription in UDM",
+			monSub:      monSub1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspMonSub1.Self},
+				},
+				Body: &rspMonSub1,
+			},
+		},
+		{
+			description: "TC2: More than one UE identifier",
+			monSub:      monSubTwoUeIDs,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "One of externalId, msisdn or externalGroupId shall be included",
+				},
+			},
+		},
+		{
+			description: "TC3: Unsupported monitoringType",
+			monSub:      monSubUnsupportedType,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unsupported monitoringType: NUMBER_OF_UES_IN_AN_AREA",
+				},
+			},
+		},
+		{
+			description: "TC4: Absent of reachabilityType",
+			monSub:      monSubNoReachabilityType,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of reachabilityType for UE_REACHABILITY",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			monSub := tc.monSub
+			nefApp.Processor().PostMonitoringEventSubscription(c, "af1", &monSub)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "ee1", af.MonSubs["1"].EeSubID)
+	require.Empty(t, af.MonSubs["1"].MonSub.MonitoringEventReports)
+	af.Mu.RUnlock()
+}
+
+func TestPutIndividualMonitoringEventSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_EE, "127.0.0.3")
+	udmCreateStub := initUDMEeCreateStub("extid-ue1@free5gc.org", nil)
+	defer gock.Remove(udmCreateStub)
+	udmDeleteStub := initUDMEeDeleteStub("extid-ue1@free5gc.org", "ee0")
+	defer gock.Remove(udmDeleteStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	monSub := monSub1ForAf1
+	monSub.Self = nefApp.Processor().genMonitoringEventSubURI("af1", "1")
+	afSub1 := af1.NewMonSub(nefCtx.NewCorreID(), &monSub)
+	afSub1.UeIdentity = "extid-ue1@free5gc.org"
+	afSub1.EeSubID = "ee0"
+	afSub1.NumReports = 1
+	af1.MonSubs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	monSubLocation := monSub1ForAf1
+	monSubLocation.MonitoringType = nef_models.MonitoringType_LOCATION_REPORTING
+	monSubLocation.ImmediateRep = false
+	rspMonSubLocation := monSubLocation
+	rspMonSubLocation.Self = monSub.Self
+
+	monSubOtherUe := monSubLocation
+	monSubOtherUe.ExternalId = "ue2@free5gc.org"
+
+	testCases := []struct {
+		description      string
+		subID            string
+		monSub           nef_models.MonitoringEventSubscription
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Change of the UE identifier",
+			subID:       afSub1.SubID,
+			monSub:      monSubOtherUe,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "UE identifier can't be changed",
+				},
+			},
+		},
+		{
+			description: "TC2: Unknown subscription",
+			subID:       "2",
+			monSub:      monSubLocation,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Title:  "Data not found",
+					Status: http.StatusNotFound,
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC3: Successful replacement, should replace EE subscription in UDM",
+			subID:       afSub1.SubID,
+			monSub:      monSubLocation,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &rspMonSubLocation,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			monSub := tc.monSub
+			nefApp.Processor().PutIndividualMonitoringEventSubscription(c, "af1", tc.subID, &monSub)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af1.Mu.RLock()
+	require.Equal(t, "ee1", afSub1.EeSubID)
+	require.Equal(t, nef_models.MonitoringType_LOCATION_REPORTING, afSub1.MonSub.MonitoringType)
+	require.Zero(t, afSub1.NumReports)
+	af1.Mu.RUnlock()
+}
+
+func TestMonitoringEventNotification(t *testing.T) {
+	afNotifChan := make(chan *http.Request, 1)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/mon/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	udmStub := initUDMEeDeleteStub("extid-ue1@free5gc.org", "ee1")
+	defer gock.Remove(udmStub)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/mon/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	monSub := monSub1ForAf1
+	monSub.MonitoringType = nef_models.MonitoringType_LOCATION_REPORTING
+	monSub.Self = "http://127.0.0.5:8000/3gpp-monitoring-event/v1/af1/subscriptions/1"
+	afSub1 := af1.NewMonSub(nefCtx.NewCorreID(), &monSub)
+	afSub1.UeIdentity = "extid-ue1@free5gc.org"
+	afSub1.EeSubID = "ee1"
+	af1.MonSubs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	plmnID := &models.PlmnId{Mcc: "208", Mnc: "93"}
+	amfNotif := &models.AmfEventNotification{
+		ReportList: []models.AmfEventReport{
+			{
+				Type: models.AmfEventType_LOCATION_REPORT,
+				Gpsi: "extid-ue1@free5gc.org",
+				Location: &models.UserLocation{
+					NrLocation: &models.NrLocation{
+						Tai: &models.Tai{
+							PlmnId: plmnID,
+							Tac:    "000001",
+						},
+						Ncgi: &models.Ncgi{
+							PlmnId:   plmnID,
+							NrCellId: "000000010",
+						},
+					},
+				},
+			},
+		},
+	}
+	locReport := nef_models.MonitoringEventReport{
+		ExternalId:     "ue1@free5gc.org",
+		MonitoringType: nef_models.MonitoringType_LOCATION_REPORTING,
+		LocationInfo: &nef_models.LocationInfo{
+			CellId:         "000000010",
+			TrackingAreaId: "000001",
+			PlmnId:         plmnID,
+		},
+	}
+
+	for i, expectedNotif := range []nef_models.MonitoringNotification{
+		{
+			Subscription:           monSub.Self,
+			MonitoringEventReports: []nef_models.MonitoringEventReport{locReport},
+		},
+		{
+			Subscription:           monSub.Self,
+			MonitoringEventReports: []nef_models.MonitoringEventReport{locReport},
+			CancelInd:              true,
+		},
+	} {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+
+		nefApp.Processor().AmfEeNotification(c, afSub1.NotifCorreID, amfNotif)
+		require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+		select {
+		case req := <-afNotifChan:
+			var monNotif nef_models.MonitoringNotification
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&monNotif))
+			require.Equal(t, expectedNotif, monNotif, "report %d", i+1)
+		case <-time.After(time.Second):
+			t.Fatal("AF is not notified")
+		}
+	}
+
+	// The subscription is cancelled after the maximumNumberOfReports
+	af1.Mu.RLock()
+	require.NotContains(t, af1.MonSubs, afSub1.SubID)
+	af1.Mu.RUnlock()
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().UdmEeNotification(c, afSub1.NotifCorreID, []models.MonitoringReport{})
+	require.Equal(t, http.StatusNotFound, httpRecorder.Code)
+}
+
+func initUDMEeCreateStub(ueIdentity string, eventReports []models.MonitoringReport) gock.Mock {
+	req := gock.New("http://127.0.0.3:8000/nudm-ee/v1")
+	req.Post("/"+ueIdentity+"/ee-subscriptions").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.3:8000/nudm-ee/v1/"+ueIdentity+"/ee-subscriptions/ee1").
+		JSON(models.CreatedEeSubscription{
+			EeSubscription: &models.EeSubscription{},
+			EventReports:   eventReports,
+		})
+	return req.Mock
+}
+
+func initUDMEeDeleteStub(ueIdentity, eeSubID string) gock.Mock {
+	req := gock.New("http://127.0.0.3:8000/nudm-ee/v1")
+	req.Delete("/" + ueIdentity + "/ee-subscriptions/" + eeSubID).
+		Persist().
+		Reply(http.StatusNoContent)
+	return req.Mock
+}
@@ -0,0 +1,566 @@
+package processor
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+var supportedNefEvents = map[nef_models.NefEvent]bool{
+	nef_models.NefEvent_UE_MOBILITY:           true,
+	nef_models.NefEvent_COMMUNICATION_FAILURE: true,
+	nef_models.NefEvent_QOS_SUSTAINABILITY:    true,
+}
+
+// smfEventCommFail is the communication failure event of Nsmf_EventExposure (TS 29.508),
+// which isn't defined in the openapi models
+const smfEventCommFail models.SmfEvent = "COMM_FAIL"
+
+// ueIdentityAnyUE is the UE identity of the UDM EE subscription to any UE
+const ueIdentityAnyUE = "anyUE"
+
+// nefEeSouthboundEvent is an event of the AMF, UDM or SMF, which is subscribed
+// to collect a NEF event
+type nefEeSouthboundEvent struct {
+	nfType models.NfType
+	event  string
+}
+
+// QOS_SUSTAINABILITY has no southbound subscription, it's collected from the
+// AS sessions with QoS, see convertEventsNotificationToNefEventNotifications()
+var nefEventToSouthboundEvents = map[nef_models.NefEvent][]nefEeSouthboundEvent{
+	nef_models.NefEvent_UE_MOBILITY: {
+		{models.NfType_AMF, string(models.AmfEventType_LOCATION_REPORT)},
+	},
+	nef_models.NefEvent_COMMUNICATION_FAILURE: {
+		{models.NfType_UDM, string(models.EventType_COMMUNICATION_FAILURE)},
+		{models.NfType_SMF, string(smfEventCommFail)},
+	},
+}
+
+func (p *Processor) PostNefEeSubscription(
+	c *gin.Context,
+	eeSubsc *nef_models.NefEventExposureSubsc,
+) {
+	logger.NefEeLog.Infof("PostNefEeSubscription - notifId[%s]", eeSubsc.NotifId)
+
+	if rsp := validateNefEventExposureSubsc(eeSubsc); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	// The events are collected from the notifications of the AMF, UDM, SMF and PCF,
+	// so there's no event to be reported immediately
+	eeSubsc.EventNotifs = nil
+
+	nefCtx := p.Context()
+	sub := nefCtx.NewNefEeSub(eeSubsc)
+
+	sub.Mu.Lock()
+	defer sub.Mu.Unlock()
+
+	// The subscription is added before the southbound subscriptions are created, so that
+	// their notifications can find it once the lock is released
+	nefCtx.AddNefEeSub(sub)
+	sbSubs, rsp := p.createNefEeSouthboundSubs(eeSubsc)
+	if rsp != nil {
+		nefCtx.DeleteNefEeSub(sub.SubID)
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	sub.SouthboundSubs = sbSubs
+	nefCtx.StoreNefEeSub(sub)
+
+	c.Header("Location", p.genNefEeSubURI(sub.SubID))
+	c.JSON(http.StatusCreated, eeSubsc)
+}
+
+func (p *Processor) GetNefEeSubscription(
+	c *gin.Context,
+	subID string,
+) {
+	logger.NefEeLog.Infof("GetNefEeSubscription - subID[%s]", subID)
+
+	sub := p.Context().GetNefEeSub(subID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	sub.Mu.RLock()
+	defer sub.Mu.RUnlock()
+	c.JSON(http.StatusOK, sub.EeSubsc)
+}
+
+// PutNefEeSubscription replaces the subscription. The southbound subscriptions of the
+// new events are created before the old ones are deleted, so the subscription is kept
+// if they can't be created.
+func (p *Processor) PutNefEeSubscription(
+	c *gin.Context,
+	subID string,
+	eeSubsc *nef_models.NefEventExposureSubsc,
+) {
+	logger.NefEeLog.Infof("PutNefEeSubscription - subID[%s]", subID)
+
+	if rsp := validateNefEventExposureSubsc(eeSubsc); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	eeSubsc.EventNotifs = nil
+
+	nefCtx := p.Context()
+	sub := nefCtx.GetNefEeSub(subID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	sub.Mu.Lock()
+	if nefCtx.GetNefEeSub(subID) != sub {
+		sub.Mu.Unlock()
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	sbSubs, rsp := p.createNefEeSouthboundSubs(eeSubsc)
+	if rsp != nil {
+		sub.Mu.Unlock()
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	oldSbSubs := sub.SouthboundSubs
+	sub.EeSubsc = eeSubsc
+	sub.SouthboundSubs = sbSubs
+	sub.NumReports = 0
+	nefCtx.StoreNefEeSub(sub)
+	sub.Log.Infoln("EE subscription is replaced")
+	rspEeSubsc := *sub.EeSubsc
+	sub.Mu.Unlock()
+
+	p.deleteNefEeSouthboundSubs(sub, oldSbSubs)
+	c.JSON(http.StatusOK, &rspEeSubsc)
+}
+
+func (p *Processor) DeleteNefEeSubscription(
+	c *gin.Context,
+	subID string,
+) {
+	logger.NefEeLog.Infof("DeleteNefEeSubscription - subID[%s]", subID)
+
+	nefCtx := p.Context()
+	sub := nefCtx.GetNefEeSub(subID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	sub.Mu.Lock()
+	if nefCtx.GetNefEeSub(subID) != sub {
+		sub.Mu.Unlock()
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	nefCtx.DeleteNefEeSub(subID)
+	sbSubs := sub.SouthboundSubs
+	sub.Mu.Unlock()
+
+	p.deleteNefEeSouthboundSubs(sub, sbSubs)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// createNefEeSouthboundSubs subscribes to the events of the AMF, UDM and SMF for each
+// target UE of the subscribed events. If one of them fails, the ones already created
+// are deleted.
+func (p *Processor) createNefEeSouthboundSubs(
+	eeSubsc *nef_models.NefEventExposureSubsc,
+) ([]*nef_context.NefEeSouthboundSub, *HandlerResponse) {
+	var sbSubs []*nef_context.NefEeSouthboundSub
+	for _, eventSubs := range eeSubsc.EventsSubs {
+		for _, ueIdentity := range genNefEeUeIdentities(eventSubs.EventFilter.TgtUe) {
+			for _, sbEvent := range nefEventToSouthboundEvents[eventSubs.Event] {
+				sbSub, rsp := p.createNefEeSouthboundSub(sbEvent, ueIdentity, eeSubsc.EventsRepInfo)
+				if rsp != nil {
+					p.deleteNefEeSouthboundSubs(nil, sbSubs)
+					return nil, rsp
+				}
+				sbSubs = append(sbSubs, sbSub)
+			}
+		}
+	}
+	return sbSubs, nil
+}
+
+func (p *Processor) createNefEeSouthboundSub(
+	sbEvent nefEeSouthboundEvent,
+	ueIdentity string,
+	repInfo *nef_models.ReportingInformation,
+) (*nef_context.NefEeSouthboundSub, *HandlerResponse) {
+	sbSub := &nef_context.NefEeSouthboundSub{
+		NfType:       sbEvent.nfType,
+		UeIdentity:   ueIdentity,
+		NotifCorreID: strconv.FormatUint(p.Context().NewCorreID(), 10),
+	}
+	var (
+		rspStatus int
+		rspBody   interface{}
+	)
+	switch sbEvent.nfType {
+	case models.NfType_AMF:
+		amfSub := p.genAmfCreateEventSubscription(sbEvent.event, ueIdentity, sbSub.NotifCorreID, repInfo)
+		rspStatus, rspBody, sbSub.SubID = p.Consumer().CreateAmfEeSubscription(amfSub)
+	case models.NfType_UDM:
+		eeSub := p.genEeSubscription(sbEvent.event, sbSub.NotifCorreID, repInfo)
+		rspStatus, rspBody, sbSub.SubID = p.Consumer().CreateEeSubscription(ueIdentity, eeSub)
+	case models.NfType_SMF:
+		smfSub := p.genNsmfEventExposure(sbEvent.event, ueIdentity, sbSub.NotifCorreID, repInfo)
+		rspStatus, rspBody, sbSub.SubID = p.Consumer().CreateSmfEeSubscription(smfSub)
+	}
+	if rspStatus != http.StatusCreated {
+		logger.NefEeLog.Errorf("Create %s EE subscription of UE[%s] failed: rspCode[%d]",
+			sbSub.NfType, ueIdentity, rspStatus)
+		return nil, &HandlerResponse{rspStatus, nil, rspBody}
+	}
+	return sbSub, nil
+}
+
+// deleteNefEeSouthboundSubs deletes the southbound subscriptions, which are already
+// removed from the subscription, so it's called without the lock of the subscription
+func (p *Processor) deleteNefEeSouthboundSubs(
+	sub *nef_context.NefEeSubscription,
+	sbSubs []*nef_context.NefEeSouthboundSub,
+) {
+	log := logger.NefEeLog
+	if sub != nil {
+		log = sub.Log
+	}
+	for _, sbSub := range sbSubs {
+		var rspStatus int
+		switch sbSub.NfType {
+		case models.NfType_AMF:
+			rspStatus, _ = p.Consumer().DeleteAmfEeSubscription(sbSub.SubID)
+		case models.NfType_UDM:
+			rspStatus, _ = p.Consumer().DeleteEeSubscription(sbSub.UeIdentity, sbSub.SubID)
+		case models.NfType_SMF:
+			rspStatus, _ = p.Consumer().DeleteSmfEeSubscription(sbSub.SubID)
+		}
+		if rspStatus != http.StatusNoContent {
+			log.Warnf("Delete %s EE subscription[%s] failed: rspCode[%d]", sbSub.NfType, sbSub.SubID, rspStatus)
+		}
+	}
+}
+
+// nefEeNotification passes the events notified by a southbound subscription
+// to the Nnef_EventExposure subscription it belongs to
+func (p *Processor) nefEeNotification(
+	c *gin.Context,
+	correID string,
+	eventNotifs []nef_models.NefEventNotification,
+) {
+	sub := p.Context().FindNefEeSub(correID)
+	if sub == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if len(eventNotifs) > 0 {
+		go p.notifyNefEeSub(sub, eventNotifs)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// SmfEeNotification translates the events of the SMF into the events of
+// the Nnef_EventExposure subscription
+func (p *Processor) SmfEeNotification(
+	c *gin.Context,
+	correID string,
+	smfNotif *models.NsmfEventExposureNotification,
+) {
+	logger.NefEeLog.Infof("SmfEeNotification - correID[%s]", correID)
+
+	p.nefEeNotification(c, correID, convertSmfEventNotificationsToNefEventNotifications(smfNotif.EventNotifs))
+}
+
+// notifyNefEvents fans out the events which aren't collected by a southbound
+// subscription to all the subscriptions
+func (p *Processor) notifyNefEvents(eventNotifs []nef_models.NefEventNotification) {
+	for _, sub := range p.Context().GetNefEeSubs() {
+		p.notifyNefEeSub(sub, eventNotifs)
+	}
+}
+
+// notifyNefEeSub notifies the events matching the event filters of the subscription.
+// The subscription is removed once the monitoring duration is over or the
+// maxReportNbr is reached.
+func (p *Processor) notifyNefEeSub(
+	sub *nef_context.NefEeSubscription,
+	eventNotifs []nef_models.NefEventNotification,
+) {
+	nefCtx := p.Context()
+	sub.Mu.Lock()
+	if nefCtx.GetNefEeSub(sub.SubID) != sub {
+		sub.Mu.Unlock()
+		return
+	}
+	if sub.IsExpired(time.Now()) {
+		nefCtx.DeleteNefEeSub(sub.SubID)
+		sbSubs := sub.SouthboundSubs
+		sub.Mu.Unlock()
+		sub.Log.Infoln("Monitoring duration is over, the subscription is removed")
+		p.deleteNefEeSouthboundSubs(sub, sbSubs)
+		return
+	}
+
+	matched := sub.FilterEvents(eventNotifs)
+	if len(matched) == 0 {
+		sub.Mu.Unlock()
+		return
+	}
+	notifUri := sub.EeSubsc.NotifUri
+	eeNotif := &nef_models.NefEventExposureNotif{
+		NotifId:     sub.EeSubsc.NotifId,
+		EventNotifs: matched,
+	}
+	var sbSubs []*nef_context.NefEeSouthboundSub
+	if sub.IsMaxReportsReached(len(matched)) {
+		nefCtx.DeleteNefEeSub(sub.SubID)
+		sbSubs = sub.SouthboundSubs
+		sub.Log.Infoln("Maximum number of reports is reached, the subscription is removed")
+	} else {
+		nefCtx.StoreNefEeSub(sub)
+	}
+	sub.Mu.Unlock()
+
+	p.deleteNefEeSouthboundSubs(sub, sbSubs)
+	if err := p.Notifier().NefEeNotifier.NotifyNefEvent(notifUri, eeNotif); err != nil {
+		sub.Log.Errorf("Notify events failed: %+v", err)
+		return
+	}
+	sub.Log.Infof("%d events are notified", len(matched))
+}
+
+func validateNefEventExposureSubsc(eeSubsc *nef_models.NefEventExposureSubsc) *HandlerResponse {
+	if eeSubsc.NotifUri == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notifUri")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if eeSubsc.NotifId == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notifId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if len(eeSubsc.EventsSubs) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of eventsSubs")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	for _, eventSubs := range eeSubsc.EventsSubs {
+		if !supportedNefEvents[eventSubs.Event] {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("Unsupported event: " + string(eventSubs.Event))
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+		if eventSubs.EventFilter == nil || eventSubs.EventFilter.TgtUe == nil {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of tgtUe in eventFilter")
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+		tgtUe := eventSubs.EventFilter.TgtUe
+		if !tgtUe.AnyUeId && len(tgtUe.Supis) == 0 && len(tgtUe.Gpsis) == 0 {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("One of supis, gpsis or anyUeId shall be included")
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+	}
+	if repInfo := eeSubsc.EventsRepInfo; repInfo != nil && repInfo.MonDur != nil &&
+		repInfo.MonDur.Before(time.Now()) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid monDur")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genNefEeSubURI(subID string) string {
+	// E.g. https://localhost:29505/nnef-eventexposure/v1/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceNefEe) + "/subscriptions/" + subID
+}
+
+// genNefEeUeIdentities returns the identities of the target UEs, one per southbound subscription
+func genNefEeUeIdentities(tgtUe *nef_models.TargetUeIdentification) []string {
+	if tgtUe.AnyUeId {
+		return []string{ueIdentityAnyUE}
+	}
+	ueIdentities := make([]string, 0, len(tgtUe.Supis)+len(tgtUe.Gpsis))
+	ueIdentities = append(ueIdentities, tgtUe.Supis...)
+	return append(ueIdentities, tgtUe.Gpsis...)
+}
+
+// isGpsi returns true if the UE identity is a GPSI (TS 29.571 clause 5.3.2)
+func isGpsi(ueIdentity string) bool {
+	return strings.HasPrefix(ueIdentity, "msisdn-") || strings.HasPrefix(ueIdentity, "extid-")
+}
+
+func (p *Processor) genAmfEeNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/amf-ee/" + notifCorreID
+}
+
+func (p *Processor) genSmfEeNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/smf-ee/" + notifCorreID
+}
+
+func (p *Processor) genAmfCreateEventSubscription(
+	event, ueIdentity, notifCorreID string,
+	repInfo *nef_models.ReportingInformation,
+) *models.AmfCreateEventSubscription {
+	amfSub := &models.AmfEventSubscription{
+		EventList:           &[]models.AmfEvent{{Type: models.AmfEventType(event)}},
+		EventNotifyUri:      p.genAmfEeNotificationUri(notifCorreID),
+		NotifyCorrelationId: notifCorreID,
+		NfId:                p.Context().NfInstID(),
+	}
+	switch {
+	case ueIdentity == ueIdentityAnyUE:
+		amfSub.AnyUE = true
+	case isGpsi(ueIdentity):
+		amfSub.Gpsi = ueIdentity
+	default:
+		amfSub.Supi = ueIdentity
+	}
+	if repInfo != nil && repInfo.MonDur != nil {
+		amfSub.Options = &models.AmfEventMode{
+			Trigger: models.AmfEventTrigger_CONTINUOUS,
+			Expiry:  repInfo.MonDur,
+		}
+	}
+	return &models.AmfCreateEventSubscription{Subscription: amfSub}
+}
+
+func (p *Processor) genEeSubscription(
+	event, notifCorreID string,
+	repInfo *nef_models.ReportingInformation,
+) *models.EeSubscription {
+	eeSub := &models.EeSubscription{
+		CallbackReference: p.genUdmEeNotificationUri(notifCorreID),
+		MonitoringConfigurations: map[string]models.MonitoringConfiguration{
+			monReferenceID: {EventType: models.EventType(event)},
+		},
+	}
+	if repInfo != nil && repInfo.MonDur != nil {
+		eeSub.ReportingOptions = &models.ReportingOptions{Expiry: repInfo.MonDur}
+	}
+	return eeSub
+}
+
+func (p *Processor) genNsmfEventExposure(
+	event, ueIdentity, notifCorreID string,
+	repInfo *nef_models.ReportingInformation,
+) *models.NsmfEventExposure {
+	smfSub := &models.NsmfEventExposure{
+		NotifId:   notifCorreID,
+		NotifUri:  p.genSmfEeNotificationUri(notifCorreID),
+		EventSubs: []models.EventSubscription{{Event: models.SmfEvent(event)}},
+	}
+	switch {
+	case ueIdentity == ueIdentityAnyUE:
+		smfSub.AnyUeInd = true
+	case isGpsi(ueIdentity):
+		smfSub.Gpsi = ueIdentity
+	default:
+		smfSub.Supi = ueIdentity
+	}
+	if repInfo != nil {
+		smfSub.Expiry = repInfo.MonDur
+	}
+	return smfSub
+}
+
+func convertSmfEventNotificationsToNefEventNotifications(
+	smfEventNotifs []models.EventNotification,
+) []nef_models.NefEventNotification {
+	var eventNotifs []nef_models.NefEventNotification
+	for i := range smfEventNotifs {
+		if smfEventNotifs[i].Event != smfEventCommFail {
+			continue
+		}
+		eventNotifs = append(eventNotifs, nef_models.NefEventNotification{
+			Event:     nef_models.NefEvent_COMMUNICATION_FAILURE,
+			TimeStamp: smfEventNotifs[i].TimeStamp,
+			Supi:      smfEventNotifs[i].Supi,
+			Gpsi:      smfEventNotifs[i].Gpsi,
+		})
+	}
+	return eventNotifs
+}
+
+func convertAmfEventReportsToNefEventNotifications(
+	amfReports []models.AmfEventReport,
+) []nef_models.NefEventNotification {
+	var eventNotifs []nef_models.NefEventNotification
+	for i := range amfReports {
+		amfReport := &amfReports[i]
+		eventNotif := nef_models.NefEventNotification{
+			TimeStamp: amfReport.TimeStamp,
+			Supi:      amfReport.Supi,
+			Gpsi:      amfReport.Gpsi,
+		}
+		switch amfReport.Type {
+		case models.AmfEventType_LOCATION_REPORT:
+			eventNotif.Event = nef_models.NefEvent_UE_MOBILITY
+			eventNotif.Location = amfReport.Location
+		case models.AmfEventType_COMMUNICATION_FAILURE_REPORT:
+			eventNotif.Event = nef_models.NefEvent_COMMUNICATION_FAILURE
+			eventNotif.CommFailure = amfReport.CommFailure
+		default:
+			continue
+		}
+		eventNotifs = append(eventNotifs, eventNotif)
+	}
+	return eventNotifs
+}
+
+func convertMonitoringReportsToNefEventNotifications(
+	monReports []models.MonitoringReport,
+) []nef_models.NefEventNotification {
+	var eventNotifs []nef_models.NefEventNotification
+	for i := range monReports {
+		if monReports[i].EventType != models.EventType_COMMUNICATION_FAILURE {
+			continue
+		}
+		eventNotifs = append(eventNotifs, nef_models.NefEventNotification{
+			Event:     nef_models.NefEvent_COMMUNICATION_FAILURE,
+			TimeStamp: monReports[i].TimeStamp,
+			Gpsi:      monReports[i].Gpsi,
+		})
+	}
+	return eventNotifs
+}
+
+// convertEventsNotificationToNefEventNotifications maps the QoS notification control
+// reports of the PCF into QOS_SUSTAINABILITY events. The PCF doesn't identify the UE
+// of the app session, so these events only match the subscriptions of any UE.
+func convertEventsNotificationToNefEventNotifications(
+	evsNotif *models.EventsNotification,
+) []nef_models.NefEventNotification {
+	var eventNotifs []nef_models.NefEventNotification
+	for _, evNotif := range evsNotif.EvNotifs {
+		if evNotif.Event != models.AfEvent_QOS_NOTIF {
+			continue
+		}
+		now := time.Now()
+		for _, qncReport := range evsNotif.QncReports {
+			eventNotifs = append(eventNotifs, nef_models.NefEventNotification{
+				Event:        nef_models.NefEvent_QOS_SUSTAINABILITY,
+				TimeStamp:    &now,
+				QosNotifType: qncReport.NotifType,
+			})
+		}
+	}
+	return eventNotifs
+}
@@ -0,0 +1,494 @@
+package processor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var nefEeSubscForUe1 = nef_models.NefEventExposureSubsc{
+	EventsSubs: []nef_models.NefEventSubs{
+		{
+			Event: nef_models.NefEvent_UE_MOBILITY,
+			EventFilter: &nef_models.NefEventFilter{
+				TgtUe: &nef_models.TargetUeIdentification{
+					Supis: []string{"imsi-208930000000001"},
+				},
+			},
+		},
+	},
+	NotifUri: "http://127.0.0.100:8000/nef-ee/notify1",
+	NotifId:  "notif1",
+}
+
+func TestPostNefEeSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_AMF, models.ServiceName_NAMF_EVTS, "127.0.0.11")
+	amfCreateStub := initAMFEeCreateStub("amf-ee1")
+	defer gock.Remove(amfCreateStub)
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_EE, "127.0.0.3")
+	udmCreateStub := initUDMEeCreateStub("msisdn-0900000001", nil)
+	defer gock.Remove(udmCreateStub)
+	udmDeleteStub := initUDMEeDeleteStub("msisdn-0900000001", "ee1")
+	defer gock.Remove(udmDeleteStub)
+	initNRFDiscStub(models.NfType_SMF, models.ServiceName_NSMF_EVENT_EXPOSURE, "127.0.0.2")
+	smfCreateStub := gock.New("http://127.0.0.2:8000/nsmf-event-exposure/v1")
+	smfCreateStub.Post("/subscriptions").
+		Persist().
+		Reply(http.StatusForbidden).
+		JSON(models.ProblemDetails{
+			Status: http.StatusForbidden,
+			Cause:  "UNSUPPORTED_EVENT",
+		})
+	defer gock.Remove(smfCreateStub.Mock)
+	var amfSubBody []byte
+	udmDeleted := false
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		switch {
+		case strings.Contains(request.URL.String(), "/namf-evts/"):
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			amfSubBody = body
+		case request.Method == http.MethodDelete && strings.Contains(request.URL.String(), "/nudm-ee/"):
+			udmDeleted = true
+		}
+	})
+	defer gock.Observe(nil)
+
+	eeSubscNoNotifUri := nefEeSubscForUe1
+	eeSubscNoNotifUri.NotifUri = ""
+
+	eeSubscNoTgtUe := nefEeSubscForUe1
+	eeSubscNoTgtUe.EventsSubs = []nef_models.NefEventSubs{
+		{
+			Event: nef_models.NefEvent_UE_MOBILITY,
+		},
+	}
+
+	eeSubscUnsupportedEvent := nefEeSubscForUe1
+	eeSubscUnsupportedEvent.EventsSubs = []nef_models.NefEventSubs{
+		{
+			Event: "SVC_EXPERIENCE",
+			EventFilter: &nef_models.NefEventFilter{
+				TgtUe: &nef_models.TargetUeIdentification{AnyUeId: true},
+			},
+		},
+	}
+
+	eeSubscCommFailure := nefEeSubscForUe1
+	eeSubscCommFailure.EventsSubs = []nef_models.NefEventSubs{
+		{
+			Event: nef_models.NefEvent_COMMUNICATION_FAILURE,
+			EventFilter: &nef_models.NefEventFilter{
+				TgtUe: &nef_models.TargetUeIdentification{
+					Gpsis: []string{"msisdn-0900000001"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		eeSubsc          nef_models.NefEventExposureSubsc
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription",
+			eeSubsc:     nefEeSubscForUe1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {nefApp.Processor().genNefEeSubURI("1")},
+				},
+				Body: &nefEeSubscForUe1,
+			},
+		},
+		{
+			description: "TC2: Absent of notifUri",
+			eeSubsc:     eeSubscNoNotifUri,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of notifUri",
+				},
+			},
+		},
+		{
+			description: "TC3: Absent of tgtUe",
+			eeSubsc:     eeSubscNoTgtUe,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of tgtUe in eventFilter",
+				},
+			},
+		},
+		{
+			description: "TC4: Unsupported event",
+			eeSubsc:     eeSubscUnsupportedEvent,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Unsupported event: SVC_EXPERIENCE",
+				},
+			},
+		},
+		{
+			description: "TC5: SMF rejects the subscription, the one of UDM is deleted",
+			eeSubsc:     eeSubscCommFailure,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusForbidden,
+				Body: &models.ProblemDetails{
+					Status: http.StatusForbidden,
+					Cause:  "UNSUPPORTED_EVENT",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteNefEeSub("1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			eeSubsc := tc.eeSubsc
+			nefApp.Processor().PostNefEeSubscription(c, &eeSubsc)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	sub := nefCtx.GetNefEeSub("1")
+	require.NotNil(t, sub)
+	require.Equal(t, []*nef_context.NefEeSouthboundSub{
+		{
+			NfType:       models.NfType_AMF,
+			UeIdentity:   "imsi-208930000000001",
+			SubID:        "amf-ee1",
+			NotifCorreID: "2",
+		},
+	}, sub.SouthboundSubs)
+	assertJSONBodyEqual(t, &models.AmfCreateEventSubscription{
+		Subscription: &models.AmfEventSubscription{
+			EventList:           &[]models.AmfEvent{{Type: models.AmfEventType_LOCATION_REPORT}},
+			EventNotifyUri:      nefApp.Processor().genAmfEeNotificationUri("2"),
+			NotifyCorrelationId: "2",
+			NfId:                nefCtx.NfInstID(),
+			Supi:                "imsi-208930000000001",
+		},
+	}, amfSubBody)
+
+	// Subscription of TC5 isn't kept
+	require.Nil(t, nefCtx.GetNefEeSub("3"))
+	require.True(t, udmDeleted)
+}
+
+func TestDeleteNefEeSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_AMF, models.ServiceName_NAMF_EVTS, "127.0.0.11")
+	amfDeleteStub := initAMFEeDeleteStub("amf-ee1")
+	defer gock.Remove(amfDeleteStub)
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_EE, "127.0.0.3")
+	udmDeleteStub := initUDMEeDeleteStub("anyUE", "ee1")
+	defer gock.Remove(udmDeleteStub)
+	var deleted []string
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.Method == http.MethodDelete {
+			deleted = append(deleted, request.URL.Path)
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	eeSubsc := nefEeSubscForUe1
+	sub := nefCtx.NewNefEeSub(&eeSubsc)
+	sub.SouthboundSubs = []*nef_context.NefEeSouthboundSub{
+		{
+			NfType:       models.NfType_AMF,
+			UeIdentity:   "imsi-208930000000001",
+			SubID:        "amf-ee1",
+			NotifCorreID: "2",
+		},
+		{
+			NfType:       models.NfType_UDM,
+			UeIdentity:   "anyUE",
+			SubID:        "ee1",
+			NotifCorreID: "3",
+		},
+	}
+	nefCtx.AddNefEeSub(sub)
+	defer func() {
+		nefCtx.DeleteNefEeSub(sub.SubID)
+		nefCtx.ResetCorreID()
+	}()
+
+	for _, expectedStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+		nefApp.Processor().DeleteNefEeSubscription(c, sub.SubID)
+		require.Equal(t, expectedStatus, httpRecorder.Code)
+	}
+
+	require.Nil(t, nefCtx.GetNefEeSub(sub.SubID))
+	require.Equal(t, []string{
+		"/namf-evts/v1/subscriptions/amf-ee1",
+		"/nudm-ee/v1/anyUE/ee-subscriptions/ee1",
+	}, deleted)
+}
+
+func TestNefEeNotification(t *testing.T) {
+	notifStub1 := initAFNotificationStub("http://127.0.0.100:8000", "/nef-ee/notify1", http.StatusNoContent)
+	defer gock.Remove(notifStub1)
+	notifStub2 := initAFNotificationStub("http://127.0.0.100:8000", "/nef-ee/notify2", http.StatusNoContent)
+	defer gock.Remove(notifStub2)
+	var notifMu sync.Mutex
+	notifBodies := make(map[string][]byte)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/nef-ee/") {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			notifMu.Lock()
+			notifBodies[request.URL.Path] = body
+			notifMu.Unlock()
+		}
+	})
+	defer gock.Observe(nil)
+	notifBody := func(path string) []byte {
+		notifMu.Lock()
+		defer notifMu.Unlock()
+		return notifBodies[path]
+	}
+
+	nefCtx := nefApp.Context()
+	eeSubsc1 := nefEeSubscForUe1
+	sub1 := nefCtx.NewNefEeSub(&eeSubsc1)
+	sub1.SouthboundSubs = []*nef_context.NefEeSouthboundSub{
+		{
+			NfType:       models.NfType_AMF,
+			UeIdentity:   "imsi-208930000000001",
+			SubID:        "amf-ee1",
+			NotifCorreID: "11",
+		},
+	}
+	nefCtx.AddNefEeSub(sub1)
+	// Communication failures of any UE
+	eeSubsc2 := nef_models.NefEventExposureSubsc{
+		EventsSubs: []nef_models.NefEventSubs{
+			{
+				Event: nef_models.NefEvent_COMMUNICATION_FAILURE,
+				EventFilter: &nef_models.NefEventFilter{
+					TgtUe: &nef_models.TargetUeIdentification{AnyUeId: true},
+				},
+			},
+		},
+		NotifUri: "http://127.0.0.100:8000/nef-ee/notify2",
+		NotifId:  "notif2",
+	}
+	sub2 := nefCtx.NewNefEeSub(&eeSubsc2)
+	sub2.SouthboundSubs = []*nef_context.NefEeSouthboundSub{
+		{
+			NfType:       models.NfType_SMF,
+			UeIdentity:   "anyUE",
+			SubID:        "smf-ee1",
+			NotifCorreID: "12",
+		},
+	}
+	nefCtx.AddNefEeSub(sub2)
+	defer func() {
+		nefCtx.DeleteNefEeSub(sub1.SubID)
+		nefCtx.DeleteNefEeSub(sub2.SubID)
+		nefCtx.ResetCorreID()
+	}()
+
+	timeStamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	location := &models.UserLocation{
+		NrLocation: &models.NrLocation{
+			Tai: &models.Tai{Tac: "000001"},
+		},
+	}
+	// The communication failure is reported by the AMF subscription of sub1,
+	// so it isn't notified to sub2
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().AmfEeNotification(c, "11", &models.AmfEventNotification{
+		ReportList: []models.AmfEventReport{
+			{
+				Type:      models.AmfEventType_LOCATION_REPORT,
+				TimeStamp: &timeStamp,
+				Supi:      "imsi-208930000000001",
+				Location:  location,
+			},
+			{
+				Type:        models.AmfEventType_COMMUNICATION_FAILURE_REPORT,
+				TimeStamp:   &timeStamp,
+				Supi:        "imsi-208930000000001",
+				CommFailure: &models.CommunicationFailure{NasReleaseCode: "1"},
+			},
+		},
+	})
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	httpRecorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().SmfEeNotification(c, "12", &models.NsmfEventExposureNotification{
+		NotifId: "12",
+		EventNotifs: []models.EventNotification{
+			{
+				Event:     smfEventCommFail,
+				TimeStamp: &timeStamp,
+				Supi:      "imsi-208930000000002",
+			},
+		},
+	})
+	require.Equal(t, http.StatusNoContent, httpRecorder.Code)
+
+	require.Eventually(t, func() bool {
+		return notifBody("/nef-ee/notify1") != nil && notifBody("/nef-ee/notify2") != nil
+	}, time.Second, 10*time.Millisecond)
+	assertJSONBodyEqual(t, &nef_models.NefEventExposureNotif{
+		NotifId: "notif1",
+		EventNotifs: []nef_models.NefEventNotification{
+			{
+				Event:     nef_models.NefEvent_UE_MOBILITY,
+				TimeStamp: &timeStamp,
+				Supi:      "imsi-208930000000001",
+				Location:  location,
+			},
+		},
+	}, notifBody("/nef-ee/notify1"))
+	assertJSONBodyEqual(t, &nef_models.NefEventExposureNotif{
+		NotifId: "notif2",
+		EventNotifs: []nef_models.NefEventNotification{
+			{
+				Event:     nef_models.NefEvent_COMMUNICATION_FAILURE,
+				TimeStamp: &timeStamp,
+				Supi:      "imsi-208930000000002",
+			},
+		},
+	}, notifBody("/nef-ee/notify2"))
+
+	httpRecorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().AmfEeNotification(c, "99", &models.AmfEventNotification{})
+	require.Equal(t, http.StatusNotFound, httpRecorder.Code)
+}
+
+func TestNotifyNefEvents(t *testing.T) {
+	notifStub1 := initAFNotificationStub("http://127.0.0.100:8000", "/nef-ee/notify1", http.StatusNoContent)
+	defer gock.Remove(notifStub1)
+	notifStub2 := initAFNotificationStub("http://127.0.0.100:8000", "/nef-ee/notify2", http.StatusNoContent)
+	defer gock.Remove(notifStub2)
+	notifBodies := make(map[string][]byte)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/nef-ee/") {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			notifBodies[request.URL.Path] = body
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	// Subscription of UE1 is removed after a report
+	eeSubsc1 := nefEeSubscForUe1
+	eeSubsc1.EventsRepInfo = &nef_models.ReportingInformation{MaxReportNbr: 1}
+	sub1 := nefCtx.NewNefEeSub(&eeSubsc1)
+	nefCtx.AddNefEeSub(sub1)
+	// Subscription of any UE
+	eeSubsc2 := nef_models.NefEventExposureSubsc{
+		EventsSubs: []nef_models.NefEventSubs{
+			{
+				Event: nef_models.NefEvent_COMMUNICATION_FAILURE,
+				EventFilter: &nef_models.NefEventFilter{
+					TgtUe: &nef_models.TargetUeIdentification{AnyUeId: true},
+				},
+			},
+		},
+		NotifUri: "http://127.0.0.100:8000/nef-ee/notify2",
+		NotifId:  "notif2",
+	}
+	sub2 := nefCtx.NewNefEeSub(&eeSubsc2)
+	nefCtx.AddNefEeSub(sub2)
+	defer func() {
+		nefCtx.DeleteNefEeSub(sub1.SubID)
+		nefCtx.DeleteNefEeSub(sub2.SubID)
+		nefCtx.ResetCorreID()
+	}()
+
+	timeStamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ue1Mobility := nef_models.NefEventNotification{
+		Event:     nef_models.NefEvent_UE_MOBILITY,
+		TimeStamp: &timeStamp,
+		Supi:      "imsi-208930000000001",
+		Location: &models.UserLocation{
+			NrLocation: &models.NrLocation{
+				Tai: &models.Tai{Tac: "000001"},
+			},
+		},
+	}
+	ue2Mobility := ue1Mobility
+	ue2Mobility.Supi = "imsi-208930000000002"
+	ue2CommFailure := nef_models.NefEventNotification{
+		Event:       nef_models.NefEvent_COMMUNICATION_FAILURE,
+		TimeStamp:   &timeStamp,
+		Supi:        "imsi-208930000000002",
+		CommFailure: &models.CommunicationFailure{NasReleaseCode: "1"},
+	}
+
+	nefApp.Processor().notifyNefEvents([]nef_models.NefEventNotification{
+		ue1Mobility, ue2Mobility, ue2CommFailure,
+	})
+
+	assertJSONBodyEqual(t, &nef_models.NefEventExposureNotif{
+		NotifId:     "notif1",
+		EventNotifs: []nef_models.NefEventNotification{ue1Mobility},
+	}, notifBodies["/nef-ee/notify1"])
+	assertJSONBodyEqual(t, &nef_models.NefEventExposureNotif{
+		NotifId:     "notif2",
+		EventNotifs: []nef_models.NefEventNotification{ue2CommFailure},
+	}, notifBodies["/nef-ee/notify2"])
+
+	require.Nil(t, nefCtx.GetNefEeSub(sub1.SubID))
+	require.NotNil(t, nefCtx.GetNefEeSub(sub2.SubID))
+}
+
+func initAMFEeCreateStub(amfSubID string) gock.Mock {
+	req := gock.New("http://127.0.0.11:8000/namf-evts/v1")
+	req.Post("/subscriptions").
+		Persist().
+		Reply(http.StatusCreated).
+		JSON(models.AmfCreatedEventSubscription{SubscriptionId: amfSubID})
+	return req.Mock
+}
+
+func initAMFEeDeleteStub(amfSubID string) gock.Mock {
+	req := gock.New("http://127.0.0.11:8000/namf-evts/v1")
+	req.Delete("/subscriptions/" + amfSubID).
+		Persist().
+		Reply(http.StatusNoContent)
+	return req.Mock
+}
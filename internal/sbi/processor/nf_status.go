@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+// nfStatusSubTypes are the NF types whose URIs are cached by the consumer
+var nfStatusSubTypes = []models.NfType{
+	models.NfType_PCF,
+	models.NfType_UDR,
+	models.NfType_BSF,
+}
+
+const (
+	// The NF status subscriptions are checked at nfStatusSubCheckInterval, and extended
+	// by nfStatusSubValidity if they expire within nfStatusSubRenewMargin
+	nfStatusSubCheckInterval = time.Minute
+	nfStatusSubRenewMargin   = 5 * time.Minute
+	nfStatusSubValidity      = 24 * time.Hour
+)
+
+// SubscribeNfStatus subscribes to the NRF for the status changes of the PCF, UDR and BSF,
+// so that their cached URIs are dropped when they are deregistered or changed.
+func (p *Processor) SubscribeNfStatus() {
+	for _, nfType := range nfStatusSubTypes {
+		p.subscribeNfStatus(nfType)
+	}
+}
+
+func (p *Processor) subscribeNfStatus(nfType models.NfType) {
+	subID, validityTime, err := p.Consumer().SubscribeNFStatus(nfType, p.genNfStatusNotificationUri(nfType))
+	if err != nil {
+		logger.ProcessorLog.Warnf("Subscribe to NF status of %s failed: %+v", nfType, err)
+		return
+	}
+	p.Context().SetNfStatusSubID(nfType, subID)
+	if validityTime != nil {
+		p.Context().SetNfStatusSubValidityTime(nfType, *validityTime)
+	}
+	logger.ProcessorLog.Infof("NF status of %s is subscribed: subID[%s]", nfType, subID)
+}
+
+// KeepNfStatusSubscriptions renews the NF status subscriptions before the NRF removes them
+// on expiry, until ctx is done.
+func (p *Processor) KeepNfStatusSubscriptions(ctx context.Context) {
+	ticker := time.NewTicker(nfStatusSubCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.renewNfStatusSubscriptions()
+		}
+	}
+}
+
+func (p *Processor) renewNfStatusSubscriptions() {
+	nefCtx := p.Context()
+	for nfType, subID := range nefCtx.NfStatusSubIDs() {
+		expiry, ok := nefCtx.NfStatusSubValidityTime(nfType)
+		if !ok || time.Until(expiry) > nfStatusSubRenewMargin {
+			continue
+		}
+		validityTime, err := p.Consumer().UpdateNFStatusSubscription(subID, time.Now().Add(nfStatusSubValidity))
+		if err != nil {
+			// The subscription may have been removed by the NRF already
+			logger.ProcessorLog.Warnf("Renew NF status subscription of %s failed: %+v, subscribe again", nfType, err)
+			p.subscribeNfStatus(nfType)
+			continue
+		}
+		nefCtx.SetNfStatusSubValidityTime(nfType, *validityTime)
+		logger.ProcessorLog.Debugf("NF status subscription of %s is renewed until %s", nfType, validityTime)
+	}
+}
+
+func (p *Processor) UnsubscribeNfStatus() {
+	for nfType, subID := range p.Context().NfStatusSubIDs() {
+		if err := p.Consumer().UnsubscribeNFStatus(subID); err != nil {
+			logger.ProcessorLog.Warnf("Unsubscribe to NF status of %s failed: %+v", nfType, err)
+		}
+		p.Context().DeleteNfStatusSubID(nfType)
+	}
+}
+
+func (p *Processor) NfStatusNotification(
+	c *gin.Context,
+	nfType string,
+	notifData *models.NotificationData,
+) {
+	logger.ProcessorLog.Infof("NfStatusNotification - nfType[%s], event[%s], nfInstanceUri[%s]",
+		nfType, notifData.Event, notifData.NfInstanceUri)
+
+	if _, ok := p.Context().NfStatusSubIDs()[models.NfType(nfType)]; !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	switch notifData.Event {
+	case models.NotificationEventType_DEREGISTERED,
+		models.NotificationEventType_PROFILE_CHANGED:
+		// The NF serving the NEF may be replaced, so the NF is discovered again at next use
+		switch models.NfType(nfType) {
+		case models.NfType_PCF:
+			p.Consumer().InvalidatePcfPolicyAuthUri()
+			p.Consumer().InvalidatePcfBdtUri()
+			p.dropPcfUriOfSubs(notifData.NfInstanceUri)
+		case models.NfType_UDR:
+			p.Consumer().InvalidateUdrDrUri()
+		case models.NfType_BSF:
+			p.Consumer().InvalidateBsfMgmtUri()
+		}
+	default:
+		logger.ProcessorLog.Debugf("Ignore NF status event[%s]", notifData.Event)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// dropPcfUriOfSubs drops the URI of the PCF of nfInstanceUri from the traffic influence
+// subscriptions, so that the PCF holding their app sessions is discovered again at next use.
+func (p *Processor) dropPcfUriOfSubs(nfInstanceUri string) {
+	pcfID := nfInstanceUri[strings.LastIndex(nfInstanceUri, "/")+1:]
+	if pcfID == "" {
+		return
+	}
+
+	for _, af := range p.Context().GetAfs() {
+		af.Mu.Lock()
+		changed := false
+		for _, sub := range af.Subs {
+			if sub.PcfID == pcfID && sub.PcfUri != "" {
+				sub.Log.Infof("PCF[%s] is changed, drop its URI[%s]", pcfID, sub.PcfUri)
+				sub.PcfUri = ""
+				changed = true
+			}
+		}
+		if changed {
+			p.Context().StoreAf(af)
+		}
+		af.Mu.Unlock()
+	}
+}
+
+func (p *Processor) genNfStatusNotificationUri(nfType models.NfType) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/nrf-nf-status/" + string(nfType)
+}
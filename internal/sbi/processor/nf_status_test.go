@@ -0,0 +1,347 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSubscribeNfStatus(t *testing.T) {
+	nrfStub := initNRFNfmSubscriptionStub()
+	defer gock.Remove(nrfStub)
+
+	nefCtx := nefApp.Context()
+	nefApp.Processor().SubscribeNfStatus()
+	defer func() {
+		for nfType := range nefCtx.NfStatusSubIDs() {
+			nefCtx.DeleteNfStatusSubID(nfType)
+		}
+	}()
+
+	require.Equal(t, map[models.NfType]string{
+		models.NfType_PCF: "nrf-sub-1",
+		models.NfType_UDR: "nrf-sub-1",
+		models.NfType_BSF: "nrf-sub-1",
+	}, nefCtx.NfStatusSubIDs())
+}
+
+func TestNfStatusNotification(t *testing.T) {
+	nefCtx := nefApp.Context()
+	pcfPaUri, pcfBdtUri, udrDrUri := nefCtx.PcfPaUri(), nefCtx.PcfBdtUri(), nefCtx.UdrDrUri()
+	nefCtx.SetNfStatusSubID(models.NfType_PCF, "nrf-sub-pcf")
+	defer func() {
+		nefCtx.DeleteNfStatusSubID(models.NfType_PCF)
+		nefCtx.SetPcfPaUri(pcfPaUri)
+		nefCtx.SetPcfBdtUri(pcfBdtUri)
+		nefCtx.SetUdrDrUri(udrDrUri)
+	}()
+	nefCtx.SetPcfPaUri("http://127.0.0.7:8000/npcf-policyauthorization/v1")
+	nefCtx.SetPcfBdtUri("http://127.0.0.7:8000/npcf-bdtpolicycontrol/v1")
+	nefCtx.SetUdrDrUri("http://127.0.0.4:8000/nudr-dr/v1")
+
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	tiSub1, tiSub2 := tiSub3ForAf1, tiSub3ForAf1
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub1)
+	afSub1.AppSessID = "12345"
+	afSub1.PcfUri = "http://127.0.0.7:8000"
+	afSub1.PcfID = "pcf1"
+	af1.Subs[afSub1.SubID] = afSub1
+	afSub2 := af1.NewSub(nefCtx.NewCorreID(), &tiSub2)
+	afSub2.AppSessID = "23456"
+	afSub2.PcfUri = "http://127.0.0.9:8000"
+	afSub2.PcfID = "pcf2"
+	af1.Subs[afSub2.SubID] = afSub2
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer nefCtx.DeleteAf("af1")
+
+	testCases := []struct {
+		description        string
+		nfType             string
+		notifData          models.NotificationData
+		expectedStatusCode int
+		expectedPcfPaUri   string
+	}{
+		{
+			description: "TC1: Unsubscribed NF type",
+			nfType:      "UDM",
+			notifData: models.NotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/udm1",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedPcfPaUri:   "http://127.0.0.7:8000/npcf-policyauthorization/v1",
+		},
+		{
+			description: "TC2: Registration of another PCF, should keep the cached URI",
+			nfType:      "PCF",
+			notifData: models.NotificationData{
+				Event:         models.NotificationEventType_REGISTERED,
+				NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/pcf2",
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedPcfPaUri:   "http://127.0.0.7:8000/npcf-policyauthorization/v1",
+		},
+		{
+			description: "TC3: Deregistration of PCF, should invalidate the cached PCF URIs",
+			nfType:      "PCF",
+			notifData: models.NotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/pcf1",
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedPcfPaUri:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			notifData := tc.notifData
+			nefApp.Processor().NfStatusNotification(c, tc.nfType, &notifData)
+			require.Equal(t, tc.expectedStatusCode, httpRecorder.Code)
+			require.Equal(t, tc.expectedPcfPaUri, nefCtx.PcfPaUri())
+		})
+	}
+
+	require.Empty(t, nefCtx.PcfBdtUri())
+	require.Equal(t, "http://127.0.0.4:8000/nudr-dr/v1", nefCtx.UdrDrUri())
+
+	// Only the app sessions at the deregistered PCF are affected
+	require.Empty(t, afSub1.PcfUri)
+	require.Equal(t, "http://127.0.0.9:8000", afSub2.PcfUri)
+
+	// The PCF is discovered again by its NF instance ID at next use
+	initNRFDiscPCFStub()
+	defer gock.Off()
+	require.Equal(t, "http://127.0.0.7:8000", nefApp.Processor().pcfUriOfSub(afSub1))
+	require.Equal(t, "http://127.0.0.7:8000", afSub1.PcfUri)
+}
+
+func TestRenewNfStatusSubscriptions(t *testing.T) {
+	defer gock.Off()
+	initNRFNfmUpdateSubscriptionStub("nrf-sub-pcf", http.StatusOK)
+	initNRFNfmUpdateSubscriptionStub("nrf-sub-udr", http.StatusNotFound)
+	subStub := initNRFNfmSubscriptionStub()
+	defer gock.Remove(subStub)
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		for nfType := range nefCtx.NfStatusSubIDs() {
+			nefCtx.DeleteNfStatusSubID(nfType)
+		}
+	}()
+	now := time.Now()
+	nefCtx.SetNfStatusSubID(models.NfType_PCF, "nrf-sub-pcf")
+	nefCtx.SetNfStatusSubValidityTime(models.NfType_PCF, now.Add(time.Minute))
+	nefCtx.SetNfStatusSubID(models.NfType_UDR, "nrf-sub-udr")
+	nefCtx.SetNfStatusSubValidityTime(models.NfType_UDR, now.Add(time.Minute))
+	nefCtx.SetNfStatusSubID(models.NfType_BSF, "nrf-sub-bsf")
+	nefCtx.SetNfStatusSubValidityTime(models.NfType_BSF, now.Add(time.Hour))
+
+	nefApp.Processor().renewNfStatusSubscriptions()
+
+	// The expired subscription of UDR is replaced, and the one of BSF is not yet renewed
+	require.Equal(t, map[models.NfType]string{
+		models.NfType_PCF: "nrf-sub-pcf",
+		models.NfType_UDR: "nrf-sub-1",
+		models.NfType_BSF: "nrf-sub-bsf",
+	}, nefCtx.NfStatusSubIDs())
+	pcfExpiry, ok := nefCtx.NfStatusSubValidityTime(models.NfType_PCF)
+	require.True(t, ok)
+	require.True(t, pcfExpiry.After(now.Add(time.Hour)))
+	_, ok = nefCtx.NfStatusSubValidityTime(models.NfType_UDR)
+	require.False(t, ok)
+	bsfExpiry, ok := nefCtx.NfStatusSubValidityTime(models.NfType_BSF)
+	require.True(t, ok)
+	require.True(t, bsfExpiry.Equal(now.Add(time.Hour)))
+}
+
+func TestUpdateNFInstance(t *testing.T) {
+	defer gock.Off()
+	var nrfReqs []string
+	var patchPaths []string
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if !strings.Contains(request.URL.Path, "/nnrf-nfm/") {
+			return
+		}
+		nrfReqs = append(nrfReqs, request.Method+" "+request.URL.Path)
+		if request.Method == http.MethodPatch {
+			var patchItems []models.PatchItem
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&patchItems))
+			for _, item := range patchItems {
+				patchPaths = append(patchPaths, item.Path)
+			}
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	cfg := nefApp.Config()
+	nfInstID, serviceList := nefCtx.NfInstID(), cfg.ServiceList()
+	defer func() {
+		nefCtx.SetNfInstID(nfInstID)
+		cfg.SetServiceList(serviceList)
+		for nfType := range nefCtx.NfStatusSubIDs() {
+			nefCtx.DeleteNfStatusSubID(nfType)
+		}
+	}()
+
+	initNRFNfmStub()
+	require.NoError(t, nefApp.Consumer().RegisterNFInstance(context.Background()))
+
+	testCases := []struct {
+		description        string
+		serviceList        []factory.Service
+		nrfStatusCode      int
+		expectedPatchPaths []string
+		expectedNrfReqs    []string
+	}{
+		{
+			description:        "TC1: Unchanged NF profile, should only refresh the NF status",
+			serviceList:        serviceList,
+			nrfStatusCode:      http.StatusOK,
+			expectedPatchPaths: []string{"/nfStatus"},
+			expectedNrfReqs: []string{
+				"PATCH /nnrf-nfm/v1/nf-instances/12345",
+			},
+		},
+		{
+			description: "TC2: Added NF service, should push the NF services",
+			serviceList: append([]factory.Service{
+				{
+					ServiceName: factory.ServiceNefOam,
+				},
+			}, serviceList...),
+			nrfStatusCode:      http.StatusOK,
+			expectedPatchPaths: []string{"/nfStatus", "/nfServices"},
+			expectedNrfReqs: []string{
+				"PATCH /nnrf-nfm/v1/nf-instances/12345",
+			},
+		},
+		{
+			description:        "TC3: Unknown NF instance, should register again and subscribe to NF status",
+			serviceList:        serviceList,
+			nrfStatusCode:      http.StatusNotFound,
+			expectedPatchPaths: []string{"/nfStatus", "/nfServices"},
+			expectedNrfReqs: []string{
+				"PATCH /nnrf-nfm/v1/nf-instances/12345",
+				"PUT /nnrf-nfm/v1/nf-instances/12345",
+				"POST /nnrf-nfm/v1/subscriptions",
+				"POST /nnrf-nfm/v1/subscriptions",
+				"POST /nnrf-nfm/v1/subscriptions",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nrfReqs, patchPaths = nil, nil
+			cfg.SetServiceList(tc.serviceList)
+			nrfStub := initNRFNfmUpdateStub(tc.nrfStatusCode)
+			defer gock.Remove(nrfStub)
+			if tc.nrfStatusCode == http.StatusNotFound {
+				initNRFNfmStub()
+				subStub := initNRFNfmSubscriptionStub()
+				defer gock.Remove(subStub)
+			}
+
+			require.NoError(t, nefApp.Consumer().UpdateNFInstance(context.Background()))
+			require.Equal(t, tc.expectedPatchPaths, patchPaths)
+			require.Equal(t, tc.expectedNrfReqs, nrfReqs)
+		})
+	}
+
+	require.Equal(t, map[models.NfType]string{
+		models.NfType_PCF: "nrf-sub-1",
+		models.NfType_UDR: "nrf-sub-1",
+		models.NfType_BSF: "nrf-sub-1",
+	}, nefCtx.NfStatusSubIDs())
+}
+
+func TestHeartbeatNFInstance(t *testing.T) {
+	nrfStub := initNRFNfmUpdateStub(http.StatusOK)
+	defer gock.Remove(nrfStub)
+	var mu sync.Mutex
+	var heartbeats int
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.Method == http.MethodPatch && strings.Contains(request.URL.Path, "/nnrf-nfm/") {
+			mu.Lock()
+			heartbeats++
+			mu.Unlock()
+		}
+	})
+	defer gock.Observe(nil)
+
+	// The heartBeatTimer of 1s is assigned by the NRF in the response
+	require.NoError(t, nefApp.Consumer().UpdateNFInstance(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		nefApp.Consumer().HeartbeatNFInstance(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return heartbeats >= 3
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "heartbeat is not stopped after the context is done")
+	}
+}
+
+func initNRFNfmUpdateStub(statusCode int) gock.Mock {
+	req := gock.New("http://127.0.0.10:8000/nnrf-nfm/v1")
+	req.Patch("/nf-instances/.*").
+		Persist().
+		Reply(statusCode).
+		JSON(models.NfProfile{
+			NfInstanceId:   "12345",
+			NfType:         models.NfType_NEF,
+			NfStatus:       models.NfStatus_REGISTERED,
+			HeartBeatTimer: 1,
+		})
+	return req.Mock
+}
+
+func initNRFNfmUpdateSubscriptionStub(subID string, statusCode int) {
+	gock.New("http://127.0.0.10:8000/nnrf-nfm/v1").
+		Patch("/subscriptions/" + subID).
+		Reply(statusCode).
+		JSON(models.NrfSubscriptionData{
+			SubscriptionId: subID,
+		})
+}
+
+func initNRFNfmSubscriptionStub() gock.Mock {
+	req := gock.New("http://127.0.0.10:8000/nnrf-nfm/v1")
+	req.Post("/subscriptions").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.10:8000/nnrf-nfm/v1/subscriptions/nrf-sub-1").
+		JSON(models.NrfSubscriptionData{
+			SubscriptionId: "nrf-sub-1",
+		})
+	return req.Mock
+}
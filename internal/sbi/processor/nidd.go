@@ -0,0 +1,517 @@
+package processor
+
+import (
+	"net/http"
+	"time"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Default maximum packet size of the NIDD (TS 29.122)
+	niddDefaultMaxPacketSize int32 = 1358
+	// Maximum number of the downlink data buffered per NIDD configuration
+	niddMaxBufferedDlData = 16
+)
+
+func (p *Processor) GetNiddConfigurations(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.NiddLog.Infof("GetNiddConfigurations - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	niddCfgs := []nef_models.NiddConfiguration{}
+	for _, cfg := range af.NiddCfgs {
+		niddCfgs = append(niddCfgs, *cfg.NiddCfg)
+	}
+	c.JSON(http.StatusOK, &niddCfgs)
+}
+
+func (p *Processor) PostNiddConfiguration(
+	c *gin.Context,
+	scsAsID string,
+	niddCfg *nef_models.NiddConfiguration,
+) {
+	logger.NiddLog.Infof("PostNiddConfiguration - scsAsID[%s]", scsAsID)
+
+	if rsp := validateNiddConfiguration(niddCfg); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	if niddCfg.MaximumPacketSize == 0 {
+		niddCfg.MaximumPacketSize = niddDefaultMaxPacketSize
+	}
+	niddCfg.Status = nef_models.NiddStatus_ACTIVE
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg := af.NewNiddCfg(niddCfg)
+	cfg.Gpsi = genGpsi(niddCfg.ExternalId, niddCfg.Msisdn)
+	niddCfg.Self = p.genNiddConfigurationURI(scsAsID, cfg.ConfigID)
+
+	af.NiddCfgs[cfg.ConfigID] = cfg
+	af.Log.Infoln("NIDD configuration is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", niddCfg.Self)
+	c.JSON(http.StatusCreated, niddCfg)
+}
+
+func (p *Processor) GetIndividualNiddConfiguration(
+	c *gin.Context,
+	scsAsID, configID string,
+) {
+	logger.NiddLog.Infof("GetIndividualNiddConfiguration - scsAsID[%s], configID[%s]", scsAsID, configID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, cfg.NiddCfg)
+}
+
+func (p *Processor) PatchIndividualNiddConfiguration(
+	c *gin.Context,
+	scsAsID, configID string,
+	niddCfgPatch *nef_models.NiddConfigurationPatch,
+) {
+	logger.NiddLog.Infof("PatchIndividualNiddConfiguration - scsAsID[%s], configID[%s]", scsAsID, configID)
+
+	if niddCfgPatch.Duration != "" {
+		if rsp := validateNiddDuration(niddCfgPatch.Duration); rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+	}
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if niddCfgPatch.Duration != "" {
+		cfg.NiddCfg.Duration = niddCfgPatch.Duration
+	}
+	if niddCfgPatch.ReliableDataService != nil {
+		cfg.NiddCfg.ReliableDataService = *niddCfgPatch.ReliableDataService
+	}
+	if niddCfgPatch.NotificationDestination != "" {
+		cfg.NiddCfg.NotificationDestination = niddCfgPatch.NotificationDestination
+	}
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, cfg.NiddCfg)
+}
+
+// DeleteIndividualNiddConfiguration deletes the NIDD configuration with the
+// downlink data still buffered for the UE.
+func (p *Processor) DeleteIndividualNiddConfiguration(
+	c *gin.Context,
+	scsAsID, configID string,
+) {
+	logger.NiddLog.Infof("DeleteIndividualNiddConfiguration - scsAsID[%s], configID[%s]", scsAsID, configID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if n := len(cfg.BufferedDlDataIDs()); n > 0 {
+		cfg.Log.Infof("%d buffered downlink data are discarded", n)
+	}
+	delete(af.NiddCfgs, configID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (p *Processor) GetNiddDownlinkDataDeliveries(
+	c *gin.Context,
+	scsAsID, configID string,
+) {
+	logger.NiddLog.Infof("GetNiddDownlinkDataDeliveries - scsAsID[%s], configID[%s]", scsAsID, configID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	dlDatas := []nef_models.NiddDownlinkDataTransfer{}
+	for _, dlData := range cfg.DlDatas {
+		dlDatas = append(dlDatas, *dlData)
+	}
+	c.JSON(http.StatusOK, &dlDatas)
+}
+
+// PostNiddDownlinkDataDelivery delivers the downlink data to the UE via the SMF if
+// the UE has the NEF-anchored PDU session, or buffers it until the PDU session
+// is established.
+func (p *Processor) PostNiddDownlinkDataDelivery(
+	c *gin.Context,
+	scsAsID, configID string,
+	dlData *nef_models.NiddDownlinkDataTransfer,
+) {
+	logger.NiddLog.Infof("PostNiddDownlinkDataDelivery - scsAsID[%s], configID[%s]", scsAsID, configID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if rsp := validateNiddDownlinkDataTransfer(cfg, dlData); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	smCtx := p.Context().FindSmContext(cfg.Gpsi, cfg.NiddCfg.Dnn)
+	if smCtx == nil {
+		if len(cfg.BufferedDlDataIDs()) >= niddMaxBufferedDlData {
+			pd := openapi.ProblemDetailsSystemFailure("Buffer of downlink data is full")
+			c.JSON(int(pd.Status), pd)
+			return
+		}
+		dlData.DeliveryStatus = nef_models.DeliveryStatus_BUFFERING
+	} else {
+		rspStatus, rspBody := p.Consumer().DeliverMtData(smCtx.CreateData.SmfId, smCtx.CreateData.PduSessionRef,
+			&nef_models.SmContextDeliverData{Data: dlData.Data})
+		if rspStatus != http.StatusOK &&
+			rspStatus != http.StatusNoContent {
+			c.JSON(rspStatus, rspBody)
+			return
+		}
+		dlData.DeliveryStatus = nef_models.DeliveryStatus_SUCCESS
+	}
+
+	dlID := cfg.NewDlDataID()
+	dlData.ExternalId = cfg.NiddCfg.ExternalId
+	dlData.Msisdn = cfg.NiddCfg.Msisdn
+	dlData.Self = p.genNiddDownlinkDataDeliveryURI(scsAsID, configID, dlID)
+	cfg.DlDatas[dlID] = dlData
+	cfg.Log.Infof("Downlink data[%s] is %s", dlID, dlData.DeliveryStatus)
+	p.Context().StoreAf(af)
+
+	c.Header("Location", dlData.Self)
+	c.JSON(http.StatusCreated, dlData)
+}
+
+func (p *Processor) GetIndividualNiddDownlinkDataDelivery(
+	c *gin.Context,
+	scsAsID, configID, dlID string,
+) {
+	logger.NiddLog.Infof("GetIndividualNiddDownlinkDataDelivery - scsAsID[%s], configID[%s], dlID[%s]",
+		scsAsID, configID, dlID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	dlData, ok := cfg.DlDatas[dlID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Downlink data delivery is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, dlData)
+}
+
+// PutIndividualNiddDownlinkDataDelivery replaces the downlink data which is still buffered
+func (p *Processor) PutIndividualNiddDownlinkDataDelivery(
+	c *gin.Context,
+	scsAsID, configID, dlID string,
+	dlData *nef_models.NiddDownlinkDataTransfer,
+) {
+	logger.NiddLog.Infof("PutIndividualNiddDownlinkDataDelivery - scsAsID[%s], configID[%s], dlID[%s]",
+		scsAsID, configID, dlID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	oldDlData, ok := cfg.DlDatas[dlID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Downlink data delivery is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	if oldDlData.DeliveryStatus != nef_models.DeliveryStatus_BUFFERING || cfg.IsDelivering(dlID) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Downlink data isn't buffered")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	if rsp := validateNiddDownlinkDataTransfer(cfg, dlData); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	dlData.ExternalId = oldDlData.ExternalId
+	dlData.Msisdn = oldDlData.Msisdn
+	dlData.Self = oldDlData.Self
+	dlData.DeliveryStatus = oldDlData.DeliveryStatus
+	cfg.DlDatas[dlID] = dlData
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, dlData)
+}
+
+func (p *Processor) DeleteIndividualNiddDownlinkDataDelivery(
+	c *gin.Context,
+	scsAsID, configID, dlID string,
+) {
+	logger.NiddLog.Infof("DeleteIndividualNiddDownlinkDataDelivery - scsAsID[%s], configID[%s], dlID[%s]",
+		scsAsID, configID, dlID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	cfg, ok := af.NiddCfgs[configID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	if _, ok = cfg.DlDatas[dlID]; !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Downlink data delivery is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	delete(cfg.DlDatas, dlID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// deliverBufferedNiddData delivers the downlink data buffered for the UE after
+// the SM context is created, and reports the delivery status to the AF.
+func (p *Processor) deliverBufferedNiddData(smCtx *nef_context.SmContext) {
+	af, cfg := p.Context().FindAfNiddCfg(smCtx.CreateData.Gpsi, smCtx.CreateData.Dnn)
+	if cfg == nil {
+		return
+	}
+
+	af.Mu.RLock()
+	dlIDs := cfg.BufferedDlDataIDs()
+	af.Mu.RUnlock()
+
+	for _, dlID := range dlIDs {
+		// The data is marked under the lock, so that it's not delivered again
+		// by another SM context of the UE, or replaced by the AF meanwhile
+		af.Mu.Lock()
+		dlData := cfg.StartDelivery(dlID)
+		af.Mu.Unlock()
+		if dlData == nil {
+			// Deleted by the AF, or being delivered already
+			continue
+		}
+
+		deliveryStatus := nef_models.DeliveryStatus_SUCCESS
+		rspStatus, _ := p.Consumer().DeliverMtData(smCtx.CreateData.SmfId, smCtx.CreateData.PduSessionRef,
+			&nef_models.SmContextDeliverData{Data: dlData.Data})
+		if rspStatus != http.StatusOK &&
+			rspStatus != http.StatusNoContent {
+			cfg.Log.Warnf("Deliver downlink data[%s] failed: status[%d]", dlID, rspStatus)
+			deliveryStatus = nef_models.DeliveryStatus_FAILURE
+		}
+
+		af.Mu.Lock()
+		cfg.FinishDelivery(dlID)
+		if af.NiddCfgs[cfg.ConfigID] != cfg || cfg.DlDatas[dlID] != dlData {
+			// Deleted by the AF during the delivery
+			af.Mu.Unlock()
+			continue
+		}
+		dlData.DeliveryStatus = deliveryStatus
+		notifDest := cfg.NiddCfg.NotificationDestination
+		dlNotif := &nef_models.NiddDownlinkDataDeliveryStatusNotification{
+			Niddconfiguration:        cfg.NiddCfg.Self,
+			ExternalId:               cfg.NiddCfg.ExternalId,
+			Msisdn:                   cfg.NiddCfg.Msisdn,
+			NiddDownlinkDataTransfer: dlData.Self,
+			DeliveryStatus:           deliveryStatus,
+		}
+		p.Context().StoreAf(af)
+		af.Mu.Unlock()
+
+		if err := p.Notifier().NiddNotifier.NotifyDownlinkDataDeliveryStatus(notifDest, dlNotif); err != nil {
+			cfg.Log.Errorf("Notify delivery status of downlink data[%s] failed: %+v", dlID, err)
+			continue
+		}
+		cfg.Log.Infof("Delivery status[%s] of downlink data[%s] is notified", deliveryStatus, dlID)
+	}
+}
+
+func validateNiddConfiguration(niddCfg *nef_models.NiddConfiguration) *HandlerResponse {
+	if niddCfg.NotificationDestination == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of notificationDestination")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if niddCfg.ExternalGroupId != "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("externalGroupId is not supported")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if (niddCfg.ExternalId == "") == (niddCfg.Msisdn == "") {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("One of externalId or msisdn shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if niddCfg.MaximumPacketSize < 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid maximumPacketSize")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if niddCfg.Duration != "" {
+		return validateNiddDuration(niddCfg.Duration)
+	}
+	return nil
+}
+
+func validateNiddDuration(duration string) *HandlerResponse {
+	t, err := time.Parse(time.RFC3339, duration)
+	if err != nil || t.Before(time.Now()) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid duration")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func validateNiddDownlinkDataTransfer(
+	cfg *nef_context.AfNiddConfiguration,
+	dlData *nef_models.NiddDownlinkDataTransfer,
+) *HandlerResponse {
+	if len(dlData.Data) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of data")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if len(dlData.Data) > int(cfg.NiddCfg.MaximumPacketSize) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Data exceeds maximumPacketSize")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if (dlData.ExternalId != "" && dlData.ExternalId != cfg.NiddCfg.ExternalId) ||
+		(dlData.Msisdn != "" && dlData.Msisdn != cfg.NiddCfg.Msisdn) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("UE is not configured for NIDD")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genNiddConfigurationURI(scsAsID, configID string) string {
+	// E.g. https://localhost:29505/3gpp-nidd/v1/{scsAsId}/configurations/{configurationId}
+	return p.Config().ServiceUri(factory.ServiceNidd) + "/" + scsAsID + "/configurations/" + configID
+}
+
+func (p *Processor) genNiddDownlinkDataDeliveryURI(scsAsID, configID, dlID string) string {
+	// E.g. https://localhost:29505/3gpp-nidd/v1/{scsAsId}/configurations/{configurationId}/
+	// downlink-data-deliveries/{downlinkDataDeliveryId}
+	return p.genNiddConfigurationURI(scsAsID, configID) + "/downlink-data-deliveries/" + dlID
+}
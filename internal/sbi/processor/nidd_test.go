@@ -0,0 +1,240 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var niddCfg1ForAf1 = nef_models.NiddConfiguration{
+	ExternalId:              "ue1@free5gc.org",
+	Dnn:                     "nidd",
+	NotificationDestination: "http://127.0.0.100:8000/nidd/notify",
+}
+
+var smCtxCreateDataForUe1 = nef_models.SmContextCreateData{
+	Supi:          "imsi-208930000000001",
+	Gpsi:          "extid-ue1@free5gc.org",
+	PduSessionId:  1,
+	Dnn:           "nidd",
+	SmfId:         "smf1",
+	PduSessionRef: "pdusess1",
+}
+
+func TestPostNiddConfiguration(t *testing.T) {
+	rspNiddCfg1 := niddCfg1ForAf1
+	rspNiddCfg1.Self = nefApp.Processor().genNiddConfigurationURI("af1", "1")
+	rspNiddCfg1.MaximumPacketSize = niddDefaultMaxPacketSize
+	rspNiddCfg1.Status = nef_models.NiddStatus_ACTIVE
+
+	niddCfgNoNotifDest := niddCfg1ForAf1
+	niddCfgNoNotifDest.NotificationDestination = ""
+
+	niddCfgBothUeIDs := niddCfg1ForAf1
+	niddCfgBothUeIDs.Msisdn = "0900000001"
+
+	testCases := []struct {
+		description      string
+		niddCfg          nef_models.NiddConfiguration
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful configuration, should be active",
+			niddCfg:     niddCfg1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspNiddCfg1.Self},
+				},
+				Body: &rspNiddCfg1,
+			},
+		},
+		{
+			description: "TC2: Absent of notificationDestination",
+			niddCfg:     niddCfgNoNotifDest,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Absent of notificationDestination",
+				},
+			},
+		},
+		{
+			description: "TC3: Both externalId and msisdn are included",
+			niddCfg:     niddCfgBothUeIDs,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "One of externalId or msisdn shall be included",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			niddCfg := tc.niddCfg
+			nefApp.Processor().PostNiddConfiguration(c, "af1", &niddCfg)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "extid-ue1@free5gc.org", af.NiddCfgs["1"].Gpsi)
+	af.Mu.RUnlock()
+}
+
+func TestPostNiddDownlinkDataDelivery(t *testing.T) {
+	initNRFDiscStub(models.NfType_SMF, "nsmf-nidd", "127.0.0.2")
+	smfStub := initSMFNiddDeliverStub("pdusess1", http.StatusNoContent)
+	defer gock.Remove(smfStub)
+	smfReqChan := make(chan *http.Request, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/deliver") {
+			smfReqChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	niddCfg := niddCfg1ForAf1
+	niddCfg.MaximumPacketSize = 8
+	niddCfg.Status = nef_models.NiddStatus_ACTIVE
+	cfg := af1.NewNiddCfg(&niddCfg)
+	cfg.Gpsi = "extid-ue1@free5gc.org"
+	af1.NiddCfgs[cfg.ConfigID] = cfg
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	testCases := []struct {
+		description      string
+		dlData           nef_models.NiddDownlinkDataTransfer
+		smCtx            bool
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Data exceeds maximumPacketSize",
+			dlData:      nef_models.NiddDownlinkDataTransfer{Data: []byte("123456789")},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Data exceeds maximumPacketSize",
+				},
+			},
+		},
+		{
+			description: "TC2: No PDU session of UE, should buffer data",
+			dlData:      nef_models.NiddDownlinkDataTransfer{Data: []byte("dl1")},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {nefApp.Processor().genNiddDownlinkDataDeliveryURI("af1", cfg.ConfigID, "1")},
+				},
+				Body: &nef_models.NiddDownlinkDataTransfer{
+					ExternalId:     "ue1@free5gc.org",
+					Self:           nefApp.Processor().genNiddDownlinkDataDeliveryURI("af1", cfg.ConfigID, "1"),
+					Data:           []byte("dl1"),
+					DeliveryStatus: nef_models.DeliveryStatus_BUFFERING,
+				},
+			},
+		},
+		{
+			description: "TC3: PDU session of UE is established, should deliver data to SMF",
+			dlData:      nef_models.NiddDownlinkDataTransfer{Data: []byte("dl2")},
+			smCtx:       true,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {nefApp.Processor().genNiddDownlinkDataDeliveryURI("af1", cfg.ConfigID, "2")},
+				},
+				Body: &nef_models.NiddDownlinkDataTransfer{
+					ExternalId:     "ue1@free5gc.org",
+					Self:           nefApp.Processor().genNiddDownlinkDataDeliveryURI("af1", cfg.ConfigID, "2"),
+					Data:           []byte("dl2"),
+					DeliveryStatus: nef_models.DeliveryStatus_SUCCESS,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if tc.smCtx {
+				createData := smCtxCreateDataForUe1
+				smCtx := nefCtx.NewSmContext(&createData)
+				defer nefCtx.DeleteSmContext(smCtx.SmContextID)
+			}
+
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			dlData := tc.dlData
+			nefApp.Processor().PostNiddDownlinkDataDelivery(c, "af1", cfg.ConfigID, &dlData)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.smCtx {
+				select {
+				case req := <-smfReqChan:
+					var mtData nef_models.SmContextDeliverData
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&mtData))
+					require.Equal(t, tc.dlData.Data, mtData.Data)
+				case <-time.After(time.Second):
+					t.Fatal("SMF is not requested")
+				}
+			}
+		})
+	}
+
+	af1.Mu.RLock()
+	require.Equal(t, []string{"1"}, cfg.BufferedDlDataIDs())
+	af1.Mu.RUnlock()
+}
+
+func initSMFNiddDeliverStub(pduSessionRef string, statusCode int) gock.Mock {
+	req := gock.New("http://127.0.0.2:8000/nsmf-nidd/v1")
+	req.Post("/pdu-sessions/" + pduSessionRef + "/deliver").
+		Persist().
+		Reply(statusCode)
+	return req.Mock
+}
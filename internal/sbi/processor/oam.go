@@ -3,9 +3,23 @@ package processor
 import (
 	"net/http"
 
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/openapi"
 	"github.com/gin-gonic/gin"
 )
 
 func (p *Processor) GetOamIndex(c *gin.Context) {
 	c.JSON(http.StatusOK, nil)
 }
+
+func (p *Processor) GetReconcileReport(c *gin.Context) {
+	logger.OamLog.Infof("GetReconcileReport")
+
+	rpt := p.Context().ReconcileReport()
+	if rpt == nil {
+		pd := openapi.ProblemDetailsDataNotFound("Reconciliation is not performed")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	c.JSON(http.StatusOK, rpt)
+}
@@ -0,0 +1,276 @@
+package processor
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (p *Processor) GetParameterProvisionSubscriptions(
+	c *gin.Context,
+	scsAsID string,
+) {
+	logger.PpLog.Infof("GetParameterProvisionSubscriptions - scsAsID[%s]", scsAsID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	ppConfigs := []nef_models.PpConfig{}
+	for _, sub := range af.PpSubs {
+		ppConfigs = append(ppConfigs, *sub.PpConfig)
+	}
+	c.JSON(http.StatusOK, &ppConfigs)
+}
+
+func (p *Processor) PostParameterProvisionSubscription(
+	c *gin.Context,
+	scsAsID string,
+	ppConfig *nef_models.PpConfig,
+) {
+	logger.PpLog.Infof("PostParameterProvisionSubscription - scsAsID[%s]", scsAsID)
+
+	if rsp := validatePpConfig(ppConfig); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(scsAsID)
+	if af == nil {
+		af = nefCtx.NewAf(scsAsID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub := af.NewPpSub(ppConfig)
+	sub.Gpsi = genGpsi(ppConfig.ExternalId, ppConfig.Msisdn)
+	// The parameters of the UE in the UDM would be overwritten by another subscription
+	if !nefCtx.ClaimUdmData(sub.UdmDataKey(), scsAsID, sub.SubID) {
+		pd := problemDetailsForbidden("Parameters of the UE are provisioned by another subscription")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	rspStatus, rspBody := p.Consumer().UpdatePpData(sub.Gpsi, convertPpConfigToPpData(scsAsID, sub.SubID, ppConfig))
+	if rspStatus != http.StatusNoContent {
+		nefCtx.ReleaseUdmData(sub.UdmDataKey(), scsAsID, sub.SubID)
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	ppConfig.Self = p.genParameterProvisionSubURI(scsAsID, sub.SubID)
+
+	af.PpSubs[sub.SubID] = sub
+	af.Log.Infoln("Parameter provisioning subscription is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", ppConfig.Self)
+	c.JSON(http.StatusCreated, ppConfig)
+}
+
+func (p *Processor) GetIndividualParameterProvisionSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.PpLog.Infof("GetIndividualParameterProvisionSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	sub, ok := af.PpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub.PpConfig)
+}
+
+func (p *Processor) PutIndividualParameterProvisionSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+	ppConfig *nef_models.PpConfig,
+) {
+	logger.PpLog.Infof("PutIndividualParameterProvisionSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	if rsp := validatePpConfig(ppConfig); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.PpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	// The parameters are provisioned to the UE
+	if genGpsi(ppConfig.ExternalId, ppConfig.Msisdn) != sub.Gpsi {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("UE identity can't be changed")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().UpdatePpData(sub.Gpsi, convertPpConfigToPpData(scsAsID, sub.SubID, ppConfig))
+	if rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	ppConfig.Self = sub.PpConfig.Self
+	sub.PpConfig = ppConfig
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, sub.PpConfig)
+}
+
+func (p *Processor) DeleteIndividualParameterProvisionSubscription(
+	c *gin.Context,
+	scsAsID, subID string,
+) {
+	logger.PpLog.Infof("DeleteIndividualParameterProvisionSubscription - scsAsID[%s], subID[%s]", scsAsID, subID)
+
+	af := p.Context().GetAf(scsAsID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.PpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().DeletePpData(sub.Gpsi)
+	if rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.PpSubs, subID)
+	p.Context().ReleaseUdmData(sub.UdmDataKey(), scsAsID, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func validatePpConfig(ppConfig *nef_models.PpConfig) *HandlerResponse {
+	if (ppConfig.ExternalId == "") == (ppConfig.Msisdn == "") {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("One of externalId or msisdn shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	ueBehaviour := ppConfig.ExpectedUeBehaviourParameters
+	if ueBehaviour == nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of expectedUeBehaviourParameters")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if ueBehaviour.PeriodicTime < 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid periodicTime")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if ueBehaviour.CommunicationDurationTime < 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid communicationDurationTime")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	// Only the periodic time and the communication duration time are provisioned to the UDM
+	if ueBehaviour.PeriodicTime == 0 && ueBehaviour.CommunicationDurationTime == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"One of periodicTime or communicationDurationTime shall be included")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	switch ueBehaviour.StationaryIndication {
+	case "", nef_models.StationaryIndication_STATIONARY, nef_models.StationaryIndication_MOBILE:
+	default:
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid stationaryIndication")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	switch ueBehaviour.ScheduledCommunicationType {
+	case "", nef_models.ScheduledCommunicationType_DOWNLINK_ONLY,
+		nef_models.ScheduledCommunicationType_UPLINK_ONLY,
+		nef_models.ScheduledCommunicationType_BIDIRECTIONAL:
+	default:
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid scheduledCommunicationType")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if ueBehaviour.ValidityTime != "" {
+		validityTime, err := time.Parse(time.RFC3339, ueBehaviour.ValidityTime)
+		if err != nil || validityTime.Before(time.Now()) {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid validityTime")
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+	}
+	return nil
+}
+
+func (p *Processor) genParameterProvisionSubURI(scsAsID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-pp/v1/{scsAsId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServicePp) + "/" + scsAsID + "/subscriptions/" + subID
+}
+
+// convertPpConfigToPpData maps the expected UE behaviour to the communication
+// characteristics, the subscription ID is used as the reference ID of the AF.
+func convertPpConfigToPpData(scsAsID, subID string, ppConfig *nef_models.PpConfig) *models.PpData {
+	refID, err := strconv.ParseInt(subID, 10, 32)
+	if err != nil {
+		logger.PpLog.Warnf("Invalid reference ID[%s]: %+v", subID, err)
+	}
+
+	ueBehaviour := ppConfig.ExpectedUeBehaviourParameters
+	commChar := &models.CommunicationCharacteristics{}
+	if ueBehaviour.PeriodicTime > 0 {
+		commChar.PpSubsRegTimer = &models.PpSubsRegTimer{
+			SubsRegTimer: ueBehaviour.PeriodicTime,
+			AfInstanceId: scsAsID,
+			ReferenceId:  int32(refID),
+		}
+	}
+	if ueBehaviour.CommunicationDurationTime > 0 {
+		commChar.PpActiveTime = &models.PpActiveTime{
+			ActiveTime:   ueBehaviour.CommunicationDurationTime,
+			AfInstanceId: scsAsID,
+			ReferenceId:  int32(refID),
+		}
+	}
+	return &models.PpData{
+		CommunicationCharacteristics: commChar,
+		SupportedFeatures:            ppConfig.SupportedFeatures,
+	}
+}
@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var ppConfig1ForAf1 = nef_models.PpConfig{
+	ExternalId: "ue1@free5gc.org",
+	ExpectedUeBehaviourParameters: &nef_models.ExpectedUeBehaviourData{
+		StationaryIndication:      nef_models.StationaryIndication_STATIONARY,
+		CommunicationDurationTime: 60,
+		PeriodicTime:              3600,
+	},
+}
+
+func TestPostParameterProvisionSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_PP, "127.0.0.3")
+	udmStub := initUDMPpDataPatchStub("extid-ue1@free5gc.org", http.StatusNoContent)
+	defer gock.Remove(udmStub)
+	udmReqChan := make(chan *http.Request, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/pp-data") {
+			udmReqChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	rspPpConfig1 := ppConfig1ForAf1
+	rspPpConfig1.Self = nefApp.Processor().genParameterProvisionSubURI("af1", "1")
+
+	ppConfigNoUeID := ppConfig1ForAf1
+	ppConfigNoUeID.ExternalId = ""
+
+	ppConfigBadValidity := ppConfig1ForAf1
+	ppConfigBadValidity.ExpectedUeBehaviourParameters = &nef_models.ExpectedUeBehaviourData{
+		PeriodicTime: 3600,
+		ValidityTime: "2020-01-01T00:00:00Z",
+	}
+
+	testCases := []struct {
+		description      string
+		ppConfig         nef_models.PpConfig
+		expectedResponse *HandlerResponse
+		expectedPpData   *models.PpData
+	}{
+		{
+			description: "TC1: Successful subscription, should provision communication characteristics to UDM",
+			ppConfig:    ppConfig1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspPpConfig1.Self},
+				},
+				Body: &rspPpConfig1,
+			},
+			expectedPpData: &models.PpData{
+				CommunicationCharacteristics: &models.CommunicationCharacteristics{
+					PpSubsRegTimer: &models.PpSubsRegTimer{
+						SubsRegTimer: 3600,
+						AfInstanceId: "af1",
+						ReferenceId:  1,
+					},
+					PpActiveTime: &models.PpActiveTime{
+						ActiveTime:   60,
+						AfInstanceId: "af1",
+						ReferenceId:  1,
+					},
+				},
+			},
+		},
+		{
+			description: "TC2: Absent of UE identity",
+			ppConfig:    ppConfigNoUeID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "One of externalId or msisdn shall be included",
+				},
+			},
+		},
+		{
+			description: "TC3: Expired validityTime",
+			ppConfig:    ppConfigBadValidity,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Invalid validityTime",
+				},
+			},
+		},
+		{
+			description: "TC4: UE provisioned by another subscription",
+			ppConfig:    ppConfig1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusForbidden,
+				Body: &models.ProblemDetails{
+					Title:  "Forbidden",
+					Status: http.StatusForbidden,
+					Detail: "Parameters of the UE are provisioned by another subscription",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			ppConfig := tc.ppConfig
+			nefApp.Processor().PostParameterProvisionSubscription(c, "af1", &ppConfig)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedPpData != nil {
+				select {
+				case req := <-udmReqChan:
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					assertJSONBodyEqual(t, tc.expectedPpData, body)
+				case <-time.After(time.Second):
+					t.Fatal("UDM is not requested")
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteIndividualParameterProvisionSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_PP, "127.0.0.3")
+	udmStub := initUDMPpDataPatchStub("extid-ue1@free5gc.org", http.StatusNoContent)
+	defer gock.Remove(udmStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	ppConfig := ppConfig1ForAf1
+	sub := af1.NewPpSub(&ppConfig)
+	sub.Gpsi = "extid-ue1@free5gc.org"
+	af1.PpSubs[sub.SubID] = sub
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	testCases := []struct {
+		description      string
+		subID            string
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Subscription is not found",
+			subID:       "99",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Successful deletion, should remove communication characteristics from UDM",
+			subID:       sub.SubID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().DeleteIndividualParameterProvisionSubscription(c, "af1", tc.subID)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			if tc.expectedResponse.Body != nil {
+				assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+			}
+		})
+	}
+
+	af1.Mu.RLock()
+	require.NotContains(t, af1.PpSubs, sub.SubID)
+	af1.Mu.RUnlock()
+}
+
+func initUDMPpDataPatchStub(gpsi string, statusCode int) gock.Mock {
+	req := gock.New("http://127.0.0.3:8000/nudm-pp/v1")
+	req.Patch("/" + gpsi + "/pp-data").
+		Persist().
+		Reply(statusCode)
+	return req.Mock
+}
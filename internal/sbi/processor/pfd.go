@@ -6,6 +6,7 @@ import (
 
 	nef_context "github.com/free5gc/nef/internal/context"
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
@@ -34,7 +35,7 @@ func (p *Processor) GetPFDManagementTransactions(c *gin.Context, scsAsID string)
 	af.Mu.RLock()
 	defer af.Mu.RUnlock()
 
-	var pfdMngs []models.PfdManagement
+	var pfdMngs []nef_models.PfdManagement
 	for _, afPfdTr := range af.PfdTrans {
 		pfdMng, rsp := p.buildPfdManagement(scsAsID, afPfdTr)
 		if rsp != nil {
@@ -50,7 +51,7 @@ func (p *Processor) GetPFDManagementTransactions(c *gin.Context, scsAsID string)
 func (p *Processor) PostPFDManagementTransactions(
 	c *gin.Context,
 	scsAsID string,
-	pfdMng *models.PfdManagement,
+	pfdMng *nef_models.PfdManagement,
 ) {
 	logger.PFDManageLog.Infof("PostPFDManagementTransactions - scsAsID[%s]", scsAsID)
 
@@ -83,6 +84,8 @@ func (p *Processor) PostPFDManagementTransactions(
 		return
 	}
 
+	afPfdTr.NotifDest = pfdMng.NotificationDestination
+
 	pfdNotifyContext := p.Notifier().PfdChangeNotifier.NewPfdNotifyContext()
 	defer pfdNotifyContext.FlushNotifications()
 
@@ -146,6 +149,7 @@ func (p *Processor) DeletePFDManagementTransactions(c *gin.Context, scsAsID stri
 			})
 		}
 		delete(af.PfdTrans, afPfdTr.TransID)
+		nefCtx.StoreAf(af)
 		afPfdTr.Log.Infoln("PFD Management Transaction is deleted")
 	}
 
@@ -189,7 +193,7 @@ func (p *Processor) GetIndividualPFDManagementTransaction(
 func (p *Processor) PutIndividualPFDManagementTransaction(
 	c *gin.Context,
 	scsAsID, transID string,
-	pfdMng *models.PfdManagement,
+	pfdMng *nef_models.PfdManagement,
 ) {
 	logger.PFDManageLog.Infof("PutIndividualPFDManagementTransaction - scsAsID[%s], transID[%s]",
 		scsAsID, transID)
@@ -245,6 +249,7 @@ func (p *Processor) PutIndividualPFDManagementTransaction(
 		})
 	}
 
+	afPfdTr.NotifDest = pfdMng.NotificationDestination
 	afPfdTr.DeleteAllExtAppIDs()
 	for appID, pfdData := range pfdMng.PfdDatas {
 		afPfdTr.AddExtAppID(appID)
@@ -261,6 +266,7 @@ func (p *Processor) PutIndividualPFDManagementTransaction(
 			})
 		}
 	}
+	nefCtx.StoreAf(af)
 	if len(pfdMng.PfdDatas) == 0 {
 		// The PFDs for all applications were not created successfully.
 		// PfdReport is included with detailed information.
@@ -310,6 +316,7 @@ func (p *Processor) DeleteIndividualPFDManagementTransaction(
 		})
 	}
 	delete(af.PfdTrans, afPfdTr.TransID)
+	nefCtx.StoreAf(af)
 	afPfdTr.Log.Infoln("PFD Management Transaction is deleted")
 
 	// TODO: Remove AfCtx if its subscriptions and transactions are both empty
@@ -396,6 +403,7 @@ func (p *Processor) DeleteIndividualApplicationPFDManagement(
 		return
 	}
 	afPfdTr.DeleteExtAppID(appID)
+	p.Context().StoreAf(af)
 	pfdNotifyContext.AddNotification(appID, &models.PfdChangeNotification{
 		ApplicationId: appID,
 		RemovalFlag:   true,
@@ -537,12 +545,13 @@ func (p *Processor) PatchIndividualApplicationPFDManagement(
 func (p *Processor) buildPfdManagement(
 	afID string,
 	afPfdTr *nef_context.AfPfdTransaction,
-) (*models.PfdManagement, *HandlerResponse) {
+) (*nef_models.PfdManagement, *HandlerResponse) {
 	transID := afPfdTr.TransID
 	appIDs := afPfdTr.GetExtAppIDs()
-	pfdMng := &models.PfdManagement{
-		Self:     p.genPfdManagementURI(afID, transID),
-		PfdDatas: make(map[string]models.PfdData, len(appIDs)),
+	pfdMng := &nef_models.PfdManagement{
+		Self:                    p.genPfdManagementURI(afID, transID),
+		PfdDatas:                make(map[string]models.PfdData, len(appIDs)),
+		NotificationDestination: afPfdTr.NotifDest,
 	}
 
 	rspCode, rspBody := p.Consumer().AppDataPfdsGet(appIDs)
@@ -557,6 +566,68 @@ func (p *Processor) buildPfdManagement(
 	return pfdMng, nil
 }
 
+// PfdChangeReportNotification relays the PFDs failed to be applied by the SMFs to the AFs
+// which provisioned them, with the reports of each PFD management transaction in a notification.
+func (p *Processor) PfdChangeReportNotification(subID string, pfdChgReports []models.PfdChangeReport) {
+	logger.PFDManageLog.Infof("PfdChangeReportNotification - subID[%s]", subID)
+
+	type pfdTransKey struct {
+		afID    string
+		transID string
+	}
+	transReports := make(map[pfdTransKey]*nef_models.PfdManagement)
+	for _, pfdChgReport := range pfdChgReports {
+		failureCode := convertPfdErrorToFailureCode(pfdChgReport.PfdError)
+		for _, appID := range pfdChgReport.ApplicationId {
+			afID, transID, ok := p.Context().IsAppIDExisted(appID)
+			if !ok {
+				logger.PFDManageLog.Warnf("appID[%s] is not provisioned by any AF, PFD failure is dropped", appID)
+				continue
+			}
+			key := pfdTransKey{afID, transID}
+			if _, ok = transReports[key]; !ok {
+				transReports[key] = &nef_models.PfdManagement{
+					PfdReports: make(map[string]models.PfdReport),
+				}
+			}
+			addPfdReport(transReports[key], &models.PfdReport{
+				ExternalAppIds: []string{appID},
+				FailureCode:    failureCode,
+			})
+		}
+	}
+
+	for key, pfdMng := range transReports {
+		af := p.Context().GetAf(key.afID)
+		if af == nil {
+			continue
+		}
+		af.Mu.RLock()
+		afPfdTr, ok := af.PfdTrans[key.transID]
+		if !ok {
+			af.Mu.RUnlock()
+			continue
+		}
+		notifDest := afPfdTr.NotifDest
+		log := afPfdTr.Log
+		af.Mu.RUnlock()
+
+		if notifDest == "" {
+			log.Warnln("No notificationDestination, PFD failures are not notified")
+			continue
+		}
+		pfdReports := make([]models.PfdReport, 0, len(pfdMng.PfdReports))
+		for _, pfdReport := range pfdMng.PfdReports {
+			pfdReports = append(pfdReports, pfdReport)
+		}
+		if err := p.Notifier().PfdMngNotifier.NotifyPfdReports(notifDest, pfdReports); err != nil {
+			log.Errorf("Notify PFD failures to AF failed: %+v", err)
+			continue
+		}
+		log.Infof("PFD failures are notified to AF")
+	}
+}
+
 func (p *Processor) storePfdDataToUDR(appID string, pfdDataForApp *models.PfdDataForApp) *models.PfdReport {
 	rspCode, _ := p.Consumer().AppDataPfdsAppIdPut(appID, pfdDataForApp)
 	if rspCode != http.StatusCreated && rspCode != http.StatusOK {
@@ -641,7 +712,7 @@ func (p *Processor) genPfdDataURI(afID, transID, appID string) string {
 
 func validatePfdManagement(
 	afID, transID string,
-	pfdMng *models.PfdManagement,
+	pfdMng *nef_models.PfdManagement,
 	nefCtx *nef_context.NefContext,
 ) *models.ProblemDetails {
 	pfdMng.PfdReports = make(map[string]models.PfdReport)
@@ -695,10 +766,27 @@ func validatePfdData(pfdData *models.PfdData, nefCtx *nef_context.NefContext, is
 	return nil
 }
 
-func addPfdReport(pfdMng *models.PfdManagement, newReport *models.PfdReport) {
+func addPfdReport(pfdMng *nef_models.PfdManagement, newReport *models.PfdReport) {
 	if oldReport, ok := pfdMng.PfdReports[string(newReport.FailureCode)]; ok {
 		oldReport.ExternalAppIds = append(oldReport.ExternalAppIds, newReport.ExternalAppIds...)
+		pfdMng.PfdReports[string(newReport.FailureCode)] = oldReport
 	} else {
 		pfdMng.PfdReports[string(newReport.FailureCode)] = *newReport
 	}
 }
+
+// convertPfdErrorToFailureCode maps the error of the PFDs reported by the SMF
+// to the failure code of the PfdReport towards the AF
+func convertPfdErrorToFailureCode(pfdError *models.ProblemDetails) models.FailureCode {
+	if pfdError == nil {
+		return models.FailureCode_OTHER_REASON
+	}
+	switch pfdError.Cause {
+	case "INSUFFICIENT_RESOURCES":
+		return models.FailureCode_RESOURCE_LIMITATION
+	case "SYSTEM_FAILURE":
+		return models.FailureCode_MALFUNCTION
+	default:
+		return models.FailureCode_OTHER_REASON
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"testing"
 
 	nef_context "github.com/free5gc/nef/internal/context"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/nef/internal/sbi/consumer"
 	"github.com/free5gc/nef/internal/sbi/notifier"
+	"github.com/free5gc/nef/internal/smsc"
 	"github.com/free5gc/nef/pkg/app"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
@@ -25,6 +27,7 @@ type nefTestApp struct {
 	nefCtx   *nef_context.NefContext
 	consumer *consumer.Consumer
 	notifier *notifier.Notifier
+	smsc     *smsc.FakeSmsc
 	proc     *Processor
 }
 
@@ -38,9 +41,10 @@ func newTestApp(cfg *factory.Config, tlsKeyLogPath string) (*nefTestApp, error)
 	if nef.consumer, err = consumer.NewConsumer(nef); err != nil {
 		return nil, err
 	}
-	if nef.notifier, err = notifier.NewNotifier(); err != nil {
+	if nef.notifier, err = notifier.NewNotifier(nef.nefCtx.Store()); err != nil {
 		return nil, err
 	}
+	nef.smsc = smsc.NewFakeSmsc()
 	if nef.proc, err = NewProcessor(nef); err != nil {
 		return nil, err
 	}
@@ -63,6 +67,10 @@ func (a *nefTestApp) Notifier() *notifier.Notifier {
 	return a.notifier
 }
 
+func (a *nefTestApp) Smsc() smsc.Smsc {
+	return a.smsc
+}
+
 func (a *nefTestApp) Processor() *Processor {
 	return a.proc
 }
@@ -142,6 +150,16 @@ func TestMain(m *testing.M) {
 			ServiceList: []factory.Service{
 				{
 					ServiceName: factory.ServiceNefPfd,
+					SuppFeat:    "1",
+				},
+			},
+			QosReferences: []factory.QosReference{
+				{
+					QosReference: "qos-video",
+					MarBwUl:      "5 Mbps",
+					MarBwDl:      "20 Mbps",
+					MirBwUl:      "1 Mbps",
+					MirBwDl:      "5 Mbps",
 				},
 			},
 		},
@@ -170,7 +188,7 @@ func TestGetPFDManagementTransactions(t *testing.T) {
 			afID:        "af1",
 			expectedResponse: &HandlerResponse{
 				Status: http.StatusOK,
-				Body: &[]models.PfdManagement{
+				Body: &[]nef_models.PfdManagement{
 					{
 						Self: nefApp.Processor().genPfdManagementURI("af1", "1"),
 						PfdDatas: map[string]models.PfdData{
@@ -283,13 +301,13 @@ func TestPostPFDManagementTransactions(t *testing.T) {
 	testCases := []struct {
 		description      string
 		afID             string
-		pfdManagement    *models.PfdManagement
+		pfdManagement    *nef_models.PfdManagement
 		expectedResponse *HandlerResponse
 	}{
 		{
 			description: "TC1: Valid input",
 			afID:        "af1",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app1": {
 						ExternalAppId: "app1",
@@ -308,7 +326,7 @@ func TestPostPFDManagementTransactions(t *testing.T) {
 			},
 			expectedResponse: &HandlerResponse{
 				Status: http.StatusCreated,
-				Body: &models.PfdManagement{
+				Body: &nef_models.PfdManagement{
 					Self: nefApp.Processor().genPfdManagementURI("af1", "1"),
 					PfdDatas: map[string]models.PfdData{
 						"app1": {
@@ -334,7 +352,7 @@ func TestPostPFDManagementTransactions(t *testing.T) {
 		{
 			description: "TC2: Invalid AF ID, should return ProblemDetails",
 			afID:        "af2",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app1": {
 						ExternalAppId: "app1",
@@ -359,7 +377,7 @@ func TestPostPFDManagementTransactions(t *testing.T) {
 		{
 			description: "Invalid PfdManagement, should return ProblemDetails",
 			afID:        "af1",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{},
 			},
 			expectedResponse: &HandlerResponse{
@@ -402,7 +420,7 @@ func TestGetIndividualPFDManagementTransaction(t *testing.T) {
 			transID:     "1",
 			expectedResponse: &HandlerResponse{
 				Status: http.StatusOK,
-				Body: &models.PfdManagement{
+				Body: &nef_models.PfdManagement{
 					Self: nefApp.Processor().genPfdManagementURI("af1", "1"),
 					PfdDatas: map[string]models.PfdData{
 						"app1": {
@@ -518,14 +536,14 @@ func TestPutIndividualPFDManagementTransaction(t *testing.T) {
 		description      string
 		afID             string
 		transID          string
-		pfdManagement    *models.PfdManagement
+		pfdManagement    *nef_models.PfdManagement
 		expectedResponse *HandlerResponse
 	}{
 		{
 			description: "TC1: Valid input",
 			afID:        "af1",
 			transID:     "1",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app1": {
 						ExternalAppId: "app1",
@@ -544,7 +562,7 @@ func TestPutIndividualPFDManagementTransaction(t *testing.T) {
 			},
 			expectedResponse: &HandlerResponse{
 				Status: http.StatusOK,
-				Body: &models.PfdManagement{
+				Body: &nef_models.PfdManagement{
 					Self: nefApp.Processor().genPfdManagementURI("af1", "1"),
 					PfdDatas: map[string]models.PfdData{
 						"app1": {
@@ -571,7 +589,7 @@ func TestPutIndividualPFDManagementTransaction(t *testing.T) {
 			description: "TC2: Invalid transaction ID, should return ProblemDetails",
 			afID:        "af1",
 			transID:     "-1",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app1": {
 						ExternalAppId: "app1",
@@ -597,7 +615,7 @@ func TestPutIndividualPFDManagementTransaction(t *testing.T) {
 			description: "TC3: Invalid PfdManagement, should return ProblemDetails",
 			afID:        "af1",
 			transID:     "1",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{},
 			},
 			expectedResponse: &HandlerResponse{
@@ -945,13 +963,13 @@ func TestPatchIndividualApplicationPFDManagement(t *testing.T) {
 func TestValidatePfdManagement(t *testing.T) {
 	testCases := []struct {
 		description     string
-		pfdManagement   *models.PfdManagement
+		pfdManagement   *nef_models.PfdManagement
 		expectedProblem *models.ProblemDetails
 		expectedReports map[string]models.PfdReport
 	}{
 		{
 			description: "TC1: Valid",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app1": {
 						ExternalAppId: "app1",
@@ -973,7 +991,7 @@ func TestValidatePfdManagement(t *testing.T) {
 		},
 		{
 			description: "TC2: Empty PfdDatas, should return ProblemDetails",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{},
 			},
 			expectedProblem: openapi.ProblemDetailsDataNotFound(DetailNoPfdData),
@@ -981,7 +999,7 @@ func TestValidatePfdManagement(t *testing.T) {
 		},
 		{
 			description: "TC3: An appID is already provisioned, should mark in PfdReports",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app100": {
 						ExternalAppId: "app100",
@@ -1007,7 +1025,7 @@ func TestValidatePfdManagement(t *testing.T) {
 		},
 		{
 			description: "TC4: None of the PFDs were created, should return ProblemDetails and mark in PfdReports",
-			pfdManagement: &models.PfdManagement{
+			pfdManagement: &nef_models.PfdManagement{
 				PfdDatas: map[string]models.PfdData{
 					"app100": {
 						ExternalAppId: "app100",
@@ -1251,6 +1269,49 @@ func initNRFNfmStub() {
 		JSON(nrfRegisterInstanceRsp)
 }
 
+// initNRFDiscStub replies the discovery of the NF service with an NF instance at the IP
+func initNRFDiscStub(nfType models.NfType, serviceName models.ServiceName, ip string) {
+	searchResult := &models.SearchResult{
+		ValidityPeriod: 100,
+		NfInstances: []models.NfProfile{
+			{
+				NfInstanceId: "nef-unit-testing",
+				NfType:       nfType,
+				NfStatus:     "REGISTERED",
+				NfServices: &[]models.NfService{
+					{
+						ServiceInstanceId: "1",
+						ServiceName:       serviceName,
+						Versions: &[]models.NfServiceVersion{
+							{
+								ApiVersionInUri: "v1",
+								ApiFullVersion:  "1.0.0",
+							},
+						},
+						Scheme:          "http",
+						NfServiceStatus: "REGISTERED",
+						IpEndPoints: &[]models.IpEndPoint{
+							{
+								Ipv4Address: ip,
+								Transport:   "TCP",
+								Port:        8000,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gock.New("http://127.0.0.10:8000/nnrf-disc/v1").
+		Get("/nf-instances").
+		MatchParam("target-nf-type", string(nfType)).
+		MatchParam("requester-nf-type", "NEF").
+		MatchParam("service-names", string(serviceName)).
+		Reply(http.StatusOK).
+		JSON(searchResult)
+}
+
 func initNRFDiscUDRStub() {
 	searchResult := &models.SearchResult{
 		ValidityPeriod: 100,
@@ -5,40 +5,73 @@ import (
 	"net/http"
 
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/internal/util"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/gin-gonic/gin"
 )
 
-func (p *Processor) GetApplicationsPFD(c *gin.Context, appIDs []string) {
+func (p *Processor) GetApplicationsPFD(c *gin.Context, appIDs []string, suppFeat string) {
 	logger.PFDFLog.Infof("GetApplicationsPFD - appIDs: %v", appIDs)
 
-	// TODO: Support SupportedFeatures
+	suppFeat, rsp := p.negotiatePfdfSuppFeat(suppFeat)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
 	rspCode, rspBody := p.Consumer().AppDataPfdsGet(appIDs)
+	if rspCode != http.StatusOK || suppFeat == "" {
+		c.JSON(rspCode, rspBody)
+		return
+	}
 
-	c.JSON(rspCode, rspBody)
+	pfdDatas := *rspBody.(*[]models.PfdDataForApp)
+	pfdDataExts := make([]nef_models.PfdDataForAppExt, 0, len(pfdDatas))
+	for i := range pfdDatas {
+		pfdDataExts = append(pfdDataExts, *convertPfdDataForAppToPfdDataForAppExt(&pfdDatas[i], suppFeat))
+	}
+	c.JSON(http.StatusOK, pfdDataExts)
 }
 
-func (p *Processor) GetIndividualApplicationPFD(c *gin.Context, appID string) {
+func (p *Processor) GetIndividualApplicationPFD(c *gin.Context, appID, suppFeat string) {
 	logger.PFDFLog.Infof("GetIndividualApplicationPFD - appID[%s]", appID)
 
-	// TODO: Support SupportedFeatures
+	suppFeat, rsp := p.negotiatePfdfSuppFeat(suppFeat)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
 	rspCode, rspBody := p.Consumer().AppDataPfdsAppIdGet(appID)
+	if rspCode != http.StatusOK || suppFeat == "" {
+		c.JSON(rspCode, rspBody)
+		return
+	}
 
-	c.JSON(rspCode, rspBody)
+	c.JSON(http.StatusOK, convertPfdDataForAppToPfdDataForAppExt(rspBody.(*models.PfdDataForApp), suppFeat))
 }
 
 func (p *Processor) PostPFDSubscriptions(c *gin.Context, pfdSubsc *models.PfdSubscription) {
 	logger.PFDFLog.Infof("PostPFDSubscriptions - appIDs: %v", pfdSubsc.ApplicationIds)
 
-	// TODO: Support SupportedFeatures
 	if len(pfdSubsc.NotifyUri) == 0 {
 		pd := openapi.ProblemDetailsDataNotFound("Absent of Notify URI")
 		c.JSON(int(pd.Status), pd)
 		return
 	}
 
+	// The negotiated features are kept in the subscription, which decide e.g. whether
+	// the PFD partial failures are reported in the responses of the notifications
+	suppFeat, rsp := p.negotiatePfdfSuppFeat(pfdSubsc.SupportedFeatures)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+	pfdSubsc.SupportedFeatures = suppFeat
+
 	subID := p.Notifier().PfdChangeNotifier.AddPfdSub(pfdSubsc)
 	hdrs := make(map[string][]string)
 	addLocationheader(hdrs, p.genPfdSubscriptionURI(subID))
@@ -48,7 +81,11 @@ func (p *Processor) PostPFDSubscriptions(c *gin.Context, pfdSubsc *models.PfdSub
 			c.Header(k, value)
 		}
 	}
-	c.JSON(http.StatusCreated, pfdSubsc)
+	c.JSON(http.StatusCreated, &nef_models.PfdSubscription{
+		ApplicationIds:    pfdSubsc.ApplicationIds,
+		NotifyUri:         pfdSubsc.NotifyUri,
+		SupportedFeatures: pfdSubsc.SupportedFeatures,
+	})
 }
 
 func (p *Processor) DeleteIndividualPFDSubscription(c *gin.Context, subID string) {
@@ -63,7 +100,30 @@ func (p *Processor) DeleteIndividualPFDSubscription(c *gin.Context, subID string
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// negotiatePfdfSuppFeat returns the requested features of Nnef_PFDManagement which are
+// also supported by the NEF as configured in the serviceList
+func (p *Processor) negotiatePfdfSuppFeat(suppFeat string) (string, *HandlerResponse) {
+	negotiated, err := util.NegotiateSuppFeat(suppFeat, p.Config().ServiceSuppFeat(factory.ServiceNefPfd))
+	if err != nil {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(err.Error())
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return negotiated, nil
+}
+
 func (p *Processor) genPfdSubscriptionURI(subID string) string {
 	// E.g. "https://localhost:29505/nnef-pfdmanagement/v1/subscriptions/{subscriptionId}
 	return fmt.Sprintf("%s/subscriptions/%s", p.Config().ServiceUri(factory.ServiceNefPfd), subID)
 }
+
+func convertPfdDataForAppToPfdDataForAppExt(
+	pfdDataForApp *models.PfdDataForApp,
+	suppFeat string,
+) *nef_models.PfdDataForAppExt {
+	return &nef_models.PfdDataForAppExt{
+		ApplicationId:     pfdDataForApp.ApplicationId,
+		Pfds:              pfdDataForApp.Pfds,
+		CachingTime:       pfdDataForApp.CachingTime,
+		SupportedFeatures: suppFeat,
+	}
+}
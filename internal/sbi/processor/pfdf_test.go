@@ -2,11 +2,17 @@ package processor
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/internal/sbi/notifier"
+	"github.com/free5gc/nef/internal/store"
 	"github.com/free5gc/openapi/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -20,6 +26,7 @@ func TestGetApplicationsPFD(t *testing.T) {
 	testCases := []struct {
 		description      string
 		appIDs           []string
+		suppFeat         string
 		expectedResponse *HandlerResponse
 	}{
 		{
@@ -38,6 +45,39 @@ func TestGetApplicationsPFD(t *testing.T) {
 				Body:   &models.ProblemDetails{Status: http.StatusNotFound},
 			},
 		},
+		{
+			description: "TC3: With supported-features, should return the negotiated features",
+			appIDs:      []string{"app1", "app2"},
+			suppFeat:    "3",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body: &[]nef_models.PfdDataForAppExt{
+					{
+						ApplicationId:     pfdDataForApp1.ApplicationId,
+						Pfds:              pfdDataForApp1.Pfds,
+						SupportedFeatures: "1",
+					},
+					{
+						ApplicationId:     pfdDataForApp2.ApplicationId,
+						Pfds:              pfdDataForApp2.Pfds,
+						SupportedFeatures: "1",
+					},
+				},
+			},
+		},
+		{
+			description: "TC4: Invalid supported-features, should return ProblemDetails",
+			appIDs:      []string{"app1", "app2"},
+			suppFeat:    "xyz",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "invalid supportedFeatures[xyz]",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -45,7 +85,7 @@ func TestGetApplicationsPFD(t *testing.T) {
 			httpRecorder := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(httpRecorder)
 
-			nefApp.Processor().GetApplicationsPFD(c, tc.appIDs)
+			nefApp.Processor().GetApplicationsPFD(c, tc.appIDs, tc.suppFeat)
 			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
 
 			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
@@ -60,6 +100,7 @@ func TestGetIndividualApplicationPFD(t *testing.T) {
 	testCases := []struct {
 		description      string
 		appID            string
+		suppFeat         string
 		expectedResponse *HandlerResponse
 	}{
 		{
@@ -78,6 +119,15 @@ func TestGetIndividualApplicationPFD(t *testing.T) {
 				Body:   &models.ProblemDetails{Status: http.StatusNotFound},
 			},
 		},
+		{
+			description: "TC3: Unsupported features requested, should omit the supportedFeatures",
+			appID:       "app1",
+			suppFeat:    "10",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &pfdDataForApp1,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -85,7 +135,7 @@ func TestGetIndividualApplicationPFD(t *testing.T) {
 			httpRecorder := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(httpRecorder)
 
-			nefApp.Processor().GetIndividualApplicationPFD(c, tc.appID)
+			nefApp.Processor().GetIndividualApplicationPFD(c, tc.appID, tc.suppFeat)
 			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
 
 			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
@@ -98,6 +148,15 @@ func TestPostPFDSubscriptions(t *testing.T) {
 		ApplicationIds: []string{"app1", "app2"},
 		NotifyUri:      "http://pfdSub1URI/notify",
 	}
+	pfdSubscWithFeat := &models.PfdSubscription{
+		ApplicationIds:    []string{"app1"},
+		NotifyUri:         "http://pfdSub1URI/notify",
+		SupportedFeatures: "F",
+	}
+	defer func() {
+		// Subscription 1 is left for TestDeleteIndividualPFDSubscription()
+		require.NoError(t, nefApp.Notifier().PfdChangeNotifier.DeletePfdSub("2"))
+	}()
 
 	testCases := []struct {
 		description      string
@@ -112,7 +171,25 @@ func TestPostPFDSubscriptions(t *testing.T) {
 				Headers: map[string][]string{
 					"Location": {nefApp.Processor().genPfdSubscriptionURI("1")},
 				},
-				Body: pfdSubsc,
+				Body: &nef_models.PfdSubscription{
+					ApplicationIds: []string{"app1", "app2"},
+					NotifyUri:      "http://pfdSub1URI/notify",
+				},
+			},
+		},
+		{
+			description:  "TC2: Subscription with supportedFeatures, should return the negotiated features",
+			subscription: pfdSubscWithFeat,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {nefApp.Processor().genPfdSubscriptionURI("2")},
+				},
+				Body: &nef_models.PfdSubscription{
+					ApplicationIds:    []string{"app1"},
+					NotifyUri:         "http://pfdSub1URI/notify",
+					SupportedFeatures: "1",
+				},
 			},
 		},
 	}
@@ -171,7 +248,7 @@ var (
 )
 
 func TestPostPfdChangeReports(t *testing.T) {
-	// Note: Because TestPostPFDSubscriptions() already used subscription ID 1, the ID will start from 2 here.
+	// Note: Because TestPostPFDSubscriptions() already used subscription ID 1 and 2, the ID will start from 3 here.
 	initUDRDrPutPfdDataStub(http.StatusOK)
 	initUDRDrDeletePfdDataStub()
 	initNEFNotificationStub("http://pfdSub2URI")
@@ -182,6 +259,7 @@ func TestPostPfdChangeReports(t *testing.T) {
 			notifChan <- request
 		}
 	})
+	defer gock.Observe(nil)
 
 	af := nefApp.Context().NewAf("af1")
 	nefApp.Context().AddAf(af)
@@ -280,9 +358,295 @@ func TestPostPfdChangeReports(t *testing.T) {
 	}
 }
 
+func TestPostPfdChangeReportsToWildcardSubscription(t *testing.T) {
+	initUDRDrPutPfdDataStub(http.StatusOK)
+	initUDRDrDeletePfdDataStub()
+	initNEFNotificationStub("http://pfdSubAllURI")
+	initNEFNotificationStub("http://pfdSubApp2URI")
+	defer gock.Off()
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "pfdSub") {
+			notifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	af := nefApp.Context().NewAf("af1")
+	nefApp.Context().AddAf(af)
+	defer nefApp.Context().DeleteAf("af1")
+
+	af.Mu.Lock()
+	afPfdTr := af.NewPfdTrans()
+	af.PfdTrans[afPfdTr.TransID] = afPfdTr
+	afPfdTr.AddExtAppID("app1")
+	af.Mu.Unlock()
+
+	// The subscription without applicationIds should be notified of every application
+	wildcardSubID := nefApp.Notifier().PfdChangeNotifier.AddPfdSub(&models.PfdSubscription{
+		NotifyUri: "http://pfdSubAllURI",
+	})
+	app2SubID := nefApp.Notifier().PfdChangeNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds: []string{"app2"},
+		NotifyUri:      "http://pfdSubApp2URI",
+	})
+	defer func() {
+		require.NoError(t, nefApp.Notifier().PfdChangeNotifier.DeletePfdSub(wildcardSubID))
+		require.NoError(t, nefApp.Notifier().PfdChangeNotifier.DeletePfdSub(app2SubID))
+	}()
+
+	testCases := []struct {
+		description          string
+		triggerFunc          func(c *gin.Context)
+		expectedNotification []models.PfdChangeNotification
+	}{
+		{
+			description: "Create app1, should only notify the wildcard subscription",
+			triggerFunc: func(c *gin.Context) {
+				nefApp.Processor().PutIndividualApplicationPFDManagement(c, "af1", "1", "app1", &models.PfdData{
+					ExternalAppId: "app1",
+					Pfds: map[string]models.Pfd{
+						"pfd1": pfd1,
+					},
+				})
+			},
+			expectedNotification: []models.PfdChangeNotification{
+				{
+					ApplicationId: "app1",
+					Pfds: []models.PfdContent{
+						pfdContent1,
+					},
+				},
+			},
+		},
+		{
+			description: "Delete app1, should only notify the wildcard subscription",
+			triggerFunc: func(c *gin.Context) {
+				nefApp.Processor().DeleteIndividualApplicationPFDManagement(c, "af1", "1", "app1")
+			},
+			expectedNotification: []models.PfdChangeNotification{
+				{
+					ApplicationId: "app1",
+					RemovalFlag:   true,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			tc.triggerFunc(c)
+			r := <-notifChan
+			require.Equal(t, "http://pfdSubAllURI/notify", r.URL.String())
+
+			var getNotifications []models.PfdChangeNotification
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&getNotifications))
+			require.Equal(t, tc.expectedNotification, getNotifications)
+		})
+	}
+}
+
+func TestPfdChangeNotificationDelivery(t *testing.T) {
+	initUDRDrPutPfdDataStub(http.StatusOK)
+	// The first notification to pfdSubRetryURI fails temporarily and should be retried
+	gock.New("http://pfdSubRetryURI").
+		Post("/notify").
+		Reply(http.StatusServiceUnavailable)
+	initNEFNotificationStub("http://pfdSubRetryURI")
+	gock.New("http://pfdSubGoneURI").
+		Post("/notify").
+		Persist().
+		Reply(http.StatusNotFound).
+		JSON(models.ProblemDetails{Status: http.StatusNotFound})
+	gock.New("http://pfdSubBadURI").
+		Post("/notify").
+		Persist().
+		Reply(http.StatusBadRequest).
+		JSON(models.ProblemDetails{Status: http.StatusBadRequest})
+	defer gock.Off()
+
+	af := nefApp.Context().NewAf("af1")
+	nefApp.Context().AddAf(af)
+	defer nefApp.Context().DeleteAf("af1")
+
+	af.Mu.Lock()
+	afPfdTr := af.NewPfdTrans()
+	af.PfdTrans[afPfdTr.TransID] = afPfdTr
+	afPfdTr.AddExtAppID("app1")
+	af.Mu.Unlock()
+
+	pfdNotifier := nefApp.Notifier().PfdChangeNotifier
+	retrySubID := pfdNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds: []string{"app1"},
+		NotifyUri:      "http://pfdSubRetryURI",
+	})
+	goneSubID := pfdNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds: []string{"app1"},
+		NotifyUri:      "http://pfdSubGoneURI",
+	})
+	badSubID := pfdNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds: []string{"app1"},
+		NotifyUri:      "http://pfdSubBadURI",
+	})
+	defer func() {
+		require.NoError(t, pfdNotifier.DeletePfdSub(retrySubID))
+	}()
+
+	// Each update of app1 is notified to all the subscriptions
+	for i := 0; i < 3; i++ {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+		nefApp.Processor().PutIndividualApplicationPFDManagement(c, "af1", "1", "app1", &models.PfdData{
+			ExternalAppId: "app1",
+			Pfds: map[string]models.Pfd{
+				"pfd1": pfd1,
+			},
+		})
+		require.Equal(t, http.StatusOK, httpRecorder.Code)
+	}
+
+	require.Eventually(t, func() bool {
+		status, ok := pfdNotifier.PfdSubStatus(retrySubID)
+		return ok && status.NumSuccess == 3
+	}, 5*time.Second, 50*time.Millisecond, "notifications should be delivered after retrying")
+
+	require.Eventually(t, func() bool {
+		_, ok := pfdNotifier.PfdSubStatus(goneSubID)
+		return !ok
+	}, time.Second, 50*time.Millisecond, "subscription unknown to the subscriber should be removed")
+	require.Eventually(t, func() bool {
+		_, ok := pfdNotifier.PfdSubStatus(badSubID)
+		return !ok
+	}, time.Second, 50*time.Millisecond, "subscription should be expired after the notifications are rejected")
+}
+
+func TestPfdChangeNotificationStopDelivery(t *testing.T) {
+	stub := gock.New("http://pfdSubStopURI").
+		Post("/notify").
+		Persist().
+		Reply(http.StatusServiceUnavailable)
+	defer gock.Remove(stub.Mock)
+	var numAttempts atomic.Int32
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.URL.Host == "pfdSubStopURI" {
+			numAttempts.Add(1)
+		}
+	})
+	defer gock.Observe(nil)
+
+	// A notifier of its own, since the delivery can't be restarted once it's stopped
+	pfdNotifier, err := notifier.NewPfdChangeNotifier(store.NewMemoryStore())
+	require.NoError(t, err)
+	subID := pfdNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds: []string{"app1"},
+		NotifyUri:      "http://pfdSubStopURI",
+	})
+
+	pfdNotifyContext := pfdNotifier.NewPfdNotifyContext()
+	pfdNotifyContext.AddNotification("app1", &models.PfdChangeNotification{
+		ApplicationId: "app1",
+		RemovalFlag:   true,
+	})
+	pfdNotifyContext.FlushNotifications()
+	require.Eventually(t, func() bool {
+		return numAttempts.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// The retry backing off is abandoned, and nothing is delivered after the stop
+	pfdNotifier.StopDelivery()
+	pfdNotifyContext.FlushNotifications()
+	require.Never(t, func() bool {
+		return numAttempts.Load() > 1
+	}, 1500*time.Millisecond, 50*time.Millisecond)
+
+	status, ok := pfdNotifier.PfdSubStatus(subID)
+	require.True(t, ok)
+	require.Zero(t, status.NumSuccess)
+	require.NoError(t, pfdNotifier.DeletePfdSub(subID))
+	require.Error(t, pfdNotifier.DeletePfdSub(subID))
+}
+
 func initNEFNotificationStub(notifyURI string) {
 	gock.New(notifyURI).
 		Post("/notify").
 		Persist().
 		Reply(http.StatusNoContent)
 }
+
+func TestPfdChangeReportNotification(t *testing.T) {
+	smfStub := gock.New("http://smfPfdSubURI").
+		Post("/notify").
+		Persist().
+		Reply(http.StatusOK).
+		JSON([]models.PfdChangeReport{
+			{
+				PfdError: &models.ProblemDetails{
+					Status: http.StatusInternalServerError,
+					Cause:  "INSUFFICIENT_RESOURCES",
+				},
+				ApplicationId: []string{"app1", "app2"},
+			},
+		})
+	defer gock.Remove(smfStub.Mock)
+	afStub := gock.New("http://af1URI").
+		Post("/pfd-notify").
+		Persist().
+		Reply(http.StatusNoContent)
+	defer gock.Remove(afStub.Mock)
+	afNotifChan := make(chan []byte, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.URL.Host == "af1URI" {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			afNotifChan <- body
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af := nefCtx.NewAf("af1")
+	af.Mu.Lock()
+	afPfdTr := af.NewPfdTrans()
+	afPfdTr.NotifDest = "http://af1URI/pfd-notify"
+	afPfdTr.AddExtAppID("app1")
+	afPfdTr.AddExtAppID("app2")
+	af.PfdTrans[afPfdTr.TransID] = afPfdTr
+	af.Mu.Unlock()
+	nefCtx.AddAf(af)
+	defer nefCtx.DeleteAf("af1")
+
+	// The PfdChangeReports are only handled with the partial failure feature
+	pfdNotifier := nefApp.Notifier().PfdChangeNotifier
+	subID := pfdNotifier.AddPfdSub(&models.PfdSubscription{
+		ApplicationIds:    []string{"app1", "app2"},
+		NotifyUri:         "http://smfPfdSubURI",
+		SupportedFeatures: "1",
+	})
+	defer func() {
+		require.NoError(t, pfdNotifier.DeletePfdSub(subID))
+	}()
+
+	pfdNotifyContext := pfdNotifier.NewPfdNotifyContext()
+	pfdNotifyContext.AddNotification("app1", &models.PfdChangeNotification{
+		ApplicationId: "app1",
+		RemovalFlag:   true,
+	})
+	pfdNotifyContext.AddNotification("app2", &models.PfdChangeNotification{
+		ApplicationId: "app2",
+		RemovalFlag:   true,
+	})
+	pfdNotifyContext.FlushNotifications()
+
+	select {
+	case body := <-afNotifChan:
+		var pfdReports []models.PfdReport
+		require.NoError(t, json.Unmarshal(body, &pfdReports))
+		require.Len(t, pfdReports, 1)
+		require.Equal(t, models.FailureCode_RESOURCE_LIMITATION, pfdReports[0].FailureCode)
+		require.ElementsMatch(t, []string{"app1", "app2"}, pfdReports[0].ExternalAppIds)
+	case <-time.After(5 * time.Second):
+		t.Fatal("PFD failures are not notified to AF")
+	}
+}
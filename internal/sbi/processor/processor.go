@@ -1,13 +1,22 @@
 package processor
 
 import (
+	"net/http"
+
 	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
 	"github.com/free5gc/nef/internal/sbi/consumer"
 	"github.com/free5gc/nef/internal/sbi/notifier"
+	"github.com/free5gc/nef/internal/smsc"
 	"github.com/free5gc/nef/pkg/app"
 	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
 )
 
+// causeUserNotFound is the cause of the ProblemDetails for the UE unknown by the UDM
+const causeUserNotFound = "USER_NOT_FOUND"
+
 type nef interface {
 	app.App
 
@@ -15,6 +24,7 @@ type nef interface {
 	Config() *factory.Config
 	Consumer() *consumer.Consumer
 	Notifier() *notifier.Notifier
+	Smsc() smsc.Smsc
 }
 
 type Processor struct {
@@ -32,9 +42,23 @@ func NewProcessor(nef nef) (*Processor, error) {
 		nef: nef,
 	}
 
+	// The PFDs failed to be applied by the SMFs are relayed to the AFs
+	nef.Notifier().PfdChangeNotifier.SetPfdChangeReportHandler(handler.PfdChangeReportNotification)
+	// The NF status subscriptions are removed by the NRF together with the NF instance
+	nef.Consumer().SetNfReregisteredHandler(handler.SubscribeNfStatus)
 	return handler, nil
 }
 
+// problemDetailsForbidden is for the request rejected as the resource is owned by
+// another subscription
+func problemDetailsForbidden(detail string) *models.ProblemDetails {
+	return &models.ProblemDetails{
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: detail,
+	}
+}
+
 func addLocationheader(header map[string][]string, location string) {
 	locations := header["Location"]
 	if locations == nil {
@@ -43,3 +67,37 @@ func addLocationheader(header map[string][]string, location string) {
 		header["Location"] = append(locations, location)
 	}
 }
+
+// translateGpsiToSupi gets the SUPI of the individual UE from the UDM, or from the cache
+// if the GPSI was translated within the SupiCacheTtl. No SUPI is returned for an empty GPSI.
+// The GPSI unknown by the UDM is reported as 404, and the other failures as system failure,
+// rather than relaying the errors of the UDM to the AF.
+func (p *Processor) translateGpsiToSupi(gpsi string) (string, *HandlerResponse) {
+	if gpsi == "" {
+		return "", nil
+	}
+	if supi, ok := p.Context().GetCachedSupi(gpsi); ok {
+		return supi, nil
+	}
+
+	rspStatus, rspBody := p.Consumer().GetIdTranslationResult(gpsi)
+	switch rspStatus {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		pd := openapi.ProblemDetailsDataNotFound("GPSI is not found")
+		pd.Cause = causeUserNotFound
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	default:
+		logger.ProcessorLog.Errorf("Translate GPSI[%s] to SUPI failed: rspStatus[%d], rspBody[%+v]",
+			gpsi, rspStatus, rspBody)
+		pd := openapi.ProblemDetailsSystemFailure("Translate GPSI to SUPI failed")
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	idTransResult, ok := rspBody.(*models.IdTranslationResult)
+	if !ok || idTransResult.Supi == "" {
+		pd := openapi.ProblemDetailsSystemFailure("SUPI is not found")
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	p.Context().CacheSupi(gpsi, idTransResult.Supi)
+	return idTransResult.Supi, nil
+}
@@ -0,0 +1,273 @@
+package processor
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/logger"
+	"github.com/free5gc/nef/internal/store"
+	"github.com/free5gc/openapi/models"
+)
+
+// ReconcileTrafficInfluence verifies the restored traffic influence subscriptions
+// against the PCF and UDR. The missing app sessions and influence data are re-created,
+// the subscriptions without any of them are removed, and the influence data created
+// by this NEF but not referred by any subscription are deleted from the UDR
+// if the subscriptions are kept in a persistent store.
+func (p *Processor) ReconcileTrafficInfluence() *nef_context.ReconcileReport {
+	logger.ProcessorLog.Infoln("Reconcile traffic influence subscriptions")
+
+	nefCtx := p.Context()
+	rpt := &nef_context.ReconcileReport{
+		StartTime: time.Now(),
+	}
+
+	// The subscriptions created during the reconciliation get the correlation IDs above
+	// lastCorreID, and their influence data are not taken as orphans
+	lastCorreID := nefCtx.NumCorreID()
+	correIDs := make(map[string]struct{})
+	for _, af := range nefCtx.GetAfs() {
+		// The PCF and UDR are requested with a copy of the subscriptions, without the AF lock
+		af.Mu.RLock()
+		subs := make(map[string]*nef_context.AfSubscription, len(af.Subs))
+		for subID, sub := range af.Subs {
+			correIDs[sub.NotifCorreID] = struct{}{}
+			subs[subID] = sub
+		}
+		af.Mu.RUnlock()
+
+		for subID, sub := range subs {
+			af.Mu.RLock()
+			subCopy := copyAfSubForReconcile(sub)
+			af.Mu.RUnlock()
+
+			item := p.reconcileAfSub(subCopy)
+			item.AfID = af.AfID
+			item.SubID = subID
+			p.applyReconcileItem(af, sub, subCopy, item)
+			rpt.AddItem(item)
+		}
+	}
+	if p.Config().StoreType() == store.TypeMemory {
+		// Nothing is restored from the memory store, so every influence data would look orphaned
+		logger.ProcessorLog.Infoln("Orphan influence data are not checked without the persistent store")
+	} else {
+		p.deleteOrphanInfluenceData(correIDs, lastCorreID, rpt)
+	}
+
+	rpt.EndTime = time.Now()
+	nefCtx.SetReconcileReport(rpt)
+	logger.ProcessorLog.Infof("Reconciliation is done: verified[%d], recreated[%d], "+
+		"unverified[%d], removed[%d], orphans[%d]", rpt.NumVerified, rpt.NumRecreated,
+		rpt.NumUnverified, rpt.NumRemoved, rpt.NumOrphans)
+	return rpt
+}
+
+// copyAfSubForReconcile copies the fields of the subscription used by the reconciliation.
+// The caller shall hold the lock of the AF.
+func copyAfSubForReconcile(sub *nef_context.AfSubscription) *nef_context.AfSubscription {
+	subCopy := *sub
+	if sub.TiSub != nil {
+		tiSub := *sub.TiSub
+		subCopy.TiSub = &tiSub
+	}
+	return &subCopy
+}
+
+// applyReconcileItem applies the result of the reconciliation to the subscription, unless
+// the subscription is deleted by the AF meanwhile. The app session re-created for
+// a deleted subscription is deleted again.
+func (p *Processor) applyReconcileItem(
+	af *nef_context.AfData,
+	sub, subCopy *nef_context.AfSubscription,
+	item nef_context.ReconcileItem,
+) {
+	af.Mu.Lock()
+	appSessRecreated := item.Result == nef_context.ReconcileResultRecreated &&
+		subCopy.AppSessID != sub.AppSessID
+	if af.Subs[sub.SubID] != sub {
+		af.Mu.Unlock()
+		if appSessRecreated {
+			rspCode, _ := p.Consumer().DeleteAppSession(subCopy.PcfUri, subCopy.AppSessID)
+			sub.Log.Infof("Subscription is deleted during the reconciliation, "+
+				"re-created app session[%s] is deleted: rspCode[%d]", subCopy.AppSessID, rspCode)
+		}
+		return
+	}
+
+	switch {
+	case item.Result == nef_context.ReconcileResultRemoved:
+		delete(af.Subs, sub.SubID)
+	case appSessRecreated:
+		sub.AppSessID = subCopy.AppSessID
+		sub.PcfUri = subCopy.PcfUri
+		sub.PcfID = subCopy.PcfID
+	default:
+		af.Mu.Unlock()
+		return
+	}
+	p.Context().StoreAf(af)
+	af.Mu.Unlock()
+}
+
+// reconcileAfSub verifies a copy of the subscription, which is applied by applyReconcileItem
+func (p *Processor) reconcileAfSub(sub *nef_context.AfSubscription) nef_context.ReconcileItem {
+	switch {
+	case sub.TiSub == nil:
+		sub.Log.Warnln("Remove the subscription without TrafficInfluSub")
+		return nef_context.ReconcileItem{
+			Result: nef_context.ReconcileResultRemoved,
+			Cause:  "No TrafficInfluSub",
+		}
+	case sub.AppSessID != "":
+		return p.reconcileAppSession(sub)
+	case sub.InfluID != "":
+		return p.reconcileInfluenceData(sub)
+	default:
+		sub.Log.Warnln("Remove the subscription without AppSessID or InfluID")
+		return nef_context.ReconcileItem{
+			Result: nef_context.ReconcileResultRemoved,
+			Cause:  "No AppSessID or InfluID",
+		}
+	}
+}
+
+func (p *Processor) reconcileAppSession(sub *nef_context.AfSubscription) nef_context.ReconcileItem {
+	rspCode, _ := p.Consumer().GetAppSession(p.pcfUriOfSub(sub), sub.AppSessID)
+	switch rspCode {
+	case http.StatusOK:
+		return nef_context.ReconcileItem{Result: nef_context.ReconcileResultVerified}
+	case http.StatusNotFound:
+	default:
+		sub.Log.Warnf("App session[%s] is not verified: rspCode[%d]", sub.AppSessID, rspCode)
+		return nef_context.ReconcileItem{
+			Result:  nef_context.ReconcileResultUnverified,
+			Cause:   "Get app session failed",
+			RspCode: rspCode,
+		}
+	}
+
+	// The PDU session may be served by another PCF now
+	pcfUri, pcfID, rsp := p.selectPcfForTrafficInfluSub(sub.TiSub)
+	if rsp != nil {
+		return nef_context.ReconcileItem{
+			Result:  nef_context.ReconcileResultUnverified,
+			Cause:   "Select PCF failed",
+			RspCode: rsp.Status,
+		}
+	}
+	asc := p.convertTrafficInfluSubToAppSessionContext(sub.TiSub, sub.NotifCorreID, sub.Supi)
+	rspCode, _, appSessID := p.Consumer().PostAppSessions(pcfUri, asc)
+	if rspCode != http.StatusCreated {
+		sub.Log.Warnf("App session[%s] is missing and re-creation failed: rspCode[%d]",
+			sub.AppSessID, rspCode)
+		return nef_context.ReconcileItem{
+			Result:  nef_context.ReconcileResultUnverified,
+			Cause:   "Re-create app session failed",
+			RspCode: rspCode,
+		}
+	}
+	sub.Log.Infof("App session[%s] is missing and re-created as [%s]", sub.AppSessID, appSessID)
+	sub.AppSessID = appSessID
+	sub.PcfUri = pcfUri
+	sub.PcfID = pcfID
+	return nef_context.ReconcileItem{
+		Result:   nef_context.ReconcileResultRecreated,
+		Cause:    "App session is missing in PCF",
+		NewResID: appSessID,
+	}
+}
+
+func (p *Processor) reconcileInfluenceData(sub *nef_context.AfSubscription) nef_context.ReconcileItem {
+	rspCode, rspBody := p.Consumer().AppDataInfluenceDataGet([]string{sub.InfluID})
+	if rspCode != http.StatusOK {
+		sub.Log.Warnf("Influence data[%s] is not verified: rspCode[%d]", sub.InfluID, rspCode)
+		return nef_context.ReconcileItem{
+			InfluID: sub.InfluID,
+			Result:  nef_context.ReconcileResultUnverified,
+			Cause:   "Get influence data failed",
+			RspCode: rspCode,
+		}
+	}
+	if tiDatas, ok := rspBody.(*[]models.TrafficInfluData); ok && len(*tiDatas) > 0 {
+		return nef_context.ReconcileItem{Result: nef_context.ReconcileResultVerified}
+	}
+
+	tiData := p.convertTrafficInfluSubToTrafficInfluData(sub.TiSub, sub.NotifCorreID, sub.Supi, sub.InterGroupID)
+	rspCode, _ = p.Consumer().AppDataInfluenceDataPut(sub.InfluID, tiData)
+	if rspCode != http.StatusOK &&
+		rspCode != http.StatusCreated &&
+		rspCode != http.StatusNoContent {
+		sub.Log.Warnf("Influence data[%s] is missing and re-creation failed: rspCode[%d]",
+			sub.InfluID, rspCode)
+		return nef_context.ReconcileItem{
+			InfluID: sub.InfluID,
+			Result:  nef_context.ReconcileResultUnverified,
+			Cause:   "Re-create influence data failed",
+			RspCode: rspCode,
+		}
+	}
+	sub.Log.Infof("Influence data[%s] is missing and re-created", sub.InfluID)
+	return nef_context.ReconcileItem{
+		InfluID: sub.InfluID,
+		Result:  nef_context.ReconcileResultRecreated,
+		Cause:   "Influence data is missing in UDR",
+	}
+}
+
+// deleteOrphanInfluenceData deletes the influence data whose UP path change
+// notifications are sent to this NEF but not referred by any subscription
+func (p *Processor) deleteOrphanInfluenceData(
+	correIDs map[string]struct{},
+	lastCorreID uint64,
+	rpt *nef_context.ReconcileReport,
+) {
+	rspCode, rspBody := p.Consumer().AppDataInfluenceDataGet(nil)
+	if rspCode != http.StatusOK {
+		logger.ProcessorLog.Warnf("Get influence data failed: rspCode[%d], orphans are not checked", rspCode)
+		return
+	}
+	tiDatas, ok := rspBody.(*[]models.TrafficInfluData)
+	if !ok {
+		return
+	}
+
+	notifUri := p.genNotificationUri()
+	for _, tiData := range *tiDatas {
+		if tiData.UpPathChgNotifUri != notifUri {
+			continue
+		}
+		if _, ok = correIDs[tiData.UpPathChgNotifCorreId]; ok {
+			continue
+		}
+		if correID, err := strconv.ParseUint(tiData.UpPathChgNotifCorreId, 10, 64); err == nil &&
+			correID > lastCorreID {
+			continue
+		}
+
+		item := nef_context.ReconcileItem{
+			InfluID: tiData.ResUri[strings.LastIndex(tiData.ResUri, "/")+1:],
+			Result:  nef_context.ReconcileResultOrphan,
+		}
+		if item.InfluID == "" {
+			item.Result = nef_context.ReconcileResultUnverified
+			item.Cause = "Orphan influence data without resUri"
+			logger.ProcessorLog.Warnf("Orphan influence data of notifCorreId[%s] can't be deleted",
+				tiData.UpPathChgNotifCorreId)
+			rpt.AddItem(item)
+			continue
+		}
+
+		rspCode, _ = p.Consumer().AppDataInfluenceDataDelete(item.InfluID)
+		if rspCode != http.StatusOK && rspCode != http.StatusNoContent {
+			item.Result = nef_context.ReconcileResultUnverified
+			item.Cause = "Delete orphan influence data failed"
+			item.RspCode = rspCode
+		}
+		logger.ProcessorLog.Infof("Orphan influence data[%s]: %s", item.InfluID, item.Result)
+		rpt.AddItem(item)
+	}
+}
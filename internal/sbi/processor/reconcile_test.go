@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	nef_context "github.com/free5gc/nef/internal/context"
+	"github.com/free5gc/nef/internal/store"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestReconcileTrafficInfluence(t *testing.T) {
+	initNRFDiscPCFStub()
+	initPCFPaGetAppSessionStub("99999", http.StatusNotFound)
+	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initUDRDrGetTiDataStub("influ1", []models.TrafficInfluData{
+		{
+			UpPathChgNotifUri:     nefApp.Processor().genNotificationUri(),
+			UpPathChgNotifCorreId: "1",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/influ1",
+		},
+	})
+	initUDRDrGetTiDataStub("", []models.TrafficInfluData{
+		{
+			UpPathChgNotifUri:     nefApp.Processor().genNotificationUri(),
+			UpPathChgNotifCorreId: "1",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/influ1",
+		},
+		{
+			UpPathChgNotifUri:     nefApp.Processor().genNotificationUri(),
+			UpPathChgNotifCorreId: "4",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/orphan1",
+		},
+		{
+			// Created for a subscription of the AF during the reconciliation
+			UpPathChgNotifUri:     nefApp.Processor().genNotificationUri(),
+			UpPathChgNotifCorreId: "99",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/new1",
+		},
+		{
+			UpPathChgNotifUri:     "http://127.0.0.99:8000/nnef-callback/v1/notification/smf",
+			UpPathChgNotifCorreId: "98",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/other1",
+		},
+	})
+	initUDRDrDeleteTiDataStub(http.StatusNoContent)
+	defer gock.Off()
+
+	// The orphans are only deleted with a persistent store
+	cfg := nefApp.Config()
+	cfg.Configuration.Persistence = &factory.Persistence{Type: store.TypeFile}
+	defer func() {
+		cfg.Configuration.Persistence = nil
+	}()
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub1ForAf1)
+	afSub1.InfluID = "influ1"
+	af1.Subs[afSub1.SubID] = afSub1
+
+	afSub2 := af1.NewSub(nefCtx.NewCorreID(), &tiSub3ForAf1)
+	afSub2.AppSessID = "99999"
+	af1.Subs[afSub2.SubID] = afSub2
+
+	afSub3 := af1.NewSub(nefCtx.NewCorreID(), &tiSub3ForAf1)
+	af1.Subs[afSub3.SubID] = afSub3
+	// The subscription of the orphan influence data was deleted before the restart
+	nefCtx.NewCorreID()
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+		nefCtx.SetReconcileReport(nil)
+	}()
+
+	rpt := nefApp.Processor().ReconcileTrafficInfluence()
+	require.Equal(t, 1, rpt.NumVerified)
+	require.Equal(t, 1, rpt.NumRecreated)
+	require.Equal(t, 0, rpt.NumUnverified)
+	require.Equal(t, 1, rpt.NumRemoved)
+	require.Equal(t, 1, rpt.NumOrphans)
+	require.ElementsMatch(t, []nef_context.ReconcileItem{
+		{
+			AfID:     "af1",
+			SubID:    afSub2.SubID,
+			Result:   nef_context.ReconcileResultRecreated,
+			Cause:    "App session is missing in PCF",
+			NewResID: "12345",
+		},
+		{
+			AfID:   "af1",
+			SubID:  afSub3.SubID,
+			Result: nef_context.ReconcileResultRemoved,
+			Cause:  "No AppSessID or InfluID",
+		},
+		{
+			InfluID: "orphan1",
+			Result:  nef_context.ReconcileResultOrphan,
+		},
+	}, rpt.Items)
+
+	af1.Mu.RLock()
+	require.Equal(t, "12345", afSub2.AppSessID)
+	require.NotContains(t, af1.Subs, afSub3.SubID)
+	af1.Mu.RUnlock()
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	nefApp.Processor().GetReconcileReport(c)
+	require.Equal(t, http.StatusOK, httpRecorder.Code)
+	assertJSONBodyEqual(t, rpt, httpRecorder.Body.Bytes())
+}
+
+func TestReconcileTrafficInfluenceWithMemoryStore(t *testing.T) {
+	initUDRDrGetTiDataStub("", []models.TrafficInfluData{
+		{
+			UpPathChgNotifUri:     nefApp.Processor().genNotificationUri(),
+			UpPathChgNotifCorreId: "99",
+			ResUri:                "http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/influ99",
+		},
+	})
+	defer gock.Off()
+	var deleted bool
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.Method == http.MethodDelete {
+			deleted = true
+		}
+	})
+	defer gock.Observe(nil)
+	defer nefApp.Context().SetReconcileReport(nil)
+
+	rpt := nefApp.Processor().ReconcileTrafficInfluence()
+	require.Equal(t, 0, rpt.NumOrphans)
+	require.Empty(t, rpt.Items)
+	require.False(t, deleted)
+}
+
+func TestReconcileTrafficInfluenceSubDeletedByAf(t *testing.T) {
+	initNRFDiscPCFStub()
+	initPCFPaGetAppSessionStub("99999", http.StatusNotFound)
+	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initPCFPaDeleteAppSessionsStub(http.StatusNoContent)
+	defer gock.Off()
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	afSub1 := af1.NewSub(nefCtx.NewCorreID(), &tiSub3ForAf1)
+	afSub1.AppSessID = "99999"
+	af1.Subs[afSub1.SubID] = afSub1
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+		nefCtx.SetReconcileReport(nil)
+	}()
+
+	// The AF deletes the subscription while the PCF is requested
+	var appSessDeleted bool
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		switch {
+		case request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/app-sessions/99999"):
+			af1.Mu.Lock()
+			delete(af1.Subs, afSub1.SubID)
+			af1.Mu.Unlock()
+		case strings.HasSuffix(request.URL.Path, "/app-sessions/12345/delete"):
+			appSessDeleted = true
+		}
+	})
+	defer gock.Observe(nil)
+
+	done := make(chan *nef_context.ReconcileReport)
+	go func() {
+		done <- nefApp.Processor().ReconcileTrafficInfluence()
+	}()
+	select {
+	case rpt := <-done:
+		require.Equal(t, 1, rpt.NumRecreated)
+	case <-time.After(time.Second):
+		t.Fatal("Reconciliation is blocked by the lock of the AF")
+	}
+
+	// The app session re-created for the deleted subscription is not left in the PCF
+	require.True(t, appSessDeleted)
+	af1.Mu.RLock()
+	require.NotContains(t, af1.Subs, afSub1.SubID)
+	af1.Mu.RUnlock()
+}
+
+func initPCFPaGetAppSessionStub(appSessID string, statusCode int) {
+	gock.New("http://127.0.0.7:8000/npcf-policyauthorization/v1").
+		Get("/app-sessions/" + appSessID).
+		Persist().
+		Reply(statusCode).
+		JSON(models.ProblemDetails{
+			Status: int32(statusCode),
+		})
+}
+
+// initUDRDrGetTiDataStub replies tiDatas to the query of influenceID,
+// or to the query of all influence data if influenceID is empty
+func initUDRDrGetTiDataStub(influenceID string, tiDatas []models.TrafficInfluData) {
+	req := gock.New("http://127.0.0.4:8000/nudr-dr/v1").
+		Get("/application-data/influenceData")
+	if influenceID != "" {
+		req.MatchParam("influence-Ids", influenceID)
+	}
+	req.Persist().
+		Reply(http.StatusOK).
+		JSON(tiDatas)
+}
@@ -0,0 +1,304 @@
+package processor
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (p *Processor) GetServiceParameterSubscriptions(
+	c *gin.Context,
+	afID string,
+) {
+	logger.SvcParamLog.Infof("GetServiceParameterSubscriptions - afID[%s]", afID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	spDatas := []nef_models.ServiceParameterData{}
+	for _, sub := range af.SpSubs {
+		spDatas = append(spDatas, *sub.SpData)
+	}
+	c.JSON(http.StatusOK, &spDatas)
+}
+
+// PostServiceParameterSubscription stores the service parameters in the UDR,
+// which are used by the PCF to provision the UE policies, e.g. URSP.
+func (p *Processor) PostServiceParameterSubscription(
+	c *gin.Context,
+	afID string,
+	spData *nef_models.ServiceParameterData,
+) {
+	logger.SvcParamLog.Infof("PostServiceParameterSubscription - afID[%s]", afID)
+
+	if rsp := validateServiceParameterData(spData); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	supi, rsp := p.translateGpsiToSupi(spData.Gpsi)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af := nefCtx.GetAf(afID)
+	if af == nil {
+		af = nefCtx.NewAf(afID)
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub := af.NewSpSub(spData)
+	sub.SpID = uuid.New().String()
+	sub.Supi = supi
+	rspStatus, rspBody := p.Consumer().AppDataServiceParamDataPut(sub.SpID,
+		convertServiceParameterDataToServiceParamData(spData, supi))
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusCreated &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+	spData.Self = p.genServiceParameterSubURI(afID, sub.SubID)
+
+	af.SpSubs[sub.SubID] = sub
+	af.Log.Infoln("Service parameter subscription is added")
+
+	nefCtx.AddAf(af)
+
+	c.Header("Location", spData.Self)
+	c.JSON(http.StatusCreated, spData)
+}
+
+func (p *Processor) GetIndividualServiceParameterSubscription(
+	c *gin.Context,
+	afID, subID string,
+) {
+	logger.SvcParamLog.Infof("GetIndividualServiceParameterSubscription - afID[%s], subID[%s]", afID, subID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	defer af.Mu.RUnlock()
+
+	sub, ok := af.SpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub.SpData)
+}
+
+func (p *Processor) PutIndividualServiceParameterSubscription(
+	c *gin.Context,
+	afID, subID string,
+	spData *nef_models.ServiceParameterData,
+) {
+	logger.SvcParamLog.Infof("PutIndividualServiceParameterSubscription - afID[%s], subID[%s]", afID, subID)
+
+	if rsp := validateServiceParameterData(spData); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.SpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	supi := sub.Supi
+	if spData.Gpsi != sub.SpData.Gpsi {
+		var rsp *HandlerResponse
+		if supi, rsp = p.translateGpsiToSupi(spData.Gpsi); rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+	}
+
+	rspStatus, rspBody := p.Consumer().AppDataServiceParamDataPut(sub.SpID,
+		convertServiceParameterDataToServiceParamData(spData, supi))
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusCreated &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	spData.Self = sub.SpData.Self
+	sub.SpData = spData
+	sub.Supi = supi
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, sub.SpData)
+}
+
+func (p *Processor) PatchIndividualServiceParameterSubscription(
+	c *gin.Context,
+	afID, subID string,
+	spPatch *nef_models.ServiceParameterDataPatch,
+) {
+	logger.SvcParamLog.Infof("PatchIndividualServiceParameterSubscription - afID[%s], subID[%s]", afID, subID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.SpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().AppDataServiceParamDataPatch(sub.SpID,
+		&nef_models.ServiceParamDataPatch{
+			ParamOverPc5: spPatch.ParamOverPc5,
+			ParamOverUu:  spPatch.ParamOverUu,
+			UrspGuidance: spPatch.UrspGuidance,
+		})
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	sub.PatchSpData(spPatch)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusOK, sub.SpData)
+}
+
+func (p *Processor) DeleteIndividualServiceParameterSubscription(
+	c *gin.Context,
+	afID, subID string,
+) {
+	logger.SvcParamLog.Infof("DeleteIndividualServiceParameterSubscription - afID[%s], subID[%s]", afID, subID)
+
+	af := p.Context().GetAf(afID)
+	if af == nil {
+		pd := openapi.ProblemDetailsDataNotFound("AF is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.Lock()
+	defer af.Mu.Unlock()
+
+	sub, ok := af.SpSubs[subID]
+	if !ok {
+		pd := openapi.ProblemDetailsDataNotFound("Subscription is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	rspStatus, rspBody := p.Consumer().AppDataServiceParamDataDelete(sub.SpID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		c.JSON(rspStatus, rspBody)
+		return
+	}
+
+	delete(af.SpSubs, subID)
+	p.Context().StoreAf(af)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func validateServiceParameterData(spData *nef_models.ServiceParameterData) *HandlerResponse {
+	// TS29.522: One of "afServiceId", "appId", or the combination of "dnn" and "snssai" shall be included.
+	if spData.AfServiceId == "" &&
+		spData.AppId == "" &&
+		(spData.Dnn == "" || spData.Snssai == nil) {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Missing one of afServiceId, appId, or dnn and snssai")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	// TS29.522: One of individual UE identifier
+	// (i.e. "gpsi", "ueIpv4Addr", "ueIpv6Addr" or "ueMacAddr"),
+	// External Group Identifier (i.e. "externalGroupId") or
+	// any UE indication "anyUeInd" shall be included.
+	if spData.Gpsi == "" &&
+		spData.UeIpv4Addr == "" &&
+		spData.UeIpv6Addr == "" &&
+		spData.UeMacAddr == "" &&
+		spData.ExternalGroupId == "" &&
+		!spData.AnyUeInd {
+		pd := openapi.ProblemDetailsMalformedReqSyntax(
+			"Missing one of gpsi, ueIpv4Addr, ueIpv6Addr, ueMacAddr, externalGroupId or anyUeInd")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if spData.ExternalGroupId != "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("externalGroupId is not supported")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+
+	if spData.ParamOverPc5 == "" &&
+		spData.ParamOverUu == "" &&
+		len(spData.UrspGuidance) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Missing one of paramOverPc5, paramOverUu or urspGuidance")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genServiceParameterSubURI(afID, subID string) string {
+	// E.g. https://localhost:29505/3gpp-service-parameter/v1/{afId}/subscriptions/{subscriptionId}
+	return p.Config().ServiceUri(factory.ServiceSvcParam) + "/" + afID + "/subscriptions/" + subID
+}
+
+func convertServiceParameterDataToServiceParamData(
+	spData *nef_models.ServiceParameterData,
+	supi string,
+) *nef_models.ServiceParamData {
+	return &nef_models.ServiceParamData{
+		AppId:        spData.AppId,
+		Dnn:          spData.Dnn,
+		Snssai:       spData.Snssai,
+		Supi:         supi,
+		UeIpv4:       spData.UeIpv4Addr,
+		UeIpv6:       spData.UeIpv6Addr,
+		UeMac:        spData.UeMacAddr,
+		AnyUeInd:     spData.AnyUeInd,
+		ParamOverPc5: spData.ParamOverPc5,
+		ParamOverUu:  spData.ParamOverUu,
+		UrspGuidance: spData.UrspGuidance,
+		SuppFeat:     spData.SuppFeat,
+	}
+}
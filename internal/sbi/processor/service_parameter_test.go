@@ -0,0 +1,216 @@
+package processor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var spData1ForAf1 = nef_models.ServiceParameterData{
+	AppId:        "app1",
+	Gpsi:         "msisdn-0900000000",
+	ParamOverPc5: "cGFyYW1PdmVyUGM1",
+}
+
+func TestPostServiceParameterSubscription(t *testing.T) {
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_SDM, "127.0.0.3")
+	udmStub := initUDMSdmIdTranslationStub("msisdn-0900000000", "imsi-208930000000001")
+	defer gock.Remove(udmStub)
+	udmUnknownStub := initUDMSdmIdTranslationStub("msisdn-0900000001", "")
+	defer gock.Remove(udmUnknownStub)
+	udrStub := initUDRDrServiceParamDataStub(http.MethodPut, http.StatusCreated)
+	defer gock.Remove(udrStub)
+	var udrReqBody []byte
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/serviceParamData/") {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			udrReqBody = body
+		}
+	})
+	defer gock.Observe(nil)
+
+	rspSpData1 := spData1ForAf1
+	rspSpData1.Self = nefApp.Processor().genServiceParameterSubURI("af1", "1")
+
+	spDataNoAppID := spData1ForAf1
+	spDataNoAppID.AppId = ""
+	spDataNoAppID.Dnn = "internet"
+
+	spDataExtGroupID := spData1ForAf1
+	spDataExtGroupID.Gpsi = ""
+	spDataExtGroupID.ExternalGroupId = "group1@free5gc.org"
+
+	spDataUnknownGpsi := spData1ForAf1
+	spDataUnknownGpsi.Gpsi = "msisdn-0900000001"
+
+	testCases := []struct {
+		description      string
+		spData           nef_models.ServiceParameterData
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription, should store service parameters in UDR",
+			spData:      spData1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspSpData1.Self},
+				},
+				Body: &rspSpData1,
+			},
+		},
+		{
+			description: "TC2: Absent of snssai with dnn",
+			spData:      spDataNoAppID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "Missing one of afServiceId, appId, or dnn and snssai",
+				},
+			},
+		},
+		{
+			description: "TC3: externalGroupId is not supported",
+			spData:      spDataExtGroupID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "externalGroupId is not supported",
+				},
+			},
+		},
+		{
+			description: "TC4: Unknown GPSI",
+			spData:      spDataUnknownGpsi,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Title:  "Data not found",
+					Status: http.StatusNotFound,
+					Detail: "GPSI is not found",
+					Cause:  "USER_NOT_FOUND",
+				},
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			spData := tc.spData
+			nefApp.Processor().PostServiceParameterSubscription(c, "af1", &spData)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+
+			for k, v := range tc.expectedResponse.Headers {
+				require.ElementsMatch(t, v, httpRecorder.Header().Values(k))
+			}
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	assertJSONBodyEqual(t, &nef_models.ServiceParamData{
+		AppId:        "app1",
+		Supi:         "imsi-208930000000001",
+		ParamOverPc5: "cGFyYW1PdmVyUGM1",
+	}, udrReqBody)
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "imsi-208930000000001", af.SpSubs["1"].Supi)
+	require.NotEmpty(t, af.SpSubs["1"].SpID)
+	af.Mu.RUnlock()
+}
+
+func TestPatchIndividualServiceParameterSubscription(t *testing.T) {
+	udrStub := initUDRDrServiceParamDataStub(http.MethodPatch, http.StatusNoContent)
+	defer gock.Remove(udrStub)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	spData := spData1ForAf1
+	spData.Self = nefApp.Processor().genServiceParameterSubURI("af1", "1")
+	sub := af1.NewSpSub(&spData)
+	sub.SpID = "sp1"
+	af1.SpSubs[sub.SubID] = sub
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+	}()
+
+	rspSpData := spData
+	rspSpData.ParamOverUu = "cGFyYW1PdmVyVXU="
+
+	testCases := []struct {
+		description      string
+		subID            string
+		spPatch          nef_models.ServiceParameterDataPatch
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Subscription is not found",
+			subID:       "2",
+			spPatch:     nef_models.ServiceParameterDataPatch{ParamOverUu: "cGFyYW1PdmVyVXU="},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "Subscription is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Successful modification, should only update the patched parameters",
+			subID:       "1",
+			spPatch:     nef_models.ServiceParameterDataPatch{ParamOverUu: "cGFyYW1PdmVyVXU="},
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &rspSpData,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			spPatch := tc.spPatch
+			nefApp.Processor().PatchIndividualServiceParameterSubscription(c, "af1", tc.subID, &spPatch)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+}
+
+func initUDRDrServiceParamDataStub(method string, status int) gock.Mock {
+	req := gock.New("http://127.0.0.4:8000/nudr-dr/v1")
+	req.Method = method
+	req.Path("/application-data/serviceParamData/.+").
+		Persist().
+		Reply(status)
+	return req.Mock
+}
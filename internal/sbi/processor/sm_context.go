@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"net/http"
+
+	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/nef/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// PostSmContext creates the SM context of the NEF-anchored PDU session for the UE
+// configured for NIDD, and delivers the downlink data buffered for the UE.
+func (p *Processor) PostSmContext(
+	c *gin.Context,
+	createData *nef_models.SmContextCreateData,
+) {
+	logger.NiddLog.Infof("PostSmContext - gpsi[%s], pduSessionId[%d]", createData.Gpsi, createData.PduSessionId)
+
+	if rsp := validateSmContextCreateData(createData); rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
+	nefCtx := p.Context()
+	af, cfg := nefCtx.FindAfNiddCfg(createData.Gpsi, createData.Dnn)
+	if cfg == nil {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	af.Mu.RLock()
+	maxPacketSize := cfg.NiddCfg.MaximumPacketSize
+	af.Mu.RUnlock()
+
+	smCtx := nefCtx.NewSmContext(createData)
+
+	go p.deliverBufferedNiddData(smCtx)
+
+	c.Header("Location", p.genSmContextURI(smCtx.SmContextID))
+	c.JSON(http.StatusCreated, &nef_models.SmContextCreatedData{
+		Gpsi:              createData.Gpsi,
+		PduSessionId:      createData.PduSessionId,
+		Dnn:               createData.Dnn,
+		MaximumPacketSize: maxPacketSize,
+		SupportedFeatures: createData.SupportedFeatures,
+	})
+}
+
+// PostSmContextRelease releases the SM context when the PDU session is released
+func (p *Processor) PostSmContextRelease(
+	c *gin.Context,
+	smContextID string,
+) {
+	logger.NiddLog.Infof("PostSmContextRelease - smContextID[%s]", smContextID)
+
+	nefCtx := p.Context()
+	if nefCtx.GetSmContext(smContextID) == nil {
+		pd := openapi.ProblemDetailsDataNotFound("SM context is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	nefCtx.DeleteSmContext(smContextID)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PostSmContextDelivery delivers the MO data of the UE to the AF of the NIDD configuration
+func (p *Processor) PostSmContextDelivery(
+	c *gin.Context,
+	smContextID string,
+	moData *nef_models.SmContextDeliverData,
+) {
+	logger.NiddLog.Infof("PostSmContextDelivery - smContextID[%s]", smContextID)
+
+	nefCtx := p.Context()
+	smCtx := nefCtx.GetSmContext(smContextID)
+	if smCtx == nil {
+		pd := openapi.ProblemDetailsDataNotFound("SM context is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+	if len(moData.Data) == 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of data")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
+	af, cfg := nefCtx.FindAfNiddCfg(smCtx.CreateData.Gpsi, smCtx.CreateData.Dnn)
+	if cfg == nil {
+		pd := openapi.ProblemDetailsDataNotFound("NIDD configuration is not found")
+		c.JSON(http.StatusNotFound, pd)
+		return
+	}
+
+	af.Mu.RLock()
+	notifDest := cfg.NiddCfg.NotificationDestination
+	ulNotif := &nef_models.NiddUplinkDataNotification{
+		Niddconfiguration: cfg.NiddCfg.Self,
+		ExternalId:        cfg.NiddCfg.ExternalId,
+		Msisdn:            cfg.NiddCfg.Msisdn,
+		Data:              moData.Data,
+	}
+	af.Mu.RUnlock()
+
+	if err := p.Notifier().NiddNotifier.NotifyUplinkData(notifDest, ulNotif); err != nil {
+		cfg.Log.Errorf("Notify uplink data failed: %+v", err)
+		pd := openapi.ProblemDetailsSystemFailure(err.Error())
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+	cfg.Log.Infoln("Uplink data is notified")
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func validateSmContextCreateData(createData *nef_models.SmContextCreateData) *HandlerResponse {
+	if createData.Gpsi == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of gpsi")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if createData.PduSessionId <= 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Invalid pduSessionId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if createData.Dnn == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of dnn")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if createData.SmfId == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of smfId")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	if createData.PduSessionRef == "" {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("Absent of pduSessionRef")
+		return &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return nil
+}
+
+func (p *Processor) genSmContextURI(smContextID string) string {
+	// E.g. https://localhost:29505/nnef-smcontext/v1/sm-contexts/{smContextId}
+	return p.Config().ServiceUri(factory.ServiceNefSmCtx) + "/sm-contexts/" + smContextID
+}
@@ -0,0 +1,272 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestPostSmContext(t *testing.T) {
+	initNRFDiscStub(models.NfType_SMF, "nsmf-nidd", "127.0.0.2")
+	smfStub := initSMFNiddDeliverStub("pdusess1", http.StatusNoContent)
+	defer gock.Remove(smfStub)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/nidd/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	afNotifChan := make(chan *http.Request, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/nidd/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	niddCfg := niddCfg1ForAf1
+	niddCfg.MaximumPacketSize = niddDefaultMaxPacketSize
+	niddCfg.Status = nef_models.NiddStatus_ACTIVE
+	cfg := af1.NewNiddCfg(&niddCfg)
+	cfg.Gpsi = "extid-ue1@free5gc.org"
+	niddCfg.Self = nefApp.Processor().genNiddConfigurationURI("af1", cfg.ConfigID)
+	dlID := cfg.NewDlDataID()
+	cfg.DlDatas[dlID] = &nef_models.NiddDownlinkDataTransfer{
+		ExternalId:     "ue1@free5gc.org",
+		Self:           nefApp.Processor().genNiddDownlinkDataDeliveryURI("af1", cfg.ConfigID, dlID),
+		Data:           []byte("dl1"),
+		DeliveryStatus: nef_models.DeliveryStatus_BUFFERING,
+	}
+	af1.NiddCfgs[cfg.ConfigID] = cfg
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	createDataOtherDnn := smCtxCreateDataForUe1
+	createDataOtherDnn.Dnn = "internet"
+
+	testCases := []struct {
+		description      string
+		createData       nef_models.SmContextCreateData
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: UE isn't configured for NIDD on the DNN",
+			createData:  createDataOtherDnn,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "NIDD configuration is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Successful creation, should deliver buffered data",
+			createData:  smCtxCreateDataForUe1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Body: &nef_models.SmContextCreatedData{
+					Gpsi:              "extid-ue1@free5gc.org",
+					PduSessionId:      1,
+					Dnn:               "nidd",
+					MaximumPacketSize: niddDefaultMaxPacketSize,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			createData := tc.createData
+			nefApp.Processor().PostSmContext(c, &createData)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+		})
+	}
+
+	smCtx := nefCtx.FindSmContext("extid-ue1@free5gc.org", "nidd")
+	require.NotNil(t, smCtx)
+	defer nefCtx.DeleteSmContext(smCtx.SmContextID)
+
+	select {
+	case req := <-afNotifChan:
+		var dlNotif nef_models.NiddDownlinkDataDeliveryStatusNotification
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&dlNotif))
+		require.Equal(t, nef_models.NiddDownlinkDataDeliveryStatusNotification{
+			Niddconfiguration:        niddCfg.Self,
+			ExternalId:               "ue1@free5gc.org",
+			NiddDownlinkDataTransfer: cfg.DlDatas[dlID].Self,
+			DeliveryStatus:           nef_models.DeliveryStatus_SUCCESS,
+		}, dlNotif)
+	case <-time.After(time.Second):
+		t.Fatal("AF is not notified")
+	}
+
+	af1.Mu.RLock()
+	require.Empty(t, cfg.BufferedDlDataIDs())
+	af1.Mu.RUnlock()
+}
+
+func TestPostSmContextDelivery(t *testing.T) {
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/nidd/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	afNotifChan := make(chan *http.Request, 1)
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/nidd/notify") {
+			afNotifChan <- request
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	niddCfg := niddCfg1ForAf1
+	niddCfg.Status = nef_models.NiddStatus_ACTIVE
+	cfg := af1.NewNiddCfg(&niddCfg)
+	cfg.Gpsi = "extid-ue1@free5gc.org"
+	niddCfg.Self = nefApp.Processor().genNiddConfigurationURI("af1", cfg.ConfigID)
+	af1.NiddCfgs[cfg.ConfigID] = cfg
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	createData := smCtxCreateDataForUe1
+	smCtx := nefCtx.NewSmContext(&createData)
+	defer func() {
+		nefCtx.DeleteSmContext(smCtx.SmContextID)
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	testCases := []struct {
+		description      string
+		smContextID      string
+		expectedResponse *HandlerResponse
+		expectedNotif    *nef_models.NiddUplinkDataNotification
+	}{
+		{
+			description: "TC1: SM context is not found",
+			smContextID: "99",
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Status: http.StatusNotFound,
+					Title:  "Data not found",
+					Detail: "SM context is not found",
+				},
+			},
+		},
+		{
+			description: "TC2: Successful delivery, should notify AF of uplink data",
+			smContextID: smCtx.SmContextID,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNoContent,
+			},
+			expectedNotif: &nef_models.NiddUplinkDataNotification{
+				Niddconfiguration: niddCfg.Self,
+				ExternalId:        "ue1@free5gc.org",
+				Data:              []byte("ul1"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			nefApp.Processor().PostSmContextDelivery(c, tc.smContextID,
+				&nef_models.SmContextDeliverData{Data: []byte("ul1")})
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+
+			if tc.expectedNotif != nil {
+				select {
+				case req := <-afNotifChan:
+					var ulNotif nef_models.NiddUplinkDataNotification
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&ulNotif))
+					require.Equal(t, *tc.expectedNotif, ulNotif)
+				case <-time.After(time.Second):
+					t.Fatal("AF is not notified")
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverBufferedNiddDataConcurrently(t *testing.T) {
+	initNRFDiscStub(models.NfType_SMF, "nsmf-nidd", "127.0.0.2")
+	smfReq := gock.New("http://127.0.0.2:8000/nsmf-nidd/v1")
+	smfReq.Post("/pdu-sessions/pdusess1/deliver").
+		Persist().
+		Reply(http.StatusNoContent).
+		Delay(50 * time.Millisecond)
+	defer gock.Remove(smfReq.Mock)
+	afNotifStub := initAFNotificationStub("http://127.0.0.100:8000", "/nidd/notify", http.StatusNoContent)
+	defer gock.Remove(afNotifStub)
+	var numSmfReqs atomic.Int32
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.String(), "/deliver") {
+			numSmfReqs.Add(1)
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	niddCfg := niddCfg1ForAf1
+	niddCfg.Status = nef_models.NiddStatus_ACTIVE
+	cfg := af1.NewNiddCfg(&niddCfg)
+	cfg.Gpsi = "extid-ue1@free5gc.org"
+	dlID := cfg.NewDlDataID()
+	cfg.DlDatas[dlID] = &nef_models.NiddDownlinkDataTransfer{
+		ExternalId:     "ue1@free5gc.org",
+		Data:           []byte("dl1"),
+		DeliveryStatus: nef_models.DeliveryStatus_BUFFERING,
+	}
+	af1.NiddCfgs[cfg.ConfigID] = cfg
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+	}()
+
+	createData := smCtxCreateDataForUe1
+	smCtx := nefCtx.NewSmContext(&createData)
+	defer nefCtx.DeleteSmContext(smCtx.SmContextID)
+
+	// The buffered data is delivered once, even if the deliveries are triggered at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nefApp.Processor().deliverBufferedNiddData(smCtx)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), numSmfReqs.Load())
+	af1.Mu.RLock()
+	require.Equal(t, nef_models.DeliveryStatus_SUCCESS, cfg.DlDatas[dlID].DeliveryStatus)
+	af1.Mu.RUnlock()
+}
@@ -2,8 +2,11 @@ package processor
 
 import (
 	"net/http"
+	"reflect"
 
+	nef_context "github.com/free5gc/nef/internal/context"
 	"github.com/free5gc/nef/internal/logger"
+	nef_models "github.com/free5gc/nef/internal/models"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
@@ -51,6 +54,12 @@ func (p *Processor) PostTrafficInfluenceSubscription(
 		return
 	}
 
+	supi, rsp := p.translateGpsiToSupi(tiSub.Gpsi)
+	if rsp != nil {
+		c.JSON(rsp.Status, rsp.Body)
+		return
+	}
+
 	nefCtx := p.Context()
 	af := nefCtx.GetAf(afID)
 	if af == nil {
@@ -73,18 +82,34 @@ func (p *Processor) PostTrafficInfluenceSubscription(
 		return
 	}
 
-	if len(tiSub.Gpsi) > 0 || len(tiSub.Ipv4Addr) > 0 || len(tiSub.Ipv6Addr) > 0 {
+	if len(tiSub.Gpsi) > 0 || len(tiSub.MacAddr) > 0 ||
+		len(tiSub.Ipv4Addr) > 0 || len(tiSub.Ipv6Addr) > 0 {
 		// Single UE, sent to PCF
-		asc := p.convertTrafficInfluSubToAppSessionContext(tiSub, afSub.NotifCorreID)
-		rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions(asc)
+		pcfUri, pcfID, rsp := p.selectPcfForTrafficInfluSub(tiSub)
+		if rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+		asc := p.convertTrafficInfluSubToAppSessionContext(tiSub, afSub.NotifCorreID, supi)
+		rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions(pcfUri, asc)
 		if rspStatus != http.StatusCreated {
 			c.JSON(rspStatus, rspBody)
+			return
 		}
 		afSub.AppSessID = appSessID
+		afSub.PcfUri = pcfUri
+		afSub.PcfID = pcfID
+		afSub.Supi = supi
 	} else if len(tiSub.ExternalGroupId) > 0 || tiSub.AnyUeInd {
 		// Group or any UE, sent to UDR
+		interGroupID, rsp := p.translateExternalGroupId(tiSub.ExternalGroupId)
+		if rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
 		afSub.InfluID = uuid.New().String()
-		tiData := p.convertTrafficInfluSubToTrafficInfluData(tiSub, afSub.NotifCorreID)
+		afSub.InterGroupID = interGroupID
+		tiData := p.convertTrafficInfluSubToTrafficInfluData(tiSub, afSub.NotifCorreID, supi, interGroupID)
 		rspStatus, rspBody := p.Consumer().AppDataInfluenceDataPut(afSub.InfluID, tiData)
 		if rspStatus != http.StatusOK &&
 			rspStatus != http.StatusCreated &&
@@ -92,6 +117,7 @@ func (p *Processor) PostTrafficInfluenceSubscription(
 			c.JSON(rspStatus, rspBody)
 			return
 		}
+		p.subscribeInfluenceDataChange(afSub, tiData)
 	} else {
 		// Invalid case. Return Error
 		pd := openapi.ProblemDetailsMalformedReqSyntax("Not individual UE case, nor group case")
@@ -99,13 +125,14 @@ func (p *Processor) PostTrafficInfluenceSubscription(
 		return
 	}
 
+	// Create Location URI, which is kept with the stored subscription
+	tiSub.Self = p.genTrafficInfluSubURI(afID, afSub.SubID)
+
 	af.Subs[afSub.SubID] = afSub
 	af.Log.Infoln("Subscription is added")
 
 	nefCtx.AddAf(af)
 
-	// Create Location URI
-	tiSub.Self = p.genTrafficInfluSubURI(afID, afSub.SubID)
 	headers := map[string][]string{
 		"Location": {tiSub.Self},
 	}
@@ -174,17 +201,43 @@ func (p *Processor) PutIndividualTrafficInfluenceSubscription(
 		return
 	}
 
-	afSub.TiSub = tiSub
+	supi := afSub.Supi
+	if tiSub.Gpsi != afSub.TiSub.Gpsi {
+		if supi, rsp = p.translateGpsiToSupi(tiSub.Gpsi); rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+	}
+	interGroupID := afSub.InterGroupID
+	if afSub.InfluID != "" && tiSub.ExternalGroupId != afSub.TiSub.ExternalGroupId {
+		if interGroupID, rsp = p.translateExternalGroupId(tiSub.ExternalGroupId); rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+	}
+
 	if afSub.AppSessID != "" {
-		asc := p.convertTrafficInfluSubToAppSessionContext(tiSub, afSub.NotifCorreID)
-		rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions(asc)
-		if rspStatus != http.StatusCreated {
-			c.JSON(rspStatus, rspBody)
+		// The UE address may be changed, so the PCF is selected again
+		pcfUri, pcfID, rsp := p.selectPcfForTrafficInfluSub(tiSub)
+		if rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
 			return
 		}
-		afSub.AppSessID = appSessID
+		if pcfUri == p.pcfUriOfSub(afSub) && isSameUeSession(afSub.TiSub, tiSub) {
+			ascUpdateData := p.convertTrafficInfluSubToAppSessionContextUpdateData(tiSub, afSub.NotifCorreID)
+			rspStatus, rspBody := p.Consumer().PatchAppSession(afSub.PcfUri, afSub.AppSessID, ascUpdateData)
+			if rspStatus != http.StatusOK &&
+				rspStatus != http.StatusNoContent {
+				c.JSON(rspStatus, rspBody)
+				return
+			}
+		} else if rsp = p.replaceAppSession(afSub, pcfUri, pcfID, tiSub, supi); rsp != nil {
+			c.JSON(rsp.Status, rsp.Body)
+			return
+		}
+		afSub.Supi = supi
 	} else if afSub.InfluID != "" {
-		tiData := p.convertTrafficInfluSubToTrafficInfluData(tiSub, afSub.NotifCorreID)
+		tiData := p.convertTrafficInfluSubToTrafficInfluData(tiSub, afSub.NotifCorreID, supi, interGroupID)
 		rspStatus, rspBody := p.Consumer().AppDataInfluenceDataPut(afSub.InfluID, tiData)
 		if rspStatus != http.StatusOK &&
 			rspStatus != http.StatusCreated &&
@@ -192,15 +245,74 @@ func (p *Processor) PutIndividualTrafficInfluenceSubscription(
 			c.JSON(rspStatus, rspBody)
 			return
 		}
+		afSub.Supi = supi
+		afSub.InterGroupID = interGroupID
+		// The DNN or S-NSSAI of the subscription to the changes may be changed
+		p.unsubscribeInfluenceDataChange(afSub)
+		p.subscribeInfluenceDataChange(afSub, tiData)
 	} else {
 		pd := openapi.ProblemDetailsDataNotFound("No AppSessID or InfluID")
 		c.JSON(int(pd.Status), pd)
 		return
 	}
 
+	tiSub.Self = afSub.TiSub.Self
+	afSub.TiSub = tiSub
+	p.Context().StoreAf(af)
 	c.JSON(http.StatusOK, afSub.TiSub)
 }
 
+// replaceAppSession creates the app session for the updated subscription at the selected PCF,
+// and then deletes the previous one, which would otherwise be left in the previous PCF.
+func (p *Processor) replaceAppSession(
+	afSub *nef_context.AfSubscription,
+	pcfUri, pcfID string,
+	tiSub *models_nef.TrafficInfluSub,
+	supi string,
+) *HandlerResponse {
+	asc := p.convertTrafficInfluSubToAppSessionContext(tiSub, afSub.NotifCorreID, supi)
+	rspStatus, rspBody, appSessID := p.Consumer().PostAppSessions(pcfUri, asc)
+	if rspStatus != http.StatusCreated {
+		return &HandlerResponse{rspStatus, nil, rspBody}
+	}
+
+	rspStatus, rspBody = p.Consumer().DeleteAppSession(p.pcfUriOfSub(afSub), afSub.AppSessID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent &&
+		rspStatus != http.StatusNotFound {
+		afSub.Log.Warnf("Delete previous app session[%s] failed: %d, %+v", afSub.AppSessID, rspStatus, rspBody)
+	}
+	afSub.AppSessID = appSessID
+	afSub.PcfUri = pcfUri
+	afSub.PcfID = pcfID
+	return nil
+}
+
+// pcfUriOfSub returns the PCF holding the app session of the subscription.
+// The PCF is discovered again by its NF instance ID if the URI is dropped
+// due to the status change of the PCF.
+func (p *Processor) pcfUriOfSub(sub *nef_context.AfSubscription) string {
+	if sub.PcfUri == "" && sub.PcfID != "" {
+		pcfUri, err := p.Consumer().DiscoverPcfPolicyAuthUri(sub.PcfID)
+		if err != nil {
+			sub.Log.Warnf("Discover PCF[%s] failed: %+v", sub.PcfID, err)
+			return ""
+		}
+		sub.PcfUri = pcfUri
+	}
+	return sub.PcfUri
+}
+
+// isSameUeSession tells whether the subscriptions target the same PDU session,
+// i.e. the app session at the PCF can be updated rather than re-created.
+func isSameUeSession(oldTiSub, newTiSub *models_nef.TrafficInfluSub) bool {
+	return oldTiSub.Ipv4Addr == newTiSub.Ipv4Addr &&
+		oldTiSub.Ipv6Addr == newTiSub.Ipv6Addr &&
+		oldTiSub.MacAddr == newTiSub.MacAddr &&
+		oldTiSub.Dnn == newTiSub.Dnn &&
+		reflect.DeepEqual(oldTiSub.Snssai, newTiSub.Snssai)
+}
+
 func (p *Processor) PatchIndividualTrafficInfluenceSubscription(
 	c *gin.Context,
 	afID, subID string,
@@ -225,9 +337,15 @@ func (p *Processor) PatchIndividualTrafficInfluenceSubscription(
 		return
 	}
 
+	if afSub.TiSub.MacAddr != "" && len(tiSubPatch.TrafficFilters) > 0 {
+		pd := openapi.ProblemDetailsMalformedReqSyntax("trafficFilters is not applicable for macAddr")
+		c.JSON(int(pd.Status), pd)
+		return
+	}
+
 	if afSub.AppSessID != "" {
 		ascUpdateData := p.convertTrafficInfluSubPatchToAppSessionContextUpdateData(tiSubPatch)
-		rspStatus, rspBody := p.Consumer().PatchAppSession(afSub.AppSessID, ascUpdateData)
+		rspStatus, rspBody := p.Consumer().PatchAppSession(p.pcfUriOfSub(afSub), afSub.AppSessID, ascUpdateData)
 		if rspStatus != http.StatusOK &&
 			rspStatus != http.StatusNoContent {
 			c.JSON(rspStatus, rspBody)
@@ -248,6 +366,7 @@ func (p *Processor) PatchIndividualTrafficInfluenceSubscription(
 	}
 
 	afSub.PatchTiSubData(tiSubPatch)
+	p.Context().StoreAf(af)
 	c.JSON(http.StatusOK, afSub.TiSub)
 }
 
@@ -275,13 +394,15 @@ func (p *Processor) DeleteIndividualTrafficInfluenceSubscription(
 	}
 
 	if sub.AppSessID != "" {
-		rspStatus, rspBody := p.Consumer().DeleteAppSession(sub.AppSessID)
+		rspStatus, rspBody := p.Consumer().DeleteAppSession(p.pcfUriOfSub(sub), sub.AppSessID)
 		if rspStatus != http.StatusOK &&
 			rspStatus != http.StatusNoContent {
 			c.JSON(rspStatus, rspBody)
 			return
 		}
 	} else {
+		// Unsubscribe first, since the removal of the data by the NEF needn't be notified
+		p.unsubscribeInfluenceDataChange(sub)
 		rspStatus, rspBody := p.Consumer().AppDataInfluenceDataDelete(sub.InfluID)
 		if rspStatus != http.StatusOK &&
 			rspStatus != http.StatusNoContent {
@@ -290,6 +411,7 @@ func (p *Processor) DeleteIndividualTrafficInfluenceSubscription(
 		}
 	}
 	delete(af.Subs, subID)
+	p.Context().StoreAf(af)
 	c.JSON(http.StatusNoContent, nil)
 }
 
@@ -307,22 +429,78 @@ func validateTrafficInfluenceData(
 	}
 
 	// TS29.522: One of individual UE identifier
-	// (i.e. "gpsi", “macAddr”, "ipv4Addr" or "ipv6Addr"),
+	// (i.e. "gpsi", "macAddr", "ipv4Addr" or "ipv6Addr"),
 	// External Group Identifier (i.e. "externalGroupId") or
 	// any UE indication "anyUeInd" shall be included.
 	if tiSub.Gpsi == "" &&
+		tiSub.MacAddr == "" &&
 		tiSub.Ipv4Addr == "" &&
 		tiSub.Ipv6Addr == "" &&
 		tiSub.ExternalGroupId == "" &&
 		!tiSub.AnyUeInd {
 		pd := openapi.
 			ProblemDetailsMalformedReqSyntax(
-				"Missing one of Gpsi, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd")
+				"Missing one of Gpsi, MacAddr, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd")
 		return &HandlerResponse{int(pd.Status), nil, pd}
 	}
+
+	// TS29.522: "macAddr" is applicable for the Ethernet PDU session,
+	// whose traffic is described by "ethTrafficFilters" rather than "trafficFilters".
+	if tiSub.MacAddr != "" {
+		if tiSub.Ipv4Addr != "" || tiSub.Ipv6Addr != "" {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("macAddr can't be combined with ipv4Addr or ipv6Addr")
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+		if len(tiSub.TrafficFilters) > 0 {
+			pd := openapi.ProblemDetailsMalformedReqSyntax("trafficFilters is not applicable for macAddr")
+			return &HandlerResponse{int(pd.Status), nil, pd}
+		}
+	}
 	return nil
 }
 
+// selectPcfForTrafficInfluSub finds the PCF serving the PDU session of the individual UE,
+// which is kept in the subscription for the following operations of the app session.
+func (p *Processor) selectPcfForTrafficInfluSub(
+	tiSub *models_nef.TrafficInfluSub,
+) (string, string, *HandlerResponse) {
+	pcfUri, pcfID, err := p.Consumer().SelectPcfPolicyAuthUri(
+		tiSub.Ipv4Addr, tiSub.Ipv6Addr, tiSub.MacAddr, tiSub.Dnn, tiSub.Snssai)
+	if err != nil {
+		logger.TrafInfluLog.Errorf("Select PCF failed: %+v", err)
+		pd := openapi.ProblemDetailsSystemFailure("PCF is not found")
+		return "", "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return pcfUri, pcfID, nil
+}
+
+// translateExternalGroupId gets the internal group ID of the group of UEs from the UDM,
+// since the influence data of the group is stored per internal group ID in the UDR.
+func (p *Processor) translateExternalGroupId(extGroupID string) (string, *HandlerResponse) {
+	if extGroupID == "" {
+		return "", nil
+	}
+
+	rspStatus, rspBody := p.Consumer().GetGroupIdentifiers(extGroupID)
+	switch rspStatus {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		pd := openapi.ProblemDetailsDataNotFound("External group ID is not found")
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	default:
+		logger.TrafInfluLog.Errorf("Translate external group ID[%s] failed: rspStatus[%d], rspBody[%+v]",
+			extGroupID, rspStatus, rspBody)
+		pd := openapi.ProblemDetailsSystemFailure("Translate external group ID failed")
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	groupIDs, ok := rspBody.(*nef_models.GroupIdentifiers)
+	if !ok || groupIDs.IntGroupId == "" {
+		pd := openapi.ProblemDetailsSystemFailure("Internal group ID is not found")
+		return "", &HandlerResponse{int(pd.Status), nil, pd}
+	}
+	return groupIDs.IntGroupId, nil
+}
+
 func (p *Processor) genTrafficInfluSubURI(
 	afID, subscriptionId string,
 ) string {
@@ -334,9 +512,54 @@ func (p *Processor) genNotificationUri() string {
 	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/smf"
 }
 
+func (p *Processor) genInfluDataNotificationUri(notifCorreID string) string {
+	return p.Config().ServiceUri(factory.ServiceNefCallback) + "/notification/udr-influence-data/" + notifCorreID
+}
+
+// subscribeInfluenceDataChange subscribes to the changes of the influence data in UDR,
+// so that the AF is informed if the data is modified or removed by another NF or the operator.
+// The traffic influence still works without the subscription, so the failure is only logged.
+func (p *Processor) subscribeInfluenceDataChange(
+	afSub *nef_context.AfSubscription,
+	tiData *models.TrafficInfluData,
+) {
+	influSub := &models.TrafficInfluSub{
+		NotificationUri: p.genInfluDataNotificationUri(afSub.NotifCorreID),
+	}
+	if tiData.Dnn != "" {
+		influSub.Dnns = []string{tiData.Dnn}
+	}
+	if tiData.Snssai != nil {
+		influSub.Snssais = []models.Snssai{*tiData.Snssai}
+	}
+	if tiData.InterGroupId != "" {
+		influSub.InternalGroupIds = []string{tiData.InterGroupId}
+	}
+
+	rspStatus, rspBody, influSubsID := p.Consumer().AppDataInfluenceDataSubsToNotifyPost(influSub)
+	if rspStatus != http.StatusCreated {
+		afSub.Log.Warnf("Subscribe to influence data changes failed: %d, %+v", rspStatus, rspBody)
+		return
+	}
+	afSub.InfluSubsID = influSubsID
+}
+
+func (p *Processor) unsubscribeInfluenceDataChange(afSub *nef_context.AfSubscription) {
+	if afSub.InfluSubsID == "" {
+		return
+	}
+	rspStatus, rspBody := p.Consumer().AppDataInfluenceDataSubsToNotifyDelete(afSub.InfluSubsID)
+	if rspStatus != http.StatusOK &&
+		rspStatus != http.StatusNoContent {
+		afSub.Log.Warnf("Unsubscribe from influence data changes failed: %d, %+v", rspStatus, rspBody)
+	}
+	afSub.InfluSubsID = ""
+}
+
 func (p *Processor) convertTrafficInfluSubToAppSessionContext(
 	tiSub *models_nef.TrafficInfluSub,
 	notifCorreID string,
+	supi string,
 ) *models.AppSessionContext {
 	asc := &models.AppSessionContext{
 		AscReqData: &models.AppSessionContextReqData{
@@ -353,7 +576,7 @@ func (p *Processor) convertTrafficInfluSubToAppSessionContext(
 			SuppFeat:  tiSub.SuppFeat,
 			Dnn:       tiSub.Dnn,
 			SliceInfo: tiSub.Snssai,
-			// Supi: ,
+			Supi:      supi,
 		},
 	}
 
@@ -367,6 +590,29 @@ func (p *Processor) convertTrafficInfluSubToAppSessionContext(
 	return asc
 }
 
+func (p *Processor) convertTrafficInfluSubToAppSessionContextUpdateData(
+	tiSub *models_nef.TrafficInfluSub,
+	notifCorreID string,
+) *models.AppSessionContextUpdateData {
+	ascUpdate := &models.AppSessionContextUpdateData{
+		AfAppId: tiSub.AfAppId,
+		AfRoutReq: &models.AfRoutingRequirementRm{
+			AppReloc:    tiSub.AppReloInd,
+			RouteToLocs: tiSub.TrafficRoutes,
+			TempVals:    tiSub.TempValidities,
+		},
+	}
+
+	if tiSub.DnaiChgType != "" {
+		ascUpdate.AfRoutReq.UpPathChgSub = &models.UpPathChgEvent{
+			DnaiChgType:     tiSub.DnaiChgType,
+			NotificationUri: p.genNotificationUri(),
+			NotifCorreId:    notifCorreID,
+		}
+	}
+	return ascUpdate
+}
+
 func (p *Processor) convertTrafficInfluSubPatchToAppSessionContextUpdateData(
 	tiSubPatch *models_nef.TrafficInfluSubPatch,
 ) *models.AppSessionContextUpdateData {
@@ -383,11 +629,13 @@ func (p *Processor) convertTrafficInfluSubPatchToAppSessionContextUpdateData(
 func (p *Processor) convertTrafficInfluSubToTrafficInfluData(
 	tiSub *models_nef.TrafficInfluSub,
 	notifCorreID string,
+	supi string,
+	interGroupID string,
 ) *models.TrafficInfluData {
 	tiData := &models.TrafficInfluData{
-		AfAppId:    tiSub.AfAppId,
-		AppReloInd: tiSub.AppReloInd,
-		// Supi: ,
+		AfAppId:               tiSub.AfAppId,
+		AppReloInd:            tiSub.AppReloInd,
+		Supi:                  supi,
 		DnaiChgType:           tiSub.DnaiChgType,
 		UpPathChgNotifUri:     p.genNotificationUri(),
 		UpPathChgNotifCorreId: notifCorreID,
@@ -405,8 +653,9 @@ func (p *Processor) convertTrafficInfluSubToTrafficInfluData(
 		SupportedFeatures: tiSub.SuppFeat,
 	}
 
-	// TODO: handle ExternalGroupId
-	if tiSub.AnyUeInd {
+	if tiSub.ExternalGroupId != "" {
+		tiData.InterGroupId = interGroupID
+	} else if tiSub.AnyUeInd {
 		tiData.InterGroupId = "AnyUE"
 	}
 
@@ -4,8 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	nef_models "github.com/free5gc/nef/internal/models"
+	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/openapi/models_nef"
 	"github.com/gin-gonic/gin"
@@ -131,6 +137,28 @@ var (
 		},
 	}
 
+	tiSub6ForAf1 = models_nef.TrafficInfluSub{
+		AfServiceId: "Service6",
+		AfAppId:     "App6",
+		Dnn:         "ethernet",
+		Snssai: &models.Snssai{
+			Sst: 1,
+			Sd:  "010203",
+		},
+		MacAddr: "00-00-5e-00-53-01",
+		EthTrafficFilters: []models.EthFlowDescription{
+			{
+				DestMacAddr: "00-00-5e-00-53-ff",
+				EthType:     "0800",
+			},
+		},
+		TrafficRoutes: []models.RouteToLocation{
+			{
+				Dnai: "mec",
+			},
+		},
+	}
+
 	tiSubPatch1ForAf1 = models_nef.TrafficInfluSubPatch{
 		TrafficFilters: []models.FlowInfo{
 			{
@@ -283,7 +311,15 @@ func TestGetGetIndividualTrafficInfluenceSubscription(t *testing.T) {
 func TestPostTrafficInfluenceSubscription(t *testing.T) {
 	initNRFDiscPCFStub()
 	initUDRDrPutTiDataStub(http.StatusNoContent)
+	initUDRDrPostInfluSubsStub()
 	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_SDM, "127.0.0.3")
+	initUDMSdmGroupIdentifiersStub("group1@free5gc.org", "20893000-0001")
+	initUDMSdmGroupIdentifiersStub("group2@free5gc.org", "")
+	gock.New("http://127.0.0.3:8000/nudm-sdm/v1").
+		Get("/group-data/group-identifiers").
+		MatchParam("ext-group-id", regexp.QuoteMeta("group3@free5gc.org")).
+		Reply(http.StatusInternalServerError)
 	defer gock.Off()
 
 	rspTiSub1 := tiSub1ForAf1
@@ -292,6 +328,24 @@ func TestPostTrafficInfluenceSubscription(t *testing.T) {
 	rspTiSub2 := tiSub3ForAf1
 	rspTiSub2.Self = nefApp.Processor().genTrafficInfluSubURI("af1", "2")
 
+	tiSubGroup := tiSub1ForAf1
+	tiSubGroup.AnyUeInd = false
+	tiSubGroup.ExternalGroupId = "group1@free5gc.org"
+	rspTiSubGroup := tiSubGroup
+	rspTiSubGroup.Self = nefApp.Processor().genTrafficInfluSubURI("af1", "3")
+
+	tiSubUnknownGroup := tiSubGroup
+	tiSubUnknownGroup.ExternalGroupId = "group2@free5gc.org"
+
+	tiSubGroupUdmFailure := tiSubGroup
+	tiSubGroupUdmFailure.ExternalGroupId = "group3@free5gc.org"
+
+	rspTiSubMac := tiSub6ForAf1
+	rspTiSubMac.Self = nefApp.Processor().genTrafficInfluSubURI("af1", "5")
+
+	tiSubMacWithIPFilters := tiSub6ForAf1
+	tiSubMacWithIPFilters.TrafficFilters = tiSub3ForAf1.TrafficFilters
+
 	testCases := []struct {
 		description      string
 		afID             string
@@ -344,7 +398,71 @@ func TestPostTrafficInfluenceSubscription(t *testing.T) {
 				Body: &models.ProblemDetails{
 					Status: http.StatusBadRequest,
 					Title:  "Malformed request syntax",
-					Detail: "Missing one of Gpsi, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd",
+					Detail: "Missing one of Gpsi, MacAddr, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd",
+				},
+			},
+		},
+		{
+			description: "TC5: Successful group subscription, should put tiData with internal group ID to UDR",
+			afID:        "af1",
+			tiSub:       &tiSubGroup,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspTiSubGroup.Self},
+				},
+				Body: &rspTiSubGroup,
+			},
+		},
+		{
+			description: "TC6: Unknown externalGroupId",
+			afID:        "af1",
+			tiSub:       &tiSubUnknownGroup,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Title:  "Data not found",
+					Status: http.StatusNotFound,
+					Detail: "External group ID is not found",
+				},
+			},
+		},
+		{
+			description: "TC7: Successful MAC address subscription, should post AppSession to PCF",
+			afID:        "af1",
+			tiSub:       &tiSub6ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+				Headers: map[string][]string{
+					"Location": {rspTiSubMac.Self},
+				},
+				Body: &rspTiSubMac,
+			},
+		},
+		{
+			description: "TC8: trafficFilters with MAC address",
+			afID:        "af1",
+			tiSub:       &tiSubMacWithIPFilters,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "trafficFilters is not applicable for macAddr",
+				},
+			},
+		},
+		{
+			description: "TC9: Failure of the UDM to translate the externalGroupId",
+			afID:        "af1",
+			tiSub:       &tiSubGroupUdmFailure,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusInternalServerError,
+				Body: &models.ProblemDetails{
+					Title:  "System failure",
+					Status: http.StatusInternalServerError,
+					Detail: "Translate external group ID failed",
+					Cause:  "SYSTEM_FAILURE",
 				},
 			},
 		},
@@ -375,10 +493,252 @@ func TestPostTrafficInfluenceSubscription(t *testing.T) {
 			assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
 		})
 	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "subs1", af.Subs["1"].InfluSubsID)
+	require.Empty(t, af.Subs["2"].InfluSubsID)
+	require.Equal(t, "20893000-0001", af.Subs["3"].InterGroupID)
+	require.Equal(t, "12345", af.Subs["5"].AppSessID)
+	require.Len(t, af.Subs, 4)
+	af.Mu.RUnlock()
+
 	nefCtx.DeleteAf("af1")
 	nefCtx.ResetCorreID()
 }
 
+func TestPostTrafficInfluenceSubscriptionWithGpsi(t *testing.T) {
+	initNRFDiscPCFStub()
+	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initNRFDiscStub(models.NfType_UDM, models.ServiceName_NUDM_SDM, "127.0.0.3")
+	udmStub := initUDMSdmIdTranslationStub("msisdn-0900000010", "imsi-208930000000010")
+	initUDMSdmIdTranslationStub("msisdn-0900000011", "")
+	gock.New("http://127.0.0.3:8000/nudm-sdm/v1").
+		Get("/msisdn-0900000012/id-translation-result").
+		Persist().
+		Reply(http.StatusServiceUnavailable).
+		JSON(models.ProblemDetails{
+			Status: http.StatusServiceUnavailable,
+			Cause:  "NF_CONGESTION",
+		})
+	defer gock.Off()
+	var ascReqs []models.AppSessionContext
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/app-sessions") {
+			var asc models.AppSessionContext
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&asc))
+			ascReqs = append(ascReqs, asc)
+		}
+	})
+	defer gock.Observe(nil)
+
+	tiSubGpsi := tiSub3ForAf1
+	tiSubGpsi.Gpsi = "msisdn-0900000010"
+
+	tiSubUnknownGpsi := tiSub3ForAf1
+	tiSubUnknownGpsi.Gpsi = "msisdn-0900000011"
+
+	tiSubUdmFailure := tiSub3ForAf1
+	tiSubUdmFailure.Gpsi = "msisdn-0900000012"
+
+	// No response from the UDM for the GPSI
+	tiSubNoUdmRsp := tiSub3ForAf1
+	tiSubNoUdmRsp.Gpsi = "msisdn-0900000013"
+
+	testCases := []struct {
+		description      string
+		tiSub            models_nef.TrafficInfluSub
+		removeUdmStub    bool
+		expectedResponse *HandlerResponse
+	}{
+		{
+			description: "TC1: Successful subscription, should post AppSession with SUPI to PCF",
+			tiSub:       tiSubGpsi,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+			},
+		},
+		{
+			description:   "TC2: Successful subscription, should get SUPI from cache",
+			tiSub:         tiSubGpsi,
+			removeUdmStub: true,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusCreated,
+			},
+		},
+		{
+			description: "TC3: Unknown GPSI",
+			tiSub:       tiSubUnknownGpsi,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusNotFound,
+				Body: &models.ProblemDetails{
+					Title:  "Data not found",
+					Status: http.StatusNotFound,
+					Detail: "GPSI is not found",
+					Cause:  "USER_NOT_FOUND",
+				},
+			},
+		},
+		{
+			description: "TC4: UDM failure, should not relay the error of UDM",
+			tiSub:       tiSubUdmFailure,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusInternalServerError,
+				Body:   openapi.ProblemDetailsSystemFailure("Translate GPSI to SUPI failed"),
+			},
+		},
+		{
+			description: "TC5: No response from UDM",
+			tiSub:       tiSubNoUdmRsp,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusInternalServerError,
+				Body:   openapi.ProblemDetailsSystemFailure("Translate GPSI to SUPI failed"),
+			},
+		},
+	}
+
+	nefCtx := nefApp.Context()
+	nefCtx.ResetSupiCache()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+		nefCtx.ResetSupiCache()
+	}()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if tc.removeUdmStub {
+				gock.Remove(udmStub)
+			}
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			tiSub := tc.tiSub
+			nefApp.Processor().PostTrafficInfluenceSubscription(c, "af1", &tiSub)
+			require.Equal(t, tc.expectedResponse.Status, httpRecorder.Code)
+			if tc.expectedResponse.Body != nil {
+				assertJSONBodyEqual(t, tc.expectedResponse.Body, httpRecorder.Body.Bytes())
+			}
+		})
+	}
+
+	require.Len(t, ascReqs, 2)
+	for i := range ascReqs {
+		require.Equal(t, "imsi-208930000000010", ascReqs[i].AscReqData.Supi)
+	}
+
+	af := nefCtx.GetAf("af1")
+	require.NotNil(t, af)
+	af.Mu.RLock()
+	require.Equal(t, "imsi-208930000000010", af.Subs["1"].Supi)
+	af.Mu.RUnlock()
+}
+
+func TestPostTrafficInfluenceSubscriptionWithBsf(t *testing.T) {
+	initNRFDiscPCFStub()
+	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initNRFDiscStub(models.NfType_BSF, models.ServiceName_NBSF_MANAGEMENT, "127.0.0.8")
+	initBSFMgmtGetPcfBindingsStub()
+	defer gock.Off()
+
+	gock.New("http://127.0.0.9:8000/npcf-policyauthorization/v1").
+		Post("/app-sessions").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.9:8000/npcf-policyauthorization/v1/app-sessions/23456").
+		JSON(models.AppSessionContext{})
+	gock.New("http://127.0.0.9:8000/npcf-policyauthorization/v1").
+		Patch("/app-sessions/23456").
+		Persist().
+		Reply(http.StatusNoContent)
+
+	nefCtx := nefApp.Context()
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+		nefCtx.SetBsfMgmtUri("")
+	}()
+
+	testCases := []struct {
+		description    string
+		tiSub          models_nef.TrafficInfluSub
+		expectedPcfUri string
+	}{
+		{
+			description:    "TC1: PCF binding is found in BSF, should post AppSession to the bound PCF",
+			tiSub:          tiSub3ForAf1,
+			expectedPcfUri: "http://127.0.0.9:8000",
+		},
+		{
+			description:    "TC2: PCF binding is not found in BSF, should post AppSession to the PCF from NRF",
+			tiSub:          tiSub6ForAf1,
+			expectedPcfUri: "http://127.0.0.7:8000",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			tiSub := tc.tiSub
+			nefApp.Processor().PostTrafficInfluenceSubscription(c, "af1", &tiSub)
+			require.Equal(t, http.StatusCreated, httpRecorder.Code)
+
+			af := nefCtx.GetAf("af1")
+			require.NotNil(t, af)
+			af.Mu.RLock()
+			require.Equal(t, tc.expectedPcfUri, af.Subs[strconv.Itoa(i+1)].PcfUri)
+			af.Mu.RUnlock()
+		})
+	}
+
+	// The following operations of the app session are sent to the bound PCF
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	tiSubPatch := tiSubPatch1ForAf1
+	nefApp.Processor().PatchIndividualTrafficInfluenceSubscription(c, "af1", "1", &tiSubPatch)
+	require.Equal(t, http.StatusOK, httpRecorder.Code)
+}
+
+func TestFindAfSubWhileAddingSub(t *testing.T) {
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	nefCtx.AddAf(af1)
+	defer func() {
+		nefCtx.DeleteAf("af1")
+		nefCtx.ResetCorreID()
+	}()
+
+	// A notification looks up the subscription while a handler holding the AF lock
+	// allocates a correlation ID and adds the subscription
+	af1.Mu.Lock()
+	found := make(chan struct{})
+	go func() {
+		nefCtx.FindAfSub("1")
+		close(found)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	added := make(chan struct{})
+	go func() {
+		tiSub := tiSub3ForAf1
+		afSub := af1.NewSub(nefCtx.NewCorreID(), &tiSub)
+		af1.Subs[afSub.SubID] = afSub
+		nefCtx.AddAf(af1)
+		af1.Mu.Unlock()
+		close(added)
+	}()
+
+	for _, ch := range []chan struct{}{added, found} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			require.Fail(t, "deadlock between the AF lock and the context lock")
+		}
+	}
+}
+
 func TestDeleteIndividualTrafficInfluenceSubscription(t *testing.T) {
 	initNRFDiscPCFStub()
 	initUDRDrDeleteTiDataStub(http.StatusNoContent)
@@ -428,6 +788,7 @@ func TestDeleteIndividualTrafficInfluenceSubscription(t *testing.T) {
 	correID1 := nefCtx.NewCorreID()
 	afSub1 := af1.NewSub(correID1, &tiSub1ForAf1)
 	afSub1.InfluID = uuid.New().String()
+	afSub1.InfluSubsID = "subs1"
 	af1.Subs[afSub1.SubID] = afSub1
 
 	correID2 := nefCtx.NewCorreID()
@@ -466,6 +827,19 @@ func TestPatchIndividualTrafficInfluenceSubscription(t *testing.T) {
 	rspTiSub2.TrafficFilters = tiSubPatch1ForAf1.TrafficFilters
 	rspTiSub2.TrafficRoutes = tiSubPatch1ForAf1.TrafficRoutes
 
+	tiSubPatchMac := models_nef.TrafficInfluSubPatch{
+		EthTrafficFilters: []models.EthFlowDescription{
+			{
+				DestMacAddr: "00-00-5e-00-53-fe",
+				EthType:     "0800",
+			},
+		},
+		TrafficRoutes: tiSubPatch1ForAf1.TrafficRoutes,
+	}
+	rspTiSubMac := tiSub6ForAf1
+	rspTiSubMac.EthTrafficFilters = tiSubPatchMac.EthTrafficFilters
+	rspTiSubMac.TrafficRoutes = tiSubPatchMac.TrafficRoutes
+
 	testCases := []struct {
 		description      string
 		afID             string
@@ -496,7 +870,7 @@ func TestPatchIndividualTrafficInfluenceSubscription(t *testing.T) {
 		{
 			description: "TC3: Patch non-existed TI subscription",
 			afID:        "af1",
-			subID:       "3",
+			subID:       "4",
 			tiSubPatch:  &tiSubPatch1ForAf1,
 			expectedResponse: &HandlerResponse{
 				Status: http.StatusNotFound,
@@ -507,6 +881,30 @@ func TestPatchIndividualTrafficInfluenceSubscription(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "TC4: trafficFilters to MAC address TI subscription",
+			afID:        "af1",
+			subID:       "3",
+			tiSubPatch:  &tiSubPatch1ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusBadRequest,
+				Body: &models.ProblemDetails{
+					Status: http.StatusBadRequest,
+					Title:  "Malformed request syntax",
+					Detail: "trafficFilters is not applicable for macAddr",
+				},
+			},
+		},
+		{
+			description: "TC5: Successful patch MAC address TI subscription to PCF",
+			afID:        "af1",
+			subID:       "3",
+			tiSubPatch:  &tiSubPatchMac,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &rspTiSubMac,
+			},
+		},
 	}
 
 	nefCtx := nefApp.Context()
@@ -521,6 +919,11 @@ func TestPatchIndividualTrafficInfluenceSubscription(t *testing.T) {
 	afSub2 := af1.NewSub(correID2, &tiSub3ForAf1)
 	af1.Subs[afSub2.SubID] = afSub2
 	afSub2.AppSessID = "12345"
+
+	tiSub3 := tiSub6ForAf1
+	afSub3 := af1.NewSub(nefCtx.NewCorreID(), &tiSub3)
+	afSub3.AppSessID = "12345"
+	af1.Subs[afSub3.SubID] = afSub3
 	nefCtx.AddAf(af1)
 	af1.Mu.Unlock()
 
@@ -544,6 +947,7 @@ func TestPutIndividualTrafficInfluenceSubscription(t *testing.T) {
 	initNRFDiscPCFStub()
 	initUDRDrPutTiDataStub(http.StatusNoContent)
 	initPCFPaPostAppSessionsStub(http.StatusCreated)
+	initPCFPaDeleteAppSessionsStub(http.StatusNoContent)
 	defer gock.Off()
 
 	testCases := []struct {
@@ -611,10 +1015,20 @@ func TestPutIndividualTrafficInfluenceSubscription(t *testing.T) {
 				Body: &models.ProblemDetails{
 					Status: http.StatusBadRequest,
 					Title:  "Malformed request syntax",
-					Detail: "Missing one of Gpsi, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd",
+					Detail: "Missing one of Gpsi, MacAddr, Ipv4Addr, Ipv6Addr, ExternalGroupId, AnyUeInd",
 				},
 			},
 		},
+		{
+			description: "TC6: Successful put MAC address TI subscription to PCF",
+			afID:        "af1",
+			subID:       "2",
+			tiSub:       &tiSub6ForAf1,
+			expectedResponse: &HandlerResponse{
+				Status: http.StatusOK,
+				Body:   &tiSub6ForAf1,
+			},
+		},
 	}
 
 	nefCtx := nefApp.Context()
@@ -629,6 +1043,7 @@ func TestPutIndividualTrafficInfluenceSubscription(t *testing.T) {
 	afSub2 := af1.NewSub(correID2, &tiSub3ForAf1)
 	af1.Subs[afSub2.SubID] = afSub2
 	afSub2.AppSessID = "12345"
+	afSub2.PcfUri = "http://127.0.0.7:8000"
 	nefCtx.AddAf(af1)
 	af1.Mu.Unlock()
 
@@ -648,6 +1063,91 @@ func TestPutIndividualTrafficInfluenceSubscription(t *testing.T) {
 	nefCtx.ResetCorreID()
 }
 
+func TestPutIndividualTrafficInfluenceSubscriptionWithBsf(t *testing.T) {
+	initNRFDiscStub(models.NfType_BSF, models.ServiceName_NBSF_MANAGEMENT, "127.0.0.8")
+	initBSFMgmtGetPcfBindingsStub()
+	initPCFPaDeleteAppSessionsStub(http.StatusNoContent)
+	defer gock.Off()
+
+	gock.New("http://127.0.0.9:8000/npcf-policyauthorization/v1").
+		Post("/app-sessions").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location", "http://127.0.0.9:8000/npcf-policyauthorization/v1/app-sessions/23456").
+		JSON(models.AppSessionContext{})
+	gock.New("http://127.0.0.9:8000/npcf-policyauthorization/v1").
+		Patch("/app-sessions/23456").
+		Persist().
+		Reply(http.StatusNoContent)
+	var pcfReqs []string
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if strings.Contains(request.URL.Path, "/npcf-policyauthorization/") {
+			pcfReqs = append(pcfReqs, request.Method+" "+request.URL.Host+request.URL.Path)
+		}
+	})
+	defer gock.Observe(nil)
+
+	nefCtx := nefApp.Context()
+	af1 := nefCtx.NewAf("af1")
+	af1.Mu.Lock()
+	tiSub := tiSub3ForAf1
+	afSub := af1.NewSub(nefCtx.NewCorreID(), &tiSub)
+	afSub.AppSessID = "12345"
+	afSub.PcfUri = "http://127.0.0.7:8000"
+	af1.Subs[afSub.SubID] = afSub
+	nefCtx.AddAf(af1)
+	af1.Mu.Unlock()
+	defer func() {
+		nefCtx.DeleteAf(af1.AfID)
+		nefCtx.ResetCorreID()
+		nefCtx.SetBsfMgmtUri("")
+	}()
+
+	testCases := []struct {
+		description       string
+		expectedPcfReqs   []string
+		expectedPcfUri    string
+		expectedAppSessID string
+	}{
+		{
+			description: "TC1: PCF is changed, should create the app session at the new PCF and delete the previous one",
+			expectedPcfReqs: []string{
+				"POST 127.0.0.9:8000/npcf-policyauthorization/v1/app-sessions",
+				"POST 127.0.0.7:8000/npcf-policyauthorization/v1/app-sessions/12345/delete",
+			},
+			expectedPcfUri:    "http://127.0.0.9:8000",
+			expectedAppSessID: "23456",
+		},
+		{
+			description: "TC2: PCF is unchanged, should update the existing app session",
+			expectedPcfReqs: []string{
+				"PATCH 127.0.0.9:8000/npcf-policyauthorization/v1/app-sessions/23456",
+			},
+			expectedPcfUri:    "http://127.0.0.9:8000",
+			expectedAppSessID: "23456",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			pcfReqs = nil
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			putTiSub := tiSub3ForAf1
+			putTiSub.TrafficRoutes = tiSubPatch1ForAf1.TrafficRoutes
+			nefApp.Processor().PutIndividualTrafficInfluenceSubscription(c, "af1", afSub.SubID, &putTiSub)
+			require.Equal(t, http.StatusOK, httpRecorder.Code)
+			require.Equal(t, tc.expectedPcfReqs, pcfReqs)
+
+			af1.Mu.RLock()
+			require.Equal(t, tc.expectedPcfUri, afSub.PcfUri)
+			require.Equal(t, tc.expectedAppSessID, afSub.AppSessID)
+			af1.Mu.RUnlock()
+		})
+	}
+}
+
 func initUDRDrPutTiDataStub(statusCode int) {
 	gock.New("http://127.0.0.4:8000/nudr-dr/v1").
 		Put("/application-data/influenceData/.*").
@@ -655,6 +1155,59 @@ func initUDRDrPutTiDataStub(statusCode int) {
 		Reply(statusCode)
 }
 
+func initUDRDrPostInfluSubsStub() {
+	gock.New("http://127.0.0.4:8000/nudr-dr/v1").
+		Post("/application-data/influenceData/subs-to-notify").
+		Persist().
+		Reply(http.StatusCreated).
+		SetHeader("Location",
+			"http://127.0.0.4:8000/nudr-dr/v1/application-data/influenceData/subs-to-notify/subs1").
+		JSON(models.TrafficInfluSub{})
+}
+
+func initUDMSdmGroupIdentifiersStub(extGroupID, intGroupID string) {
+	rsp := gock.New("http://127.0.0.3:8000/nudm-sdm/v1").
+		Get("/group-data/group-identifiers").
+		MatchParam("ext-group-id", regexp.QuoteMeta(extGroupID)).
+		Persist()
+	if intGroupID == "" {
+		rsp.Reply(http.StatusNotFound).
+			JSON(models.ProblemDetails{
+				Status: http.StatusNotFound,
+				Cause:  "GROUP_IDENTIFIER_NOT_FOUND",
+			})
+	} else {
+		rsp.Reply(http.StatusOK).
+			JSON(nef_models.GroupIdentifiers{
+				ExtGroupId: extGroupID,
+				IntGroupId: intGroupID,
+			})
+	}
+}
+
+func initBSFMgmtGetPcfBindingsStub() {
+	gock.New("http://127.0.0.8:8000/nbsf-management/v1").
+		Get("/pcfBindings").
+		MatchParam("ipv4Addr", tiSub3ForAf1.Ipv4Addr).
+		Persist().
+		Reply(http.StatusOK).
+		JSON(models.PcfBinding{
+			Ipv4Addr: tiSub3ForAf1.Ipv4Addr,
+			Dnn:      tiSub3ForAf1.Dnn,
+			Snssai:   tiSub3ForAf1.Snssai,
+			PcfIpEndPoints: []models.IpEndPoint{
+				{
+					Ipv4Address: "127.0.0.9",
+					Port:        8000,
+				},
+			},
+		})
+	gock.New("http://127.0.0.8:8000/nbsf-management/v1").
+		Get("/pcfBindings").
+		Persist().
+		Reply(http.StatusNoContent)
+}
+
 func initUDRDrPatchTiDataStub(statusCode int) {
 	gock.New("http://127.0.0.4:8000/nudr-dr/v1").
 		Patch("/application-data/influenceData/.*").
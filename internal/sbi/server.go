@@ -46,6 +46,46 @@ func NewServer(nef nef, tlsKeyLogPath string) (*Server, error) {
 	group := s.router.Group(factory.TraffInfluResUriPrefix)
 	applyRoutes(group, endpoints)
 
+	endpoints = s.getAsSessionWithQosRoutes()
+	group = s.router.Group(factory.AsSessQosResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getMonitoringEventRoutes()
+	group = s.router.Group(factory.MonEvtResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getDeviceTriggeringRoutes()
+	group = s.router.Group(factory.DevTrigResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getChargeablePartyRoutes()
+	group = s.router.Group(factory.ChgPartyResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getBdtRoutes()
+	group = s.router.Group(factory.BdtResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getParameterProvisionRoutes()
+	group = s.router.Group(factory.PpResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.get5GLanParameterProvisionRoutes()
+	group = s.router.Group(factory.FiveGLanPpResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getNiddRoutes()
+	group = s.router.Group(factory.NiddResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getAnalyticsExposureRoutes()
+	group = s.router.Group(factory.AnaExpoResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getServiceParameterRoutes()
+	group = s.router.Group(factory.SvcParamResUriPrefix)
+	applyRoutes(group, endpoints)
+
 	endpoints = s.getPFDManagementRoutes()
 	group = s.router.Group(factory.PfdMngResUriPrefix)
 	applyRoutes(group, endpoints)
@@ -54,6 +94,14 @@ func NewServer(nef nef, tlsKeyLogPath string) (*Server, error) {
 	group = s.router.Group(factory.NefPfdMngResUriPrefix)
 	applyRoutes(group, endpoints)
 
+	endpoints = s.getSmContextRoutes()
+	group = s.router.Group(factory.NefSmCtxResUriPrefix)
+	applyRoutes(group, endpoints)
+
+	endpoints = s.getNefEventExposureRoutes()
+	group = s.router.Group(factory.NefEeResUriPrefix)
+	applyRoutes(group, endpoints)
+
 	endpoints = s.getOamRoutes()
 	group = s.router.Group(factory.NefOamResUriPrefix)
 	applyRoutes(group, endpoints)
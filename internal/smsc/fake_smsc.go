@@ -0,0 +1,78 @@
+package smsc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/free5gc/nef/internal/logger"
+)
+
+const fakeDefaultDeliveryDelay = 100 * time.Millisecond
+
+// FakeSmsc stands in for the SMS-SC, every submitted message is reported
+// with the same result after the delivery delay
+type FakeSmsc struct {
+	mu      sync.Mutex
+	result  DeliveryResult
+	delay   time.Duration
+	pending map[string]*time.Timer
+}
+
+func NewFakeSmsc() *FakeSmsc {
+	return &FakeSmsc{
+		result:  DeliveryResultSuccess,
+		delay:   fakeDefaultDeliveryDelay,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+// SetDelivery sets the result and the delay of the messages submitted afterwards
+func (s *FakeSmsc) SetDelivery(result DeliveryResult, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+	s.delay = delay
+}
+
+func (s *FakeSmsc) Submit(msg *TriggerMessage, handler ReportHandler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := s.result
+	// The message is not delivered within the validity period
+	if msg.ValidityPeriod > 0 && s.delay > msg.ValidityPeriod {
+		result = DeliveryResultExpired
+	}
+
+	logger.SmscLog.Infof("Submit trigger message[%s] to UE[%s], %d bytes", msg.ID, msg.Supi, len(msg.Payload))
+	s.pending[msg.ID] = time.AfterFunc(s.delay, func() {
+		s.mu.Lock()
+		if _, ok := s.pending[msg.ID]; !ok {
+			s.mu.Unlock()
+			return
+		}
+		delete(s.pending, msg.ID)
+		s.mu.Unlock()
+
+		logger.SmscLog.Infof("Trigger message[%s]: %s", msg.ID, result)
+		handler(&DeliveryReport{
+			ID:     msg.ID,
+			Result: result,
+		})
+	})
+	return nil
+}
+
+func (s *FakeSmsc) Recall(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timer, ok := s.pending[id]
+	if !ok {
+		return ErrMessageNotFound
+	}
+	timer.Stop()
+	delete(s.pending, id)
+	logger.SmscLog.Infof("Trigger message[%s] is recalled", id)
+	return nil
+}
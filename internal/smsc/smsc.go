@@ -0,0 +1,61 @@
+package smsc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeFake string = "fake"
+)
+
+type DeliveryResult string
+
+// List of DeliveryResult reported by the SMS-SC
+const (
+	DeliveryResultSuccess DeliveryResult = "SUCCESS"
+	DeliveryResultFailure DeliveryResult = "FAILURE"
+	DeliveryResultExpired DeliveryResult = "EXPIRED"
+	DeliveryResultUnknown DeliveryResult = "UNKNOWN"
+)
+
+var ErrMessageNotFound = errors.New("message is not found")
+
+// TriggerMessage is the device trigger delivered to the UE by MT SMS (TS 23.682 clause 5.2.2)
+type TriggerMessage struct {
+	// Reference of the message in the delivery report
+	ID             string
+	Supi           string
+	Gpsi           string
+	ValidityPeriod time.Duration
+	Priority       bool
+	AppPortID      int32
+	AppSrcPortID   int32
+	Payload        []byte
+}
+
+type DeliveryReport struct {
+	ID     string
+	Result DeliveryResult
+}
+
+type ReportHandler func(report *DeliveryReport)
+
+// Smsc is the interface towards the SMS-SC that delivers the device triggers
+// (T4 or SGd/Gdd in TS 23.682).
+type Smsc interface {
+	// Submit accepts the message for delivery, the outcome is reported to the handler
+	Submit(msg *TriggerMessage, handler ReportHandler) error
+	// Recall withdraws the message which is not delivered yet
+	Recall(id string) error
+}
+
+func NewSmsc(smscType string) (Smsc, error) {
+	switch smscType {
+	case TypeFake:
+		return NewFakeSmsc(), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS-SC type: %s", smscType)
+	}
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/free5gc/nef/internal/logger"
+)
+
+const (
+	opPut    string = "put"
+	opDelete string = "delete"
+)
+
+// logRecord is a line of the append-only log of the FileStore
+type logRecord struct {
+	Op     string          `json:"op"`
+	Bucket string          `json:"bucket"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value,omitempty"`
+}
+
+// FileStore is an append-only JSON log on the local disk. Every change is
+// appended to the log and synced before returning. The log is replayed into
+// memory when it's opened, and then compacted to the latest records.
+type FileStore struct {
+	mem  *MemoryStore
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, errors.New("file store path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{
+		mem:  NewMemoryStore(),
+		path: path,
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.file = f
+	logger.StoreLog.Infof("File store is opened: %s", path)
+	return s, nil
+}
+
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			logger.StoreLog.Errorf("Close file error: %+v", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var rec logRecord
+		if err = json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// The last record may be partially written if NEF crashed
+			logger.StoreLog.Warnf("Skip the broken record at line %d: %+v", line, err)
+			continue
+		}
+		switch rec.Op {
+		case opPut:
+			s.mem.put(rec.Bucket, rec.Key, rec.Value)
+		case opDelete:
+			s.mem.delete(rec.Bucket, rec.Key)
+		default:
+			logger.StoreLog.Warnf("Skip the unknown op[%s] at line %d", rec.Op, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the log with only the latest record of each key.
+// The log is replaced only if the whole snapshot is written.
+func (s *FileStore) compact() error {
+	tmpPath := s.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeSnapshot(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if rmErr := os.Remove(tmpPath); rmErr != nil {
+			logger.StoreLog.Errorf("Remove %s error: %+v", tmpPath, rmErr)
+		}
+		return fmt.Errorf("compact file store: %w", err)
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+func (s *FileStore) writeSnapshot(f *os.File) error {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for bucket, records := range s.mem.buckets {
+		for key, value := range records {
+			if err := enc.Encode(&logRecord{Op: opPut, Bucket: bucket, Key: key, Value: value}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (s *FileStore) append(rec *logRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = s.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileStore) Put(bucket, key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errors.New("file store is closed")
+	}
+	if err = s.append(&logRecord{Op: opPut, Bucket: bucket, Key: key, Value: b}); err != nil {
+		return err
+	}
+	s.mem.mu.Lock()
+	s.mem.put(bucket, key, b)
+	s.mem.mu.Unlock()
+	return nil
+}
+
+func (s *FileStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errors.New("file store is closed")
+	}
+	if err := s.append(&logRecord{Op: opDelete, Bucket: bucket, Key: key}); err != nil {
+		return err
+	}
+	return s.mem.Delete(bucket, key)
+}
+
+func (s *FileStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.mem.ForEach(bucket, fn)
+}
+
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestFileStoreRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nef_state.log")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put("af", "af1", &testRecord{Name: "af1", Count: 1}))
+	require.NoError(t, s.Put("af", "af2", &testRecord{Name: "af2", Count: 1}))
+	require.NoError(t, s.Put("af", "af1", &testRecord{Name: "af1", Count: 2}))
+	require.NoError(t, s.Delete("af", "af2"))
+	require.NoError(t, s.Put("nef", "numCorreID", 3))
+	require.NoError(t, s.Close())
+
+	// A record partially written before a crash is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put","bucket":"af","key":"af3","val`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = NewFileStore(path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	records := make(map[string]string)
+	require.NoError(t, s.ForEach("af", func(key string, value []byte) error {
+		records[key] = string(value)
+		return nil
+	}))
+	require.Equal(t, map[string]string{"af1": `{"name":"af1","count":2}`}, records)
+
+	var numCorreID uint64
+	require.NoError(t, s.ForEach("nef", func(key string, value []byte) error {
+		require.Equal(t, "numCorreID", key)
+		require.Equal(t, "3", string(value))
+		numCorreID = 3
+		return nil
+	}))
+	require.Equal(t, uint64(3), numCorreID)
+}
+
+func TestFileStoreCompactFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nef_state.log")
+
+	s, err := NewFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put("af", "af1", &testRecord{Name: "af1", Count: 1}))
+	require.NoError(t, s.Put("nef", "numCorreID", 3))
+	require.NoError(t, s.Put("nefEe", "1", &testRecord{Name: "ee1", Count: 1}))
+	require.NoError(t, s.Close())
+	log, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A record which can't be encoded fails the compaction wherever it's met,
+	// and the log is kept as it is
+	s = &FileStore{
+		mem:  NewMemoryStore(),
+		path: path,
+	}
+	require.NoError(t, s.replay())
+	s.mem.put("af", "af2", []byte(`{"name":`))
+	require.Error(t, s.compact())
+
+	compacted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, log, compacted)
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+type MemoryStore struct {
+	buckets map[string]map[string][]byte
+	mu      sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) Put(bucket, key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(bucket, key, b)
+	return nil
+}
+
+func (s *MemoryStore) put(bucket, key string, value []byte) {
+	if _, ok := s.buckets[bucket]; !ok {
+		s.buckets[bucket] = make(map[string][]byte)
+	}
+	s.buckets[bucket][key] = value
+}
+
+func (s *MemoryStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delete(bucket, key)
+	return nil
+}
+
+func (s *MemoryStore) delete(bucket, key string) {
+	delete(s.buckets[bucket], key)
+	if len(s.buckets[bucket]) == 0 {
+		delete(s.buckets, bucket)
+	}
+}
+
+func (s *MemoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	values := make(map[string][]byte, len(s.buckets[bucket]))
+	for key, value := range s.buckets[bucket] {
+		keys = append(keys, key)
+		values[key] = value
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
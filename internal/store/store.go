@@ -0,0 +1,31 @@
+package store
+
+import (
+	"fmt"
+)
+
+const (
+	TypeMemory string = "memory"
+	TypeFile   string = "file"
+)
+
+// Store keeps the NEF state across restarts. The records are JSON encoded
+// and grouped by bucket, each record is identified by its key in the bucket.
+type Store interface {
+	Put(bucket, key string, value interface{}) error
+	Delete(bucket, key string) error
+	// ForEach calls fn with every record of the bucket
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	Close() error
+}
+
+func NewStore(storeType, path string) (Store, error) {
+	switch storeType {
+	case TypeMemory:
+		return NewMemoryStore(), nil
+	case TypeFile:
+		return NewFileStore(path)
+	default:
+		return nil, fmt.Errorf("unsupported store type: %s", storeType)
+	}
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Features of Nnef_PFDManagement, numbered from 1 as the bits of the supportedFeatures
+const (
+	// The consumer reports the PFDs failed to be applied in the response of the notification
+	PfdMngFeaturePartialFailure uint = 1
+)
+
+// NegotiateSuppFeat returns the features both requested by the consumer and supported by the NEF.
+// Both are the hexadecimal bitmasks whose last character stands for the features 1 to 4.
+// An empty string is returned if no feature is negotiated, so that the supportedFeatures
+// is omitted from the response.
+func NegotiateSuppFeat(requested, supported string) (string, error) {
+	if requested == "" {
+		return "", nil
+	}
+	reqBits, err := parseSuppFeat(requested)
+	if err != nil {
+		return "", err
+	}
+	suppBits, err := parseSuppFeat(supported)
+	if err != nil {
+		return "", err
+	}
+
+	// Align both bitmasks to the least significant character
+	if len(reqBits) > len(suppBits) {
+		reqBits = reqBits[len(reqBits)-len(suppBits):]
+	} else {
+		suppBits = suppBits[len(suppBits)-len(reqBits):]
+	}
+
+	var sb strings.Builder
+	for i := range reqBits {
+		sb.WriteString(strconv.FormatUint(uint64(reqBits[i]&suppBits[i]), 16))
+	}
+	return strings.TrimLeft(sb.String(), "0"), nil
+}
+
+// IsSuppFeatEnabled reports whether the feature is set in the supportedFeatures
+func IsSuppFeatEnabled(suppFeat string, feature uint) bool {
+	if feature == 0 {
+		return false
+	}
+	bits, err := parseSuppFeat(suppFeat)
+	if err != nil {
+		return false
+	}
+	idx := len(bits) - 1 - int((feature-1)/4)
+	if idx < 0 {
+		return false
+	}
+	return bits[idx]&(1<<((feature-1)%4)) != 0
+}
+
+// parseSuppFeat converts the hexadecimal characters of the supportedFeatures into nibbles
+func parseSuppFeat(suppFeat string) ([]uint8, error) {
+	bits := make([]uint8, len(suppFeat))
+	for i, c := range suppFeat {
+		n, err := strconv.ParseUint(string(c), 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supportedFeatures[%s]", suppFeat)
+		}
+		bits[i] = uint8(n)
+	}
+	return bits, nil
+}
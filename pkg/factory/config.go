@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/davecgh/go-spew/spew"
@@ -24,6 +25,18 @@ const (
 	ServiceNefPfd      string = string(models.ServiceName_NNEF_PFDMANAGEMENT)
 	ServiceNefOam      string = "nnef-oam"
 	ServiceNefCallback string = "nnef-callback"
+	ServiceNefSmCtx    string = "nnef-smcontext"
+	ServiceNefEe       string = "nnef-eventexposure"
+	ServiceAsSessQos   string = "3gpp-as-session-with-qos"
+	ServiceMonEvt      string = "3gpp-monitoring-event"
+	ServiceDevTrig     string = "3gpp-device-triggering"
+	ServiceChgParty    string = "3gpp-chargeable-party"
+	ServiceBdt         string = "3gpp-bdt"
+	ServicePp          string = "3gpp-pp"
+	Service5GLanPp     string = "3gpp-5glan-pp"
+	ServiceNidd        string = "3gpp-nidd"
+	ServiceAnaExpo     string = "3gpp-analyticsexposure"
+	ServiceSvcParam    string = "3gpp-service-parameter"
 )
 
 const (
@@ -36,11 +49,28 @@ const (
 	NefSbiDefaultPort        = 8000
 	NefSbiDefaultScheme      = "https"
 	NefDefaultNrfUri         = "https://127.0.0.10:8000"
+	NefDefaultAfAckTimeout   = 5 * time.Second
+	NefDefaultSupiCacheTtl   = 5 * time.Minute
+	NefDefaultStoreType      = "memory"
+	NefDefaultStorePath      = "./nefdata/nef_state.log"
+	NefDefaultSmscType       = "fake"
 	TraffInfluResUriPrefix   = "/" + ServiceTraffInflu + "/v1"
 	PfdMngResUriPrefix       = "/" + ServicePfdMng + "/v1"
 	NefPfdMngResUriPrefix    = "/" + ServiceNefPfd + "/v1"
 	NefOamResUriPrefix       = "/" + ServiceNefOam + "/v1"
 	NefCallbackResUriPrefix  = "/" + ServiceNefCallback + "/v1"
+	AsSessQosResUriPrefix    = "/" + ServiceAsSessQos + "/v1"
+	MonEvtResUriPrefix       = "/" + ServiceMonEvt + "/v1"
+	DevTrigResUriPrefix      = "/" + ServiceDevTrig + "/v1"
+	ChgPartyResUriPrefix     = "/" + ServiceChgParty + "/v1"
+	BdtResUriPrefix          = "/" + ServiceBdt + "/v1"
+	PpResUriPrefix           = "/" + ServicePp + "/v1"
+	FiveGLanPpResUriPrefix   = "/" + Service5GLanPp + "/v1"
+	NiddResUriPrefix         = "/" + ServiceNidd + "/v1"
+	NefSmCtxResUriPrefix     = "/" + ServiceNefSmCtx + "/v1"
+	NefEeResUriPrefix        = "/" + ServiceNefEe + "/v1"
+	AnaExpoResUriPrefix      = "/" + ServiceAnaExpo + "/v1"
+	SvcParamResUriPrefix     = "/" + ServiceSvcParam + "/v1"
 )
 
 type Config struct {
@@ -78,6 +108,34 @@ type Configuration struct {
 	NrfUri      string    `yaml:"nrfUri,omitempty" valid:"required"`
 	NrfCertPem  string    `yaml:"nrfCertPem,omitempty" valid:"optional"`
 	ServiceList []Service `yaml:"serviceList,omitempty" valid:"required"`
+	// Time to wait for the AF acknowledgement of an UP path change notification
+	AfAckTimeout time.Duration `yaml:"afAckTimeout,omitempty" valid:"optional"`
+	// Time to keep the SUPI translated from a GPSI by the UDM
+	SupiCacheTtl time.Duration `yaml:"supiCacheTtl,omitempty" valid:"optional"`
+	Persistence  *Persistence  `yaml:"persistence,omitempty" valid:"optional"`
+	// Pre-defined QoS references that AFs may request in AsSessionWithQoS subscriptions
+	QosReferences []QosReference `yaml:"qosReferences,omitempty" valid:"optional"`
+	Smsc          *Smsc          `yaml:"smsc,omitempty" valid:"optional"`
+}
+
+// Persistence is where the AF contexts, subscriptions and PFD transactions are kept across restarts
+type Persistence struct {
+	Type string `yaml:"type,omitempty" valid:"in(memory|file),optional"`
+	Path string `yaml:"path,omitempty" valid:"type(string),optional"`
+}
+
+// Smsc is the SMS-SC that delivers the device triggers
+type Smsc struct {
+	Type string `yaml:"type,omitempty" valid:"in(fake),optional"`
+}
+
+// QosReference maps a pre-defined QoS reference to the bitrates requested to the PCF
+type QosReference struct {
+	QosReference string `yaml:"qosReference" valid:"type(string),minstringlength(1),required"`
+	MarBwUl      string `yaml:"marBwUl,omitempty" valid:"type(string),optional"` // e.g. "10 Mbps"
+	MarBwDl      string `yaml:"marBwDl,omitempty" valid:"type(string),optional"`
+	MirBwUl      string `yaml:"mirBwUl,omitempty" valid:"type(string),optional"`
+	MirBwDl      string `yaml:"mirBwDl,omitempty" valid:"type(string),optional"`
 }
 
 type Logger struct {
@@ -96,9 +154,17 @@ func (c *Configuration) validate() (bool, error) {
 		switch s.ServiceName {
 		case ServiceNefPfd:
 		case ServiceNefOam:
+		case ServiceNefSmCtx:
+		case ServiceNefEe:
 		default:
 			err := errors.New("invalid serviceList[" + strconv.Itoa(i) + "]: " +
-				s.ServiceName + ", should be " + ServiceNefPfd + " or " + ServiceNefOam)
+				s.ServiceName + ", should be " + ServiceNefPfd + ", " + ServiceNefOam + ", " +
+				ServiceNefSmCtx + " or " + ServiceNefEe)
+			return false, appendInvalid(err)
+		}
+		if s.SuppFeat != "" && !govalidator.IsHexadecimal(s.SuppFeat) {
+			err := errors.New("invalid serviceList[" + strconv.Itoa(i) + "].suppFeat: " +
+				s.SuppFeat + ", should be hexadecimal")
 			return false, appendInvalid(err)
 		}
 	}
@@ -333,6 +399,68 @@ func (c *Config) NrfCertPem() string {
 	return "" // havn't setup in config
 }
 
+func (c *Config) AfAckTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.Configuration.AfAckTimeout > 0 {
+		return c.Configuration.AfAckTimeout
+	}
+	return NefDefaultAfAckTimeout
+}
+
+func (c *Config) SupiCacheTtl() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.Configuration.SupiCacheTtl > 0 {
+		return c.Configuration.SupiCacheTtl
+	}
+	return NefDefaultSupiCacheTtl
+}
+
+func (c *Config) StoreType() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.Configuration.Persistence != nil && c.Configuration.Persistence.Type != "" {
+		return c.Configuration.Persistence.Type
+	}
+	return NefDefaultStoreType
+}
+
+func (c *Config) StorePath() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.Configuration.Persistence != nil && c.Configuration.Persistence.Path != "" {
+		return c.Configuration.Persistence.Path
+	}
+	return NefDefaultStorePath
+}
+
+func (c *Config) SmscType() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.Configuration.Smsc != nil && c.Configuration.Smsc.Type != "" {
+		return c.Configuration.Smsc.Type
+	}
+	return NefDefaultSmscType
+}
+
+func (c *Config) QosReference(qosRef string) *QosReference {
+	c.RLock()
+	defer c.RUnlock()
+
+	for i := range c.Configuration.QosReferences {
+		if c.Configuration.QosReferences[i].QosReference == qosRef {
+			return &c.Configuration.QosReferences[i]
+		}
+	}
+	return nil
+}
+
 func (c *Config) ServiceList() []Service {
 	c.RLock()
 	defer c.RUnlock()
@@ -343,6 +471,28 @@ func (c *Config) ServiceList() []Service {
 	return nil
 }
 
+// SetServiceList replaces the services provided by the NEF, which are pushed to the NRF
+// at the next heartbeat
+func (c *Config) SetServiceList(serviceList []Service) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.Configuration.ServiceList = serviceList
+}
+
+// ServiceSuppFeat returns the features of the service supported by the NEF
+func (c *Config) ServiceSuppFeat(serviceName string) string {
+	c.RLock()
+	defer c.RUnlock()
+
+	for _, s := range c.Configuration.ServiceList {
+		if s.ServiceName == serviceName {
+			return s.SuppFeat
+		}
+	}
+	return ""
+}
+
 func (c *Config) GetCertPemPath() string {
 	c.RLock()
 	defer c.RUnlock()
@@ -406,6 +556,30 @@ func (c *Config) ServiceUri(name string) string {
 		return c.SbiUri() + NefOamResUriPrefix
 	case ServiceNefCallback:
 		return c.SbiUri() + NefCallbackResUriPrefix
+	case ServiceAsSessQos:
+		return c.SbiUri() + AsSessQosResUriPrefix
+	case ServiceMonEvt:
+		return c.SbiUri() + MonEvtResUriPrefix
+	case ServiceDevTrig:
+		return c.SbiUri() + DevTrigResUriPrefix
+	case ServiceChgParty:
+		return c.SbiUri() + ChgPartyResUriPrefix
+	case ServiceBdt:
+		return c.SbiUri() + BdtResUriPrefix
+	case ServicePp:
+		return c.SbiUri() + PpResUriPrefix
+	case Service5GLanPp:
+		return c.SbiUri() + FiveGLanPpResUriPrefix
+	case ServiceNidd:
+		return c.SbiUri() + NiddResUriPrefix
+	case ServiceNefSmCtx:
+		return c.SbiUri() + NefSmCtxResUriPrefix
+	case ServiceNefEe:
+		return c.SbiUri() + NefEeResUriPrefix
+	case ServiceAnaExpo:
+		return c.SbiUri() + AnaExpoResUriPrefix
+	case ServiceSvcParam:
+		return c.SbiUri() + SvcParamResUriPrefix
 	default:
 		return ""
 	}
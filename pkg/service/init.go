@@ -13,6 +13,7 @@ import (
 	"github.com/free5gc/nef/internal/sbi/consumer"
 	"github.com/free5gc/nef/internal/sbi/notifier"
 	"github.com/free5gc/nef/internal/sbi/processor"
+	"github.com/free5gc/nef/internal/smsc"
 	"github.com/free5gc/nef/pkg/app"
 	"github.com/free5gc/nef/pkg/factory"
 	"github.com/sirupsen/logrus"
@@ -32,6 +33,7 @@ type NefApp struct {
 	nefCtx    *nef_context.NefContext
 	consumer  *consumer.Consumer
 	notifier  *notifier.Notifier
+	smsc      smsc.Smsc
 	proc      *processor.Processor
 	sbiServer *sbi.Server
 }
@@ -57,7 +59,10 @@ func NewApp(
 	if nef.consumer, err = consumer.NewConsumer(nef); err != nil {
 		return nil, err
 	}
-	if nef.notifier, err = notifier.NewNotifier(); err != nil {
+	if nef.notifier, err = notifier.NewNotifier(nef.nefCtx.Store()); err != nil {
+		return nil, err
+	}
+	if nef.smsc, err = smsc.NewSmsc(cfg.SmscType()); err != nil {
 		return nil, err
 	}
 	if nef.proc, err = processor.NewProcessor(nef); err != nil {
@@ -93,6 +98,10 @@ func (a *NefApp) Notifier() *notifier.Notifier {
 	return a.notifier
 }
 
+func (a *NefApp) Smsc() smsc.Smsc {
+	return a.smsc
+}
+
 func (a *NefApp) Processor() *processor.Processor {
 	return a.proc
 }
@@ -157,10 +166,49 @@ func (a *NefApp) Start() error {
 		return err
 	}
 
+	a.wg.Add(1)
+	go a.runNrfHeartbeat()
+
+	// The cached PCF, UDR and BSF are discovered again if they are changed
+	a.proc.SubscribeNfStatus()
+	a.wg.Add(1)
+	go a.keepNfStatusSubscriptions()
+
+	// The restored subscriptions may drift from PCF and UDR during the downtime
+	a.proc.ReconcileTrafficInfluence()
+
 	a.WaitRoutineStopped()
 	return nil
 }
 
+// runNrfHeartbeat keeps the NEF registered in the NRF until the NEF is terminated
+func (a *NefApp) runNrfHeartbeat() {
+	defer func() {
+		if p := recover(); p != nil {
+			// Print stack for panic to log. Fatalf() will let program exit.
+			logger.InitLog.Fatalf("panic: %v\n%s", p, string(debug.Stack()))
+		}
+
+		a.wg.Done()
+	}()
+
+	a.consumer.HeartbeatNFInstance(a.ctx)
+}
+
+// keepNfStatusSubscriptions renews the NF status subscriptions until the NEF is terminated
+func (a *NefApp) keepNfStatusSubscriptions() {
+	defer func() {
+		if p := recover(); p != nil {
+			// Print stack for panic to log. Fatalf() will let program exit.
+			logger.InitLog.Fatalf("panic: %v\n%s", p, string(debug.Stack()))
+		}
+
+		a.wg.Done()
+	}()
+
+	a.proc.KeepNfStatusSubscriptions(a.ctx)
+}
+
 func (a *NefApp) listenShutdownEvent() {
 	defer func() {
 		if p := recover(); p != nil {
@@ -182,12 +230,19 @@ func (a *NefApp) terminateProcedure() {
 		a.sbiServer.Terminate()
 	}
 
+	a.proc.UnsubscribeNfStatus()
+
+	// The PFD subscriptions expired by the delivery are removed from the store, which is closed below
+	a.notifier.Stop()
+
 	// deregister with NRF
 	if err := a.consumer.DeregisterNFInstance(); err != nil {
 		logger.MainLog.Error(err)
 	} else {
 		logger.MainLog.Infof("Deregister from NRF successfully")
 	}
+
+	a.nefCtx.CloseStore()
 }
 
 func (a *NefApp) WaitRoutineStopped() {